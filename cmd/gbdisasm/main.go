@@ -0,0 +1,51 @@
+// Command gbdisasm exports a ROM as RGBDS source files, one per bank (see package rgbds).
+//
+//	gbdisasm [-cdl game.cdl] [-sym game.sym] [-o dir] game.gb
+//	cd dir && rgbasm -o game.o game.asm && rgblink -o game.gb game.o
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/jmontupet/gbcore/pkg/rgbds"
+	"github.com/jmontupet/gbcore/pkg/romloader"
+	"github.com/jmontupet/gbcore/pkg/symbols"
+)
+
+func main() {
+	cdlPath := flag.String("cdl", "", "code/data log of the ROM (code found from the entry points if not set)")
+	symPath := flag.String("sym", "", "symbol file (RGBDS / no$gmb) naming the labels")
+	dir := flag.String("o", "src", "output directory")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage : %s [options] rom\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	rom, err := romloader.Load(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	var options rgbds.Options
+	if *cdlPath != "" {
+		if options.CDL, err = ioutil.ReadFile(*cdlPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *symPath != "" {
+		if options.Symbols, err = symbols.Load(*symPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := rgbds.Export(rom, *dir, options); err != nil {
+		log.Fatal(err)
+	}
+}
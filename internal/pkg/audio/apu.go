@@ -1,8 +1,11 @@
 package audio
 
 import (
+	"math"
+
 	"github.com/jmontupet/gbcore/internal/pkg/constants"
 	"github.com/jmontupet/gbcore/internal/pkg/ioports"
+	"github.com/jmontupet/gbcore/internal/pkg/scheduler"
 	"github.com/jmontupet/gbcore/pkg/coreio"
 )
 
@@ -23,111 +26,137 @@ type APU struct {
 	nr50 *ioports.Ptr // FF24 - NR50 - Channel control / ON-OFF / Volume
 	nr51 *ioports.Ptr // FF25 - NR51 - Selection of Sound output terminal
 	nr52 *ioports.Ptr // FF26 - NR52 - Sound on/off
+
+	// Frame sequencer (512 Hz) : clocks the length counters on its even steps (256 Hz)
+	sequencerStep   uint8
+	sequencerClocks int // Clocks since the last step
+
+	// Resampler : the channels run at the CPU clock, the samples are output at AudioFrequency
+	resamplerPosition float64
+	buffer            []uint8
+	bufferPosition    int
 }
 
 const resampleFactor = 70224 * constants.ScreenRefreshRate / constants.AudioFrequency
 const audioSamplePerFrame = constants.AudioBufferSamples
 
-var currentResamplerPosition = 0.0
-var buffer = make([]uint8, audioSamplePerFrame*2)
-var bufferPosition = 0
+// sequencerPeriod is the clocks between two steps of the frame sequencer (512 Hz)
+const sequencerPeriod = constants.CPUClockSpeed / 512
 
-func (apu *APU) Tick(cycles uint8) {
+// Advance runs the APU for n clocks (4 per machine cycle, 2 in double speed).
+// Nothing runs while the sound is off (NR52) : the frame sequencer restarts at step 0.
+func (apu *APU) Advance(clocks int) {
 	// Sound ON/OFF
-	if apu.nr52.Get()>>7 == 0 {
+	if !apu.nr52.GetBit7() {
+		apu.sequencerStep = 0
+		apu.sequencerClocks = 0
 		return
 	}
 
 	// Setup Channel 1
 	apu.channel1.SelectPattern(apu.nr11.Get() >> 6)
 	apu.channel1.SetFrequency(uint16(apu.nr13.Get()) | uint16(apu.nr14.Get()&0x07)<<8)
-	apu.channel1.EnableSoundLength(apu.nr14.Get()&0x20 != 0)
-	apu.channel1.SetSoundLength(apu.nr11.Get() & 0x3F)
+	apu.channel1.EnableSoundLength(apu.nr14.GetBit6())
 	if apu.nr14.GetBit7() {
 		apu.nr14.SetBit7(false)
-		apu.channel1.Initial()
+		apu.channel1.Initial(apu.nr11.Get() & 0x3F)
 	}
 
 	// Setup Channel 2
 	apu.channel2.SelectPattern(apu.nr21.Get() >> 6)
 	apu.channel2.SetFrequency(uint16(apu.nr23.Get()) | uint16(apu.nr24.Get()&0x07)<<8)
-	apu.channel2.EnableSoundLength(apu.nr24.Get()&0x20 != 0)
-	apu.channel2.SetSoundLength(apu.nr21.Get() & 0x3F)
+	apu.channel2.EnableSoundLength(apu.nr24.GetBit6())
 	if apu.nr24.GetBit7() {
 		apu.nr24.SetBit7(false)
-		apu.channel2.Initial()
+		apu.channel2.Initial(apu.nr21.Get() & 0x3F)
 	}
 
-	for i := uint8(0); i < cycles; i++ {
-		chan1Sample := apu.channel1.Tick()
-		chan2Sample := apu.channel2.Tick()
-		// mixed := uint8((uint32(chan1Sample) + uint32(chan2Sample)) >> 2)
-
-		currentResamplerPosition++
-		if currentResamplerPosition >= resampleFactor {
-			vS01 := uint32(apu.nr50.Get() & 0x07)
-			vS02 := uint32(apu.nr50.Get() >> 4 & 0x07)
-			vS01 = vS01 / 7
-			vS02 = vS02 / 7
-
-			out01 := make([]uint8, 4)
-			out02 := make([]uint8, 4)
-			// NR51 :
-			// Bit 7 - Output sound 4 to SO2 terminal
-			// Bit 6 - Output sound 3 to SO2 terminal
-			// Bit 5 - Output sound 2 to SO2 terminal
-			// Bit 4 - Output sound 1 to SO2 terminal
-			// Bit 3 - Output sound 4 to SO1 terminal
-			// Bit 2 - Output sound 3 to SO1 terminal
-			// Bit 1 - Output sound 2 to SO1 terminal
-			// Bit 0 - Output sound 1 to SO1 terminal
-			if apu.nr51.GetBit0() {
-				out01 = append(out01, chan1Sample)
-			}
-			if apu.nr51.GetBit1() {
-				out01 = append(out01, chan2Sample)
-			}
-			if apu.nr51.GetBit4() {
-				out02 = append(out02, chan1Sample)
-			}
-			if apu.nr51.GetBit5() {
-				out02 = append(out02, chan2Sample)
-			}
-
-			if len(out01) > 0 {
-				total := uint32(0)
-				for _, v := range out01 {
-					total += uint32(v)
-				}
-				buffer = append(buffer, uint8((total>>2)*vS01))
-			} else {
-				buffer = append(buffer, 0)
-			}
-			if len(out02) > 0 {
-				total := uint32(0)
-				for _, v := range out02 {
-					total += uint32(v)
-				}
-				buffer = append(buffer, uint8((total>>2)*vS02))
-			} else {
-				buffer = append(buffer, 0)
-			}
-
-			// buffer = append(buffer, chan1Sample, chan2Sample)
-			bufferPosition++
-			if bufferPosition >= audioSamplePerFrame {
-				bufferPosition = 0
-				// SWAP
-				buffer = apu.audioPlayer.SwapAudioBuffer(buffer)
-			}
-			currentResamplerPosition = 0.0
+	// The channels run up to the next step of the frame sequencer or the next sample
+	for clocks > 0 {
+		n := clocks
+		if c := sequencerPeriod - apu.sequencerClocks; c < n {
+			n = c
+		}
+		if c := int(math.Ceil(resampleFactor - apu.resamplerPosition)); c < n {
+			n = c
+		}
+		clocks -= n
+		apu.channel1.Advance(n)
+		apu.channel2.Advance(n)
+
+		apu.sequencerClocks += n
+		if apu.sequencerClocks == sequencerPeriod {
+			apu.sequencerClocks = 0
+			apu.stepSequencer()
 		}
+		apu.resamplerPosition += float64(n)
+		if apu.resamplerPosition >= resampleFactor {
+			apu.resamplerPosition -= resampleFactor
+			apu.output(apu.channel1.Sample(), apu.channel2.Sample())
+		}
+	}
+}
+
+// output mixes the channels samples to the audio buffer, swapped when full
+func (apu *APU) output(chan1Sample uint8, chan2Sample uint8) {
+	vS01 := uint32(apu.nr50.Get() & 0x07)
+	vS02 := uint32(apu.nr50.Get() >> 4 & 0x07)
+	vS01 = vS01 / 7
+	vS02 = vS02 / 7
+
+	// NR51 :
+	// Bit 7 - Output sound 4 to SO2 terminal
+	// Bit 6 - Output sound 3 to SO2 terminal
+	// Bit 5 - Output sound 2 to SO2 terminal
+	// Bit 4 - Output sound 1 to SO2 terminal
+	// Bit 3 - Output sound 4 to SO1 terminal
+	// Bit 2 - Output sound 3 to SO1 terminal
+	// Bit 1 - Output sound 2 to SO1 terminal
+	// Bit 0 - Output sound 1 to SO1 terminal
+	var out01, out02 uint32
+	if apu.nr51.GetBit0() {
+		out01 += uint32(chan1Sample)
+	}
+	if apu.nr51.GetBit1() {
+		out01 += uint32(chan2Sample)
+	}
+	if apu.nr51.GetBit4() {
+		out02 += uint32(chan1Sample)
+	}
+	if apu.nr51.GetBit5() {
+		out02 += uint32(chan2Sample)
+	}
+	apu.buffer = append(apu.buffer, uint8((out01>>2)*vS01), uint8((out02>>2)*vS02))
+
+	apu.bufferPosition++
+	if apu.bufferPosition >= audioSamplePerFrame {
+		apu.bufferPosition = 0
+		// SWAP : the samples are appended to the returned buffer
+		apu.buffer = apu.audioPlayer.SwapAudioBuffer(apu.buffer)[:0]
+	}
+}
+
+// NextEvent returns the clocks before the next step of the frame sequencer, Never while the sound is off
+func (apu *APU) NextEvent() uint64 {
+	if !apu.nr52.GetBit7() {
+		return scheduler.Never
+	}
+	return uint64(sequencerPeriod - apu.sequencerClocks)
+}
+
+// stepSequencer runs a step of the frame sequencer : the length counters are clocked on the even steps
+func (apu *APU) stepSequencer() {
+	if apu.sequencerStep&1 == 0 {
+		apu.channel1.ClockLength()
+		apu.channel2.ClockLength()
 	}
+	apu.sequencerStep = (apu.sequencerStep + 1) & 7
 }
 
 func NewAPU(io *ioports.IOPorts, audioPlayer coreio.AudioPlayer) *APU {
 	return &APU{
 		audioPlayer: audioPlayer,
+		buffer:      make([]uint8, 0, audioSamplePerFrame*2),
 
 		channel1: NewSquareChannel(),
 		nr11:     io.NewPtr(0xFF11),
@@ -0,0 +1,66 @@
+package audio
+
+import (
+	"testing"
+
+	"github.com/jmontupet/gbcore/internal/pkg/ioports"
+	"github.com/jmontupet/gbcore/internal/pkg/scheduler"
+)
+
+// recordingPlayer records the size of the swapped audio buffers
+type recordingPlayer struct {
+	sizes []int
+}
+
+func (p *recordingPlayer) SwapAudioBuffer(data []uint8) []uint8 {
+	p.sizes = append(p.sizes, len(data))
+	return data
+}
+
+func TestFrameSequencer(t *testing.T) {
+	io := ioports.NewGBIOPorts(true)
+	apu := NewAPU(io, &recordingPlayer{})
+	if e := apu.NextEvent(); e != scheduler.Never {
+		t.Fatalf("sound off : next event %d", e)
+	}
+	apu.Advance(100)
+
+	io.Write(0xFF26, 0x80) // Sound on
+	if e := apu.NextEvent(); e != sequencerPeriod {
+		t.Fatalf("sound on : expected next event %d, got %d", sequencerPeriod, e)
+	}
+	io.Write(0xFF11, 0x3F) // Length 1
+	io.Write(0xFF14, 0xC0) // Trigger, length enabled
+	apu.Advance(sequencerPeriod - 1)
+	if !apu.channel1.started || apu.NextEvent() != 1 {
+		t.Fatalf("before step 0 : started %v, next event %d", apu.channel1.started, apu.NextEvent())
+	}
+	apu.Advance(1)
+	if apu.channel1.started {
+		t.Errorf("step 0 : the length counter must stop the channel")
+	}
+	if apu.sequencerStep != 1 || apu.NextEvent() != sequencerPeriod {
+		t.Errorf("step 0 : step %d, next event %d", apu.sequencerStep, apu.NextEvent())
+	}
+
+	io.Write(0xFF26, 0x00) // Sound off : the sequencer restarts at step 0
+	apu.Advance(1)
+	if apu.sequencerStep != 0 || apu.NextEvent() != scheduler.Never {
+		t.Errorf("sound off : step %d, next event %d", apu.sequencerStep, apu.NextEvent())
+	}
+}
+
+func TestAudioBuffer(t *testing.T) {
+	io := ioports.NewGBIOPorts(true)
+	player := &recordingPlayer{}
+	apu := NewAPU(io, player)
+	io.Write(0xFF26, 0x80)
+	for len(player.sizes) < 3 {
+		apu.Advance(sequencerPeriod)
+	}
+	for i, size := range player.sizes {
+		if size != audioSamplePerFrame*2 {
+			t.Errorf("buffer %d : expected %d bytes, got %d", i, audioSamplePerFrame*2, size)
+		}
+	}
+}
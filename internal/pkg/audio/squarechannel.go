@@ -10,12 +10,7 @@ type SquareChannel struct {
 	timer   uint16
 	counter uint16 // If counter >= Timer -> patternPosition++ % 8; counter=0
 
-	////// Frame Sequencer //////
-	sequencer        uint16
-	sequencerCounter uint16
-
-	////// Sample Counter //////
-	soundLength        uint16
+	////// Length Counter (clocked by the frame sequencer) //////
 	soundLengthEnable  bool
 	soundLengthCounter uint16
 
@@ -23,9 +18,12 @@ type SquareChannel struct {
 	started bool
 }
 
-func (sc *SquareChannel) Initial() {
+// Initial starts the channel (trigger), the length counter loaded from the NRx1 length.
+// NRx1 is polled : its value at the trigger is used.
+func (sc *SquareChannel) Initial(length uint8) {
 	// TODO : reinit counters
 	sc.started = true
+	sc.soundLengthCounter = 64 - uint16(length)
 }
 
 func (sc *SquareChannel) SetFrequency(freq uint16)    { sc.timer = (2048 - freq) * 4 }
@@ -34,37 +32,36 @@ func (sc *SquareChannel) SelectPattern(pattern uint8) { sc.patternSelected = pat
 func (sc *SquareChannel) EnableSoundLength(enable bool) {
 	sc.soundLengthEnable = enable
 }
-func (sc *SquareChannel) SetSoundLength(soundLength uint8) {
-	sc.soundLength = (64 - uint16(soundLength)) * 1 / 256
-}
 
-func (sc *SquareChannel) Tick() uint8 {
-	if !sc.started {
-		return 0
+// ClockLength decrements the length counter (256 Hz) : the channel stops when it reaches 0
+func (sc *SquareChannel) ClockLength() {
+	if !sc.soundLengthEnable || sc.soundLengthCounter == 0 {
+		return
 	}
-	var sampleValue uint8 = 0
-
-	// SAMPLE VALUE
-	if sc.pattern[sc.patternSelected][sc.patternPosition] {
-		sampleValue = sc.Volume
+	sc.soundLengthCounter--
+	if sc.soundLengthCounter == 0 {
+		sc.started = false
 	}
+}
 
-	// SOUND LENGTH
-	if sc.soundLengthEnable {
-		if sc.soundLengthCounter > sc.soundLength {
-			sampleValue = 0
-		} else {
-			sc.soundLengthCounter++
-		}
+// Sample returns the output of the channel
+func (sc *SquareChannel) Sample() uint8 {
+	if sc.started && sc.pattern[sc.patternSelected][sc.patternPosition] {
+		return sc.Volume
 	}
+	return 0
+}
 
-	// INC
-	sc.counter++
+// Advance runs the frequency timer for n clocks : the pattern moves on each period
+func (sc *SquareChannel) Advance(clocks int) {
+	if !sc.started {
+		return
+	}
+	sc.counter += uint16(clocks) // Up to a sample period (APU.Advance)
 	if sc.counter >= sc.timer {
-		sc.counter = 0
-		sc.patternPosition = (sc.patternPosition + 1) % 8
+		sc.patternPosition = uint8((uint16(sc.patternPosition) + sc.counter/sc.timer) % 8)
+		sc.counter %= sc.timer
 	}
-	return sampleValue
 }
 
 func NewSquareChannel() *SquareChannel {
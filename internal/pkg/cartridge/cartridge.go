@@ -8,29 +8,120 @@ import (
 
 type Cartridge interface {
 	memory.Memory
+	// ROMBank returns the ROM bank currently mapped at addr (0000-7FFF)
+	ROMBank(addr uint16) uint
+	// RAMBank returns the RAM bank currently mapped at A000-BFFF
+	RAMBank() uint
+	// ROM returns the cartridge ROM, all banks. Not a copy.
+	ROM() []uint8
+	// RAM returns the cartridge RAM, all banks (nil without RAM). Not a copy.
+	RAM() []uint8
+	// Mapped returns the memory currently mapped at 0000-3FFF, 4000-7FFF or A000-BFFF (area of addr), not a copy.
+	// RAM smaller than 8KB is mirrored in the area. It returns nil if the area must be accessed
+	// through Read & Write (disabled RAM, RTC registers...). ROM writes always go through Write.
+	Mapped(addr uint16) []uint8
 }
 
 func NewCartridge(data []byte) (Cartridge, error) {
+	if len(data) < 0x150 {
+		return nil, fmt.Errorf("CARTRIDGE HEADER TRUNCATED : 0x%04X BYTES", len(data))
+	}
 	switch cType := data[0x147]; cType {
 	case 0x00: // ROM_Only
-		return newROMOnly(data), nil
+		return newROMOnly(data)
 	case 0x01: // ROM_MBC1
-		return newMBC1(data), nil
+		return newMBC1(data)
 	case 0x03: // ROM_MBC1_RAM_Batt
-		return newMBC1(data), nil
+		return newMBC1(data)
 	case 0x10: // ROM_MBC3_Timer_RAM_Batt
-		return newMBC3(data), nil
+		return newMBC3(data)
 	case 0x13: // ROM_MBC3_RAM_Batt
-		return newMBC3(data), nil
+		return newMBC3(data)
 	case 0x19: // ROM_MBC5
-		return newMBC5(data), nil
+		return newMBC5(data)
 	case 0x1B: // ROM_MBC5_RAM_Batt
-		return newMBC5(data), nil
+		return newMBC5(data)
+	case 0x1C, 0x1D, 0x1E: // ROM_MBC5_Rumble, ROM_MBC5_Rumble_RAM, ROM_MBC5_Rumble_RAM_Batt
+		return newMBC5(data)
 	default:
 		return nil, fmt.Errorf("CARTRIDGE TYPE NOT IMPLEMENTED : 0x%02X", cType)
 	}
 }
 
+const romBankSizeInt uint = 0x4000 // 16KB
+const ramBankSizeInt uint = 0x2000 // 8KB
+
+// newBankedROM returns the ROM data padded to the number of banks declared in the header.
+//
+// The number of banks is always a power of 2, bank numbers are masked with nbBank - 1
+// like the real hardware which ignores unconnected address lines.
+func newBankedROM(data []byte) (rom []uint8, nbBank uint, err error) {
+	romSize := data[0x148]
+	if romSize > 0x08 {
+		return nil, 0, fmt.Errorf("ROM SIZE NOT MANAGED : 0x%02X", romSize)
+	}
+	nbBank = 2 << romSize // 00h - 32KByte (2 banks) ... 08h - 8MByte (512 banks)
+	if size := nbBank * romBankSizeInt; uint(len(data)) < size {
+		// Missing data (bad dump or overdumped header) : open bus
+		rom = make([]uint8, size)
+		for i := copy(rom, data); i < len(rom); i++ {
+			rom[i] = 0xFF
+		}
+		return rom, nbBank, nil
+	}
+	return data, nbBank, nil
+}
+
+// newBankedRAM allocates the cartridge RAM declared in the header.
+func newBankedRAM(data []byte) (ram []uint8, nbBank uint, err error) {
+	switch ramSize := data[0x149]; ramSize {
+	case 0x00: // 00h - None
+		return nil, 0, nil
+	case 0x01: // 01h - 2 KBytes (unofficial, only the first 2KB of the bank are mapped)
+		return make([]uint8, 1024*2), 1, nil
+	case 0x02: // 02h - 8 Kbytes
+		return make([]uint8, 1024*8), 1, nil
+	case 0x03: // 03h - 32 KBytes (4 banks of 8KBytes each)
+		return make([]uint8, 4*1024*8), 4, nil
+	case 0x04: // 04h - 128 KBytes (16 banks of 8KBytes each)
+		return make([]uint8, 16*1024*8), 16, nil
+	case 0x05: // 05h - 64 KBytes (8 banks of 8KBytes each)
+		return make([]uint8, 8*1024*8), 8, nil
+	default:
+		return nil, 0, fmt.Errorf("RAM SIZE NOT MANAGED : 0x%02X", ramSize)
+	}
+}
+
+// romOffset returns the index in rom of addr (0000-3FFF or 4000-7FFF) for the bank.
+// bank must be already masked.
+func romOffset(bank uint, addr uint16) uint {
+	return bank*romBankSizeInt + uint(addr&0x3FFF)
+}
+
+// romArea returns the 16KB of rom in the bank, nil if rom is too small
+func romArea(rom []uint8, bank uint) []uint8 {
+	offset := romOffset(bank, 0)
+	if offset+romBankSizeInt > uint(len(rom)) {
+		return nil
+	}
+	return rom[offset : offset+romBankSizeInt]
+}
+
+// ramArea returns the RAM mapped at A000-BFFF for the bank (all the RAM if smaller than 8KB), nil without RAM
+func ramArea(ram []uint8, bank uint) []uint8 {
+	if uint(len(ram)) <= ramBankSizeInt {
+		return ram
+	}
+	offset := ramOffset(ram, bank, 0xA000)
+	return ram[offset : offset+ramBankSizeInt]
+}
+
+// ramOffset returns the index in ram of addr (A000-BFFF) for the bank.
+// The result wraps on the RAM size (mirroring of small RAM chips).
+func ramOffset(ram []uint8, bank uint, addr uint16) uint {
+	return (bank*ramBankSizeInt + uint(addr-0xA000)) & uint(len(ram)-1)
+}
+
 // const (
 // 	ROM_Only                 CartridgeType = 0x00
 // 	ROM_MBC1                 CartridgeType = 0x01
@@ -0,0 +1,82 @@
+package cartridge
+
+import (
+	"testing"
+)
+
+// newTestROM builds a ROM image with the given header values.
+// The first two bytes of each bank contain the bank number (LO, HI).
+func newTestROM(cType uint8, romSize uint8, ramSize uint8) []byte {
+	nbBank := 2 << romSize
+	data := make([]byte, nbBank*int(romBankSizeInt))
+	for bank := 0; bank < nbBank; bank++ {
+		data[bank*int(romBankSizeInt)] = uint8(bank)
+		data[bank*int(romBankSizeInt)+1] = uint8(bank >> 8)
+	}
+	data[0x147] = cType
+	data[0x148] = romSize
+	data[0x149] = ramSize
+	return data
+}
+
+// readBank returns the bank number mapped at base (0000 or 4000)
+func readBank(c Cartridge, base uint16) uint {
+	return uint(c.Read(base)) | uint(c.Read(base+1))<<8
+}
+
+type bankWrite struct {
+	addr  uint16
+	value uint8
+}
+
+func newTestCartridge(t *testing.T, cType uint8, romSize uint8, ramSize uint8) Cartridge {
+	t.Helper()
+	c, err := NewCartridge(newTestROM(cType, romSize, ramSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestNewCartridgePadsShortROM(t *testing.T) {
+	data := newTestROM(0x19, 0x02, 0x00)[:0x4000*3] // 8 banks declared, 3 dumped
+	c, err := NewCartridge(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Write(0x2000, 0x05)
+	if v := c.Read(0x4000); v != 0xFF {
+		t.Fatalf("missing bank : expected: 0xFF, got: 0x%02X", v)
+	}
+}
+
+func TestROMOnlyPadsShortROM(t *testing.T) {
+	data := newTestROM(0x00, 0x00, 0x00)[:0x4000] // 16KB image
+	c, err := NewCartridge(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := c.Read(0x4000); v != 0xFF {
+		t.Fatalf("4000 : expected: 0xFF, got: 0x%02X", v)
+	}
+	if v := c.Read(0x7FFF); v != 0xFF {
+		t.Fatalf("7FFF : expected: 0xFF, got: 0x%02X", v)
+	}
+}
+
+// testRAMBanks writes a marker in each RAM bank then checks it is read back
+// with the expected wrapping.
+func testRAMBanks(t *testing.T, c Cartridge, selectBank func(bank uint8), banks []uint8, expected []uint8) {
+	t.Helper()
+	c.Write(0x0000, 0x0A) // RAM enable
+	for _, bank := range banks {
+		selectBank(bank)
+		c.Write(0xA000, bank+1)
+	}
+	for i, bank := range banks {
+		selectBank(bank)
+		if v := c.Read(0xA000); v != expected[i] {
+			t.Fatalf("RAM bank 0x%02X : expected: 0x%02X, got: 0x%02X", bank, expected[i], v)
+		}
+	}
+}
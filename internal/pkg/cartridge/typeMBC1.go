@@ -1,20 +1,16 @@
 package cartridge
 
 import (
-	"fmt"
 	"log"
 )
 
-const romBankSizeInt uint = 0x4000 // 16KB
-const ramBankSizeInt uint = 0x2000 // 8KB
-
 type mbc1 struct {
 	data      []uint8
-	romBank   uint
+	romBank   uint // 5 bits register (2000-3FFF)
 	nbROMBank uint
 
 	ram       []uint8
-	ramBank   uint
+	bankHigh  uint // 2 bits register (4000-5FFF) : RAM bank or upper bits of ROM bank
 	nbRAMBank uint
 
 	modeRam bool
@@ -22,17 +18,63 @@ type mbc1 struct {
 	ramEnable bool
 }
 
+// fixedROMBank returns the bank mapped at 0000-3FFF.
+// In RAM banking mode, upper bits are also applied to this area.
+func (c *mbc1) fixedROMBank() uint {
+	if c.modeRam {
+		return (c.bankHigh << 5) & (c.nbROMBank - 1)
+	}
+	return 0
+}
+
+// switchableROMBank returns the bank mapped at 4000-7FFF.
+func (c *mbc1) switchableROMBank() uint {
+	return (c.bankHigh<<5 | c.romBank) & (c.nbROMBank - 1)
+}
+
+// ramBank returns the bank mapped at A000-BFFF. Only available in RAM banking mode.
+func (c *mbc1) ramBank() uint {
+	if c.modeRam && c.nbRAMBank > 0 {
+		return c.bankHigh & (c.nbRAMBank - 1)
+	}
+	return 0
+}
+
+func (c *mbc1) ROMBank(addr uint16) uint {
+	if addr < 0x4000 {
+		return c.fixedROMBank()
+	}
+	return c.switchableROMBank()
+}
+
+func (c *mbc1) RAMBank() uint { return c.ramBank() }
+func (c *mbc1) ROM() []uint8  { return c.data }
+func (c *mbc1) RAM() []uint8  { return c.ram }
+
+func (c *mbc1) Mapped(addr uint16) []uint8 {
+	switch {
+	case addr <= 0x3FFF:
+		return romArea(c.data, c.fixedROMBank())
+	case addr <= 0x7FFF:
+		return romArea(c.data, c.switchableROMBank())
+	case c.ramEnable:
+		return ramArea(c.ram, c.ramBank())
+	default:
+		return nil
+	}
+}
+
 func (c *mbc1) Read(addr uint16) uint8 {
 	switch {
 	case addr >= 0x0000 && addr <= 0x3FFF: // ROM CART FIXED
-		return c.data[addr]
+		return c.data[romOffset(c.fixedROMBank(), addr)]
 	case addr >= 0x4000 && addr <= 0x7FFF: // ROM CART BANK N
-		return c.data[uint(addr)-0x4000+c.romBank*romBankSizeInt]
+		return c.data[romOffset(c.switchableROMBank(), addr)]
 	case addr >= 0xA000 && addr <= 0xBFFF: // CART RAM
-		if !c.ramEnable {
-			return 0x00
+		if !c.ramEnable || len(c.ram) == 0 {
+			return 0xFF // Open bus
 		}
-		return c.ram[uint(addr)-0xA000+c.ramBank*ramBankSizeInt]
+		return c.ram[ramOffset(c.ram, c.ramBank(), addr)]
 	default:
 		log.Fatalf("MEMORY UNREACHABLE : 0x%04X", addr)
 		return 0x00
@@ -53,23 +95,18 @@ func (c *mbc1) Write(addr uint16, value uint8) {
 
 	// 4000-5FFF - RAM Bank Number - or - Upper Bits of ROM Bank Number (Write Only)
 	case addr >= 0x4000 && addr <= 0x5FFF:
-		if c.modeRam {
-			panic(fmt.Sprintf("TODO : 4000-5FFF - RAM Bank Number - or - Upper Bits of ROM Bank Number (Write Only) : %x", addr))
-		}
-		upperBits := uint((value & 0x03) << 5)
-		c.changeROMBank(uint8(c.romBank&0x1F | upperBits))
+		c.bankHigh = uint(value & 0x03)
 
 	// 6000-7FFF - ROM/RAM Mode Select (Write Only)
 	// 00h = ROM Banking Mode (up to 8KByte RAM, 2MByte ROM) (default)
 	// 01h = RAM Banking Mode (up to 32KByte RAM, 512KByte ROM)
 	case addr >= 0x6000 && addr <= 0x7FFF:
-		// fmt.Printf("CHANGE CARTRIDGE MODE TO 0x%02X\n", value)
-		c.modeRam = value&0x0A == 0x0A
+		c.modeRam = value&0x01 == 0x01
 
 	// CART RAM
 	case addr >= 0xA000 && addr <= 0xBFFF:
-		if c.ramEnable {
-			c.ram[addr-0xA000] = value
+		if c.ramEnable && len(c.ram) > 0 {
+			c.ram[ramOffset(c.ram, c.ramBank(), addr)] = value
 		}
 
 	// OFF RANGE
@@ -78,47 +115,32 @@ func (c *mbc1) Write(addr uint16, value uint8) {
 	}
 }
 
+// changeROMBank sets the 5 lower bits of the ROM bank.
+//
+// 0 is translated to 1 before masking with the ROM size : banks 20h, 40h and 60h
+// can't be selected in 4000-7FFF, and a 0 written on a small ROM still selects bank 1.
 func (c *mbc1) changeROMBank(v uint8) {
-	value := uint(v)
-	if value > c.nbROMBank {
-		log.Fatalf(fmt.Sprintf("ROM BANK 0x%02X DOES NOT EXIST. MAX : 0x%02X", value, c.nbROMBank))
+	value := uint(v & 0x1F)
+	if value == 0 {
+		value = 1
 	}
-	if value == 0 || value == 0x20 || value == 0x40 || value == 0x60 {
-		value++
-	}
-	// if value != c.romBank {
-	// 	fmt.Printf("CHANGE CARTRIDGE ROM BANK TO 0x%02X\n", value)
-	// }
 	c.romBank = value
 }
 
-func newMBC1(data []byte) Cartridge {
-	cartridge := &mbc1{
-		data:    data,
-		romBank: 1,
-	}
-	switch ramSize := ReadRAMSize(cartridge); ramSize {
-	case 0x00: // 00h - None
-		cartridge.nbRAMBank = 0
-	case 0x02: // 02h - 8 Kbytes
-		cartridge.nbRAMBank = 4
-		cartridge.ram = make([]uint8, 1024*8)
-	default:
-		panic(fmt.Sprintf("RAM SIZE NOT MANAGED FOR MBC1 : 0x%02X", ramSize))
+func newMBC1(data []byte) (Cartridge, error) {
+	rom, nbROMBank, err := newBankedROM(data)
+	if err != nil {
+		return nil, err
 	}
-
-	switch romSize := ReadROMSize(cartridge); romSize {
-	case 0x00: // 00h - None
-		cartridge.nbROMBank = 0
-	case 0x01: // 01h -		64KByte (4 banks)
-		cartridge.nbROMBank = 4
-	case 0x03: // 03h - 256KByte (16 banks)
-		cartridge.nbROMBank = 16
-	case 0x04: // 04h - 512KByte (32 banks)
-		cartridge.nbROMBank = 32
-	default:
-		panic(fmt.Sprintf("ROM SIZE NOT MANAGED FOR MBC1 : 0x%02X", romSize))
+	ram, nbRAMBank, err := newBankedRAM(data)
+	if err != nil {
+		return nil, err
 	}
-
-	return cartridge
+	return &mbc1{
+		data:      rom,
+		romBank:   1,
+		nbROMBank: nbROMBank,
+		ram:       ram,
+		nbRAMBank: nbRAMBank,
+	}, nil
 }
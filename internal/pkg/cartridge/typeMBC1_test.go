@@ -0,0 +1,66 @@
+package cartridge
+
+import (
+	"testing"
+)
+
+func TestMBC1ROMBanks(t *testing.T) {
+	tests := []struct {
+		name         string
+		romSize      uint8
+		writes       []bankWrite
+		fixedBank    uint
+		switchedBank uint
+	}{
+		{"default", 0x04, nil, 0, 1},
+		{"bank 0 is bank 1", 0x04, []bankWrite{{0x2000, 0x00}}, 0, 1},
+		{"bank 5", 0x04, []bankWrite{{0x2000, 0x05}}, 0, 5},
+		{"5 bits only", 0x04, []bankWrite{{0x2000, 0xE3}}, 0, 3},
+		{"masked by rom size", 0x02, []bankWrite{{0x2000, 0x1D}}, 0, 5},
+		{"masked to bank 0", 0x01, []bankWrite{{0x2000, 0x04}}, 0, 0},
+		{"upper bits", 0x06, []bankWrite{{0x2000, 0x02}, {0x4000, 0x01}}, 0, 0x22},
+		{"bank 20h is bank 21h", 0x06, []bankWrite{{0x2000, 0x00}, {0x4000, 0x01}}, 0, 0x21},
+		{"upper bits masked", 0x05, []bankWrite{{0x2000, 0x03}, {0x4000, 0x03}}, 0, 0x23},
+		{"mode 1 maps upper bits at 0000", 0x06, []bankWrite{{0x4000, 0x02}, {0x6000, 0x01}}, 0x40, 0x41},
+		{"mode 1 on small rom", 0x04, []bankWrite{{0x4000, 0x02}, {0x6000, 0x01}}, 0, 1},
+	}
+	for _, tt := range tests {
+		c := newTestCartridge(t, 0x01, tt.romSize, 0x00)
+		for _, w := range tt.writes {
+			c.Write(w.addr, w.value)
+		}
+		if bank := readBank(c, 0x0000); bank != tt.fixedBank {
+			t.Errorf("%s : 0000-3FFF : expected bank 0x%02X, got 0x%02X", tt.name, tt.fixedBank, bank)
+		}
+		if bank := readBank(c, 0x4000); bank != tt.switchedBank {
+			t.Errorf("%s : 4000-7FFF : expected bank 0x%02X, got 0x%02X", tt.name, tt.switchedBank, bank)
+		}
+	}
+}
+
+func TestMBC1RAMBanks(t *testing.T) {
+	tests := []struct {
+		name     string
+		ramSize  uint8
+		mode     uint8
+		banks    []uint8
+		expected []uint8
+	}{
+		{"32KB mode 1", 0x03, 0x01, []uint8{0, 1, 2, 3}, []uint8{1, 2, 3, 4}},
+		{"32KB mode 0 : bank 0 only", 0x03, 0x00, []uint8{0, 1, 2, 3}, []uint8{4, 4, 4, 4}},
+		{"8KB mode 1 : wraps", 0x02, 0x01, []uint8{0, 1}, []uint8{2, 2}},
+		{"2KB : mirrored", 0x01, 0x01, []uint8{0}, []uint8{1}},
+	}
+	for _, tt := range tests {
+		c := newTestCartridge(t, 0x03, 0x04, tt.ramSize)
+		c.Write(0x6000, tt.mode)
+		testRAMBanks(t, c, func(bank uint8) { c.Write(0x4000, bank) }, tt.banks, tt.expected)
+	}
+
+	c := newTestCartridge(t, 0x03, 0x04, 0x01)
+	c.Write(0x0000, 0x0A)
+	c.Write(0xA000, 0x42)
+	if v := c.Read(0xA800); v != 0x42 {
+		t.Fatalf("2KB RAM mirror : expected: 0x42, got: 0x%02X", v)
+	}
+}
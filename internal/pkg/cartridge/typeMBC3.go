@@ -1,7 +1,6 @@
 package cartridge
 
 import (
-	"fmt"
 	"log"
 )
 
@@ -19,17 +18,41 @@ type mbc3 struct {
 	rtcEnable bool
 }
 
+func (c *mbc3) ROMBank(addr uint16) uint {
+	if addr < 0x4000 {
+		return 0
+	}
+	return c.romBank
+}
+
+func (c *mbc3) RAMBank() uint { return c.ramBank }
+func (c *mbc3) ROM() []uint8  { return c.data }
+func (c *mbc3) RAM() []uint8  { return c.ram }
+
+func (c *mbc3) Mapped(addr uint16) []uint8 {
+	switch {
+	case addr <= 0x3FFF:
+		return romArea(c.data, 0)
+	case addr <= 0x7FFF:
+		return romArea(c.data, c.romBank)
+	case c.ramTimerEnable && !c.rtcEnable:
+		return ramArea(c.ram, c.ramBank)
+	default:
+		return nil
+	}
+}
+
 func (c *mbc3) Read(addr uint16) uint8 {
 	switch {
 	case addr >= 0x0000 && addr <= 0x3FFF: // ROM CART FIXED
 		return c.data[addr]
 	case addr >= 0x4000 && addr <= 0x7FFF: // ROM CART BANK N
-		return c.data[uint(addr)-0x4000+c.romBank*romBankSizeInt]
-	case addr >= 0xA000 && addr <= 0xBFFF: // CART RAM
-		if c.ramTimerEnable {
-			return c.ram[uint(addr)-0xA000+c.ramBank*ramBankSizeInt]
+		return c.data[romOffset(c.romBank, addr)]
+	case addr >= 0xA000 && addr <= 0xBFFF: // CART RAM, or RTC register (not emulated)
+		if c.ramTimerEnable && !c.rtcEnable && len(c.ram) > 0 {
+			return c.ram[ramOffset(c.ram, c.ramBank, addr)]
 		}
-		return 0x00
+		return 0xFF // Open bus
 	default:
 		log.Fatalf("MEMORY UNREACHABLE : 0x%04X", addr)
 		return 0x00
@@ -50,8 +73,11 @@ func (c *mbc3) Write(addr uint16, value uint8) {
 
 	// 4000-5FFF - RAM Bank Number - or - RTC Register Select (Write Only)
 	case addr >= 0x4000 && addr <= 0x5FFF:
-		if value <= 0x03 { // 4 banks max
+		if value <= 0x07 { // RAM banks, masked by the RAM size
 			c.ramBank = uint(value)
+			if c.nbRAMBank > 0 {
+				c.ramBank &= c.nbRAMBank - 1
+			}
 			c.rtcEnable = false
 		} else {
 			c.rtcEnable = true
@@ -59,12 +85,12 @@ func (c *mbc3) Write(addr uint16, value uint8) {
 
 	// 6000-7FFF - Latch Clock Data (Write Only)
 	case addr >= 0x6000 && addr <= 0x7FFF:
-		fmt.Printf("TODO : Latch Clock Data %X : %X\n", addr, value)
+		// TODO : RTC not emulated, nothing to latch
 
 	// CART RAM
 	case addr >= 0xA000 && addr <= 0xBFFF:
-		if c.ramTimerEnable && !c.rtcEnable {
-			c.ram[addr-0xA000] = value
+		if c.ramTimerEnable && !c.rtcEnable && len(c.ram) > 0 {
+			c.ram[ramOffset(c.ram, c.ramBank, addr)] = value
 		}
 	// OFF RANGE
 	default:
@@ -72,47 +98,31 @@ func (c *mbc3) Write(addr uint16, value uint8) {
 	}
 }
 
+// changeROMBank selects the bank mapped at 4000-7FFF.
+//
+// 7 bits are used, 0 is translated to 1 and the result is masked with the ROM size.
 func (c *mbc3) changeROMBank(v uint8) {
 	value := uint(v & 0x7F)
-	if value > c.nbROMBank {
-		panic(fmt.Sprintf("ROM BANK 0x%02X DOES NOT EXIST. MAX : 0x%02X", value, c.nbROMBank))
-	}
-	if value&0x7F != c.romBank {
-		// fmt.Printf("CHANGE CARTRIDGE ROM BANK TO 0x%02X\n", value)
-	}
 	if value == 0 {
-		c.romBank = 1
-		return
+		value = 1
 	}
-	c.romBank = value & 0x7F
+	c.romBank = value & (c.nbROMBank - 1)
 }
 
-func newMBC3(data []byte) Cartridge {
-	cartridge := &mbc3{
-		data:    data,
-		romBank: 1,
+func newMBC3(data []byte) (Cartridge, error) {
+	rom, nbROMBank, err := newBankedROM(data)
+	if err != nil {
+		return nil, err
 	}
-	switch ramSize := ReadRAMSize(cartridge); ramSize {
-	case 0x02: // 02h - 8 Kbytes
-		cartridge.nbRAMBank = 1
-		cartridge.ram = make([]uint8, 1024*8)
-	case 0x03: // 03h -		32 KBytes (4 banks of 8KBytes each)
-		cartridge.nbRAMBank = 4
-		cartridge.ram = make([]uint8, 4*1024*8)
-
-	default:
-		panic(fmt.Sprintf("RAM SIZE NOT MANAGED FOR MBC3 : 0x%02X", ramSize))
+	ram, nbRAMBank, err := newBankedRAM(data)
+	if err != nil {
+		return nil, err
 	}
-
-	switch romSize := ReadROMSize(cartridge); romSize {
-
-	case 0x05: // 05h -		1MByte (64 banks)
-		cartridge.nbROMBank = 64
-	case 0x06: // 06h -   2MByte (128 banks) - only 125 banks used by MBC1
-		cartridge.nbROMBank = 128
-	default:
-		panic(fmt.Sprintf("ROM SIZE NOT MANAGED FOR MBC3 : 0x%02X", romSize))
-	}
-
-	return cartridge
+	return &mbc3{
+		data:      rom,
+		romBank:   1,
+		nbROMBank: nbROMBank,
+		ram:       ram,
+		nbRAMBank: nbRAMBank,
+	}, nil
 }
@@ -0,0 +1,69 @@
+package cartridge
+
+import (
+	"testing"
+)
+
+func TestMBC3ROMBanks(t *testing.T) {
+	tests := []struct {
+		name    string
+		romSize uint8
+		writes  []bankWrite
+		bank    uint
+	}{
+		{"default", 0x06, nil, 1},
+		{"bank 0 is bank 1", 0x06, []bankWrite{{0x2000, 0x00}}, 1},
+		{"bank 20h", 0x06, []bankWrite{{0x2000, 0x20}}, 0x20},
+		{"bank 7Fh", 0x06, []bankWrite{{0x3FFF, 0x7F}}, 0x7F},
+		{"7 bits only", 0x06, []bankWrite{{0x2000, 0x85}}, 0x05},
+		{"masked by rom size", 0x03, []bankWrite{{0x2000, 0x15}}, 0x05},
+		{"masked to bank 0", 0x02, []bankWrite{{0x2000, 0x08}}, 0x00},
+	}
+	for _, tt := range tests {
+		c := newTestCartridge(t, 0x13, tt.romSize, 0x03)
+		for _, w := range tt.writes {
+			c.Write(w.addr, w.value)
+		}
+		if bank := readBank(c, 0x0000); bank != 0 {
+			t.Errorf("%s : 0000-3FFF : expected bank 0x00, got 0x%02X", tt.name, bank)
+		}
+		if bank := readBank(c, 0x4000); bank != tt.bank {
+			t.Errorf("%s : 4000-7FFF : expected bank 0x%02X, got 0x%02X", tt.name, tt.bank, bank)
+		}
+	}
+}
+
+func TestMBC3RAMBanks(t *testing.T) {
+	tests := []struct {
+		name     string
+		ramSize  uint8
+		banks    []uint8
+		expected []uint8
+	}{
+		{"32KB", 0x03, []uint8{0, 1, 2, 3}, []uint8{1, 2, 3, 4}},
+		{"8KB : wraps", 0x02, []uint8{0, 1, 3}, []uint8{4, 4, 4}},
+	}
+	for _, tt := range tests {
+		c := newTestCartridge(t, 0x13, 0x05, tt.ramSize)
+		testRAMBanks(t, c, func(bank uint8) { c.Write(0x4000, bank) }, tt.banks, tt.expected)
+	}
+}
+
+// TestMBC3RTCSelect checks RAM is unmapped while a RTC register is selected (RTC not emulated)
+func TestMBC3RTCSelect(t *testing.T) {
+	c := newTestCartridge(t, 0x10, 0x05, 0x03) // MBC3 + TIMER + RAM + BATTERY
+	c.Write(0x0000, 0x0A)
+	c.Write(0xA000, 0x12)
+	c.Write(0x4000, 0x08) // RTC seconds
+	c.Write(0xA000, 0x34)
+	if v := c.Read(0xA000); v != 0xFF {
+		t.Errorf("RTC selected : expected 0xFF, got 0x%02X", v)
+	}
+	if c.Mapped(0xA000) != nil {
+		t.Errorf("RTC selected : RAM mapped")
+	}
+	c.Write(0x4000, 0x00)
+	if v := c.Read(0xA000); v != 0x12 {
+		t.Errorf("RAM bank 0 : expected 0x12, got 0x%02X", v)
+	}
+}
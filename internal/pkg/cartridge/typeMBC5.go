@@ -1,22 +1,44 @@
 package cartridge
 
 import (
-	"fmt"
 	"log"
 )
 
 type mbc5 struct {
 	data      []uint8
-	romBank   uint
+	romBank   uint // 9 bits : 2000-2FFF lower 8 bits, 3000-3FFF bit 8
 	nbROMBank uint
 
 	ram       []uint8
 	ramBank   uint
 	nbRAMBank uint
 
-	ramTimerEnable bool
+	ramEnable bool
+	rumble    bool // RAM bank bit 3 drives the motor
+}
 
-	rtcEnable bool
+func (c *mbc5) ROMBank(addr uint16) uint {
+	if addr < 0x4000 {
+		return 0
+	}
+	return c.romBank & (c.nbROMBank - 1)
+}
+
+func (c *mbc5) RAMBank() uint { return c.ramBank }
+func (c *mbc5) ROM() []uint8  { return c.data }
+func (c *mbc5) RAM() []uint8  { return c.ram }
+
+func (c *mbc5) Mapped(addr uint16) []uint8 {
+	switch {
+	case addr <= 0x3FFF:
+		return romArea(c.data, 0)
+	case addr <= 0x7FFF:
+		return romArea(c.data, c.romBank&(c.nbROMBank-1))
+	case c.ramEnable:
+		return ramArea(c.ram, c.ramBank)
+	default:
+		return nil
+	}
 }
 
 func (c *mbc5) Read(addr uint16) uint8 {
@@ -24,12 +46,12 @@ func (c *mbc5) Read(addr uint16) uint8 {
 	case addr >= 0x0000 && addr <= 0x3FFF: // ROM CART FIXED
 		return c.data[addr]
 	case addr >= 0x4000 && addr <= 0x7FFF: // ROM CART BANK N
-		return c.data[uint(addr)-0x4000+c.romBank*romBankSizeInt]
+		return c.data[romOffset(c.romBank&(c.nbROMBank-1), addr)]
 	case addr >= 0xA000 && addr <= 0xBFFF: // CART RAM
-		if c.ramTimerEnable {
-			return c.ram[uint(addr)-0xA000+c.ramBank*ramBankSizeInt]
+		if c.ramEnable && len(c.ram) > 0 {
+			return c.ram[ramOffset(c.ram, c.ramBank, addr)]
 		}
-		return 0x00
+		return 0xFF // Open bus
 	default:
 		log.Fatalf("MEMORY UNREACHABLE : 0x%04X", addr)
 		return 0x00
@@ -40,31 +62,36 @@ func (c *mbc5) Write(addr uint16, value uint8) {
 	switch {
 	// BANK CONTROLLER
 
-	// 0000-1FFF - RAM and Timer Enable (Write Only)
+	// 0000-1FFF - RAM Enable (Write Only)
 	case addr >= 0x0000 && addr <= 0x1FFF:
-		c.ramTimerEnable = value&0xF == 0x0A
+		c.ramEnable = value&0xF == 0x0A
 
-	// 2000-3FFF - ROM Bank Number (Write Only)
-	case addr >= 0x2000 && addr <= 0x3FFF:
-		c.changeROMBank(value)
+	// 2000-2FFF - Low 8 bits of ROM Bank Number (Write Only)
+	// Unlike MBC1 & MBC3, bank 0 can be mapped at 4000-7FFF
+	case addr >= 0x2000 && addr <= 0x2FFF:
+		c.romBank = c.romBank&0x100 | uint(value)
 
-	// 4000-5FFF - RAM Bank Number - or - RTC Register Select (Write Only)
+	// 3000-3FFF - High bit of ROM Bank Number (Write Only)
+	case addr >= 0x3000 && addr <= 0x3FFF:
+		c.romBank = uint(value&0x01)<<8 | c.romBank&0xFF
+
+	// 4000-5FFF - RAM Bank Number (Write Only)
+	// Bit 3 drives the rumble motor on rumble cartridges
 	case addr >= 0x4000 && addr <= 0x5FFF:
-		if value <= 0x03 { // 4 banks max
-			c.ramBank = uint(value)
-			c.rtcEnable = false
-		} else {
-			c.rtcEnable = true
+		if c.rumble {
+			value &= 0x07
+		}
+		if c.nbRAMBank > 0 {
+			c.ramBank = uint(value&0x0F) & (c.nbRAMBank - 1)
 		}
 
-	// 6000-7FFF - Latch Clock Data (Write Only)
+	// 6000-7FFF - Nothing
 	case addr >= 0x6000 && addr <= 0x7FFF:
-		fmt.Printf("TODO : Latch Clock Data %X : %X\n", addr, value)
 
 	// CART RAM
 	case addr >= 0xA000 && addr <= 0xBFFF:
-		if c.ramTimerEnable && !c.rtcEnable {
-			c.ram[addr-0xA000] = value
+		if c.ramEnable && len(c.ram) > 0 {
+			c.ram[ramOffset(c.ram, c.ramBank, addr)] = value
 		}
 	// OFF RANGE
 	default:
@@ -72,47 +99,21 @@ func (c *mbc5) Write(addr uint16, value uint8) {
 	}
 }
 
-func (c *mbc5) changeROMBank(v uint8) {
-	value := uint(v & 0x7F)
-	if value > c.nbROMBank {
-		panic(fmt.Sprintf("ROM BANK 0x%02X DOES NOT EXIST. MAX : 0x%02X", value, c.nbROMBank))
+func newMBC5(data []byte) (Cartridge, error) {
+	rom, nbROMBank, err := newBankedROM(data)
+	if err != nil {
+		return nil, err
 	}
-	if value == 0 {
-		c.romBank = 1
-		return
+	ram, nbRAMBank, err := newBankedRAM(data)
+	if err != nil {
+		return nil, err
 	}
-	c.romBank = value & 0x7F
-}
-
-func newMBC5(data []byte) Cartridge {
-	cartridge := &mbc5{
-		data:    data,
-		romBank: 1,
-	}
-	switch ramSize := ReadRAMSize(cartridge); ramSize {
-	case 0x00: // 00h - None
-		cartridge.nbRAMBank = 0
-	case 0x02: // 02h - 8 Kbytes
-		cartridge.nbRAMBank = 1
-		cartridge.ram = make([]uint8, 1024*8)
-	case 0x03: // 03h -		32 KBytes (4 banks of 8KBytes each)
-		cartridge.nbRAMBank = 4
-		cartridge.ram = make([]uint8, 4*1024*8)
-	default:
-		panic(fmt.Sprintf("RAM SIZE NOT MANAGED FOR MBC5 : 0x%02X", ramSize))
-	}
-
-	switch romSize := ReadROMSize(cartridge); romSize {
-
-	case 0x05: // 05h -		1MByte (64 banks)
-		cartridge.nbROMBank = 64
-	case 0x06: // 06h -   2MByte (128 banks) - only 125 banks used by MBC1
-		cartridge.nbROMBank = 128
-	case 0x07: // 07h -   4MByte (256 banks)
-		cartridge.nbROMBank = 256
-	default:
-		panic(fmt.Sprintf("ROM SIZE NOT MANAGED FOR MBC5 : 0x%02X", romSize))
-	}
-
-	return cartridge
+	return &mbc5{
+		data:      rom,
+		romBank:   1,
+		nbROMBank: nbROMBank,
+		ram:       ram,
+		nbRAMBank: nbRAMBank,
+		rumble:    data[0x147] >= 0x1C && data[0x147] <= 0x1E,
+	}, nil
 }
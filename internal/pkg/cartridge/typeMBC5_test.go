@@ -0,0 +1,51 @@
+package cartridge
+
+import (
+	"testing"
+)
+
+func TestMBC5ROMBanks(t *testing.T) {
+	tests := []struct {
+		name    string
+		romSize uint8
+		writes  []bankWrite
+		bank    uint
+	}{
+		{"default", 0x08, nil, 1},
+		{"bank 0 allowed", 0x08, []bankWrite{{0x2000, 0x00}}, 0},
+		{"bank FFh", 0x08, []bankWrite{{0x2FFF, 0xFF}}, 0xFF},
+		{"9th bit", 0x08, []bankWrite{{0x2000, 0x05}, {0x3000, 0x01}}, 0x105},
+		{"9th bit only", 0x08, []bankWrite{{0x2000, 0x05}, {0x3000, 0xFE}}, 0x05},
+		{"masked by rom size", 0x05, []bankWrite{{0x2000, 0xC3}, {0x3000, 0x01}}, 0x03},
+	}
+	for _, tt := range tests {
+		c := newTestCartridge(t, 0x1B, tt.romSize, 0x03)
+		for _, w := range tt.writes {
+			c.Write(w.addr, w.value)
+		}
+		if bank := readBank(c, 0x0000); bank != 0 {
+			t.Errorf("%s : 0000-3FFF : expected bank 0x00, got 0x%02X", tt.name, bank)
+		}
+		if bank := readBank(c, 0x4000); bank != tt.bank {
+			t.Errorf("%s : 4000-7FFF : expected bank 0x%03X, got 0x%03X", tt.name, tt.bank, bank)
+		}
+	}
+}
+
+func TestMBC5RAMBanks(t *testing.T) {
+	tests := []struct {
+		name     string
+		cType    uint8
+		ramSize  uint8
+		banks    []uint8
+		expected []uint8
+	}{
+		{"128KB", 0x1B, 0x04, []uint8{0, 1, 7, 15}, []uint8{1, 2, 8, 16}},
+		{"32KB : wraps", 0x1B, 0x03, []uint8{0, 4, 9}, []uint8{5, 5, 10}},
+		{"rumble : bit 3 ignored", 0x1E, 0x04, []uint8{0x01, 0x09}, []uint8{0x0A, 0x0A}},
+	}
+	for _, tt := range tests {
+		c := newTestCartridge(t, tt.cType, 0x05, tt.ramSize)
+		testRAMBanks(t, c, func(bank uint8) { c.Write(0x4000, bank) }, tt.banks, tt.expected)
+	}
+}
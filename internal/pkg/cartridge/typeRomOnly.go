@@ -1,7 +1,6 @@
 package cartridge
 
 import (
-	"fmt"
 	"log"
 )
 
@@ -13,6 +12,8 @@ func (c *romOnly) Read(addr uint16) uint8 {
 	switch {
 	case addr >= 0x0000 && addr < 0x8000: // ROM CART
 		return c.data[addr]
+	case addr >= 0xA000 && addr <= 0xBFFF: // NO CART RAM
+		return 0xFF // Open bus
 	default:
 		log.Fatalf("MEMORY UNREACHABLE : 0x%04X", addr)
 		return 0x00
@@ -22,14 +23,31 @@ func (c *romOnly) Read(addr uint16) uint8 {
 func (c *romOnly) Write(addr uint16, value uint8) {
 	switch {
 	case addr >= 0x0000 && addr < 0x8000: // ROM CART
-		fmt.Printf("CART ROM IS READ ONLY !!! %X : %X\n", addr, value)
+		// CART ROM IS READ ONLY
+	case addr >= 0xA000 && addr <= 0xBFFF: // NO CART RAM
 	default:
 		log.Fatalf("MEMORY UNREACHABLE : 0x%04X", addr)
 	}
 }
 
-func newROMOnly(data []uint8) Cartridge {
-	return &romOnly{
-		data: data,
+func (c *romOnly) ROMBank(addr uint16) uint { return uint(addr >> 14) }
+func (c *romOnly) RAMBank() uint            { return 0 }
+func (c *romOnly) ROM() []uint8             { return c.data }
+func (c *romOnly) RAM() []uint8             { return nil }
+
+func (c *romOnly) Mapped(addr uint16) []uint8 {
+	if addr <= 0x7FFF {
+		return romArea(c.data, uint(addr>>14))
+	}
+	return nil // No RAM
+}
+
+func newROMOnly(data []uint8) (Cartridge, error) {
+	rom, _, err := newBankedROM(data)
+	if err != nil {
+		return nil, err
 	}
+	return &romOnly{
+		data: rom,
+	}, nil
 }
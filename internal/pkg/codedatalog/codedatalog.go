@@ -0,0 +1,11 @@
+// Package codedatalog defines the flags of the code/data log updated by the MMU.
+// They are exported by the cdl package.
+package codedatalog
+
+// Flags of a ROM byte
+const (
+	Code    uint8 = 0x01 // First byte of an executed instruction
+	Operand uint8 = 0x02 // Other bytes of an executed instruction (operands, CB opcodes)
+	Data    uint8 = 0x04 // Read by the CPU
+	DMA     uint8 = 0x08 // Source of an OAM or VRAM DMA transfer
+)
@@ -6,84 +6,306 @@ import (
 	"github.com/jmontupet/gbcore/internal/pkg/cpu/registers"
 
 	"github.com/jmontupet/gbcore/internal/pkg/interrupt"
-
-	"github.com/jmontupet/gbcore/internal/pkg/mmu"
+	"github.com/jmontupet/gbcore/internal/pkg/ioports"
 )
 
+// Bus is the memory seen by the CPU (the MMU, or a flat memory in tests)
+type Bus interface {
+	Read(addr uint16) uint8
+	Write(addr uint16, value uint8)
+	// Fetch reads an instruction byte : the opcode or one of its operands
+	Fetch(addr uint16, opcode bool) uint8
+	// Peek reads without side effects (idle loops checks)
+	Peek(addr uint16) uint8
+}
+
+// Clock is advanced by the CPU on each machine cycle (4 clocks in normal speed).
+// It keeps the other components in sync with the CPU memory accesses.
+type Clock interface {
+	MCycle()
+	// Idle runs up to max machine cycles without CPU activity (HALT), stopping at the
+	// next event of the components. It returns the machine cycles run, at least 1.
+	Idle(max uint8) uint8
+	// Sync brings the components up to date before a change outside of the bus (STOP).
+	Sync()
+	// ResetDiv resets DIV and its internal counter (STOP), then reschedules the components.
+	ResetDiv()
+	// Skippable returns the machine cycles before the next event of the components, when the time
+	// of an idle loop can be skipped (0 otherwise).
+	Skippable() uint64
+}
+
+// haltMaxCycles limits the machine cycles of a halted Tick
+const haltMaxCycles = 0xFF
+
 // CPU emulate GameBoy CPU
 type CPU struct {
 	regs        registers.Registers
-	mmu         *mmu.MMU
+	bus         Bus
 	interrupts  *interrupt.Manager
+	clock       Clock
+	key1        *ioports.Ptr // FF4D - Prepare speed switch
 	halt        bool
+	haltBug     bool  // Next opcode fetch doesn't increment PC
+	imeDelay    uint8 // Instructions to execute before EI takes effect
+	stopped     bool
 	DoubleSpeed bool
+
+	// FastForward skips the idle time (HALT, idle loops) up to the next event of the components,
+	// instead of running it cycle by cycle. The emulation result is the same.
+	FastForward bool
+	loop        idleLoop
+
+	// Illegal opcode executed : CPU frozen until reset
+	locked       bool
+	lockedPC     uint16
+	lockedOpcode uint8
+
+	speedSwitchDelay uint  // Machine cycles before the CPU restarts after a speed switch
+	cycles           uint8 // Machine cycles used by the current Tick
+
+	execHooks []func() // Called before each instruction
+	breaking  bool     // An exec hook stopped the execution before the instruction (Break)
+}
+
+// Regs gives access to the CPU registers (debug tools)
+func (c *CPU) Regs() *registers.Registers {
+	return &c.regs
+}
+
+// AddExecHook registers a function called before each instruction execution,
+// PC being the address of the instruction.
+func (c *CPU) AddExecHook(hook func()) {
+	c.execHooks = append(c.execHooks, hook)
+}
+
+// Break stops the execution before the current instruction. Called from an exec hook,
+// the next hooks are skipped and Tick returns : the instruction runs on the next Tick.
+func (c *CPU) Break() {
+	c.breaking = true
 }
 
-// readUint16 read next uint16 value from the mmu at ProgramCounter address and inc2 PC
+// idle runs an internal machine cycle (no memory access)
+func (c *CPU) idle() {
+	c.clock.MCycle()
+	c.cycles++
+}
+
+// read runs a machine cycle and reads memory at its end
+func (c *CPU) read(addr uint16) uint8 {
+	c.idle()
+	value := c.bus.Read(addr)
+	c.loop.read(addr, value, c.cycles)
+	return value
+}
+
+// write runs a machine cycle and writes memory at its end
+func (c *CPU) write(addr uint16, value uint8) {
+	c.idle()
+	c.loop.pure = false
+	c.bus.Write(addr, value)
+}
+
+// fetch runs a machine cycle and reads an instruction byte at its end
+func (c *CPU) fetch(addr uint16, opcode bool) uint8 {
+	c.idle()
+	return c.bus.Fetch(addr, opcode)
+}
+
+// readUint16 read next uint16 value from the bus at ProgramCounter address and inc2 PC
 func (c *CPU) readUint16() uint16 {
+	lo := uint16(c.readUint8())
+	hi := uint16(c.readUint8())
+	return hi<<8 | lo
+}
+
+// fetchOpcode read the opcode at address PC and inc PC (except after the HALT bug)
+func (c *CPU) fetchOpcode() uint8 {
 	pc := c.regs.GetPC()
-	c.regs.SetPC(pc + 2)
-	return uint16(c.mmu.Read(pc)) | (uint16(c.mmu.Read(pc+1)) << 8)
+	if c.haltBug {
+		c.haltBug = false
+	} else {
+		c.regs.SetPC(pc + 1)
+	}
+	return c.fetch(pc, true)
 }
 
-// readUint8 read next uint8 value from the mmu at ProgramCounter address and inc PC
+// readUint8 read next uint8 value (operand) from the bus at ProgramCounter address and inc PC
 func (c *CPU) readUint8() uint8 {
 	pc := c.regs.GetPC()
 	c.regs.SetPC(pc + 1)
-	return c.mmu.Read(pc)
+	return c.fetch(pc, false)
 }
 
-// stop CPU restart the cpu at new speed if required
+// speedSwitchCycles is the CPU pause duration after a speed switch (machine cycles)
+const speedSwitchCycles = 2050
+
+// stop enters the low power mode, or switches the CPU speed if it was prepared in KEY1.
+// The byte following STOP is skipped. DIV is reset in both cases.
 func (c *CPU) stop() {
-	println("STOP CPU")
-
-	reg := c.mmu.Read(0xFF4D) // CPU speed / CGB mode
-	prepareSwitchMode := reg & 0x01
-	currentMode := reg >> 7
-
-	if prepareSwitchMode == 1 {
-		if currentMode == 1 {
-			println("SWITCH TO NORMAL SPEED MODE")
-			// Switch to Normal Mode
-			c.DoubleSpeed = false
-			c.mmu.Write(0xFF4D, 0x00)
-		} else {
-			println("SWITCH TO DOUBLE SPEED MODE")
-			// Switch to Double Speed Mode
-			c.DoubleSpeed = true
-			c.mmu.Write(0xFF4D, 0x80)
-		}
+	c.regs.SetPC(c.regs.GetPC() + 1)
+	c.clock.Sync()
+	c.clock.ResetDiv()
+
+	key1 := c.key1.Get() // CPU speed / CGB mode
+	if key1&0x01 == 0 {
+		c.stopped = true
+		return
+	}
+	c.DoubleSpeed = !c.DoubleSpeed
+	if c.DoubleSpeed {
+		c.key1.Set(0x80)
+	} else {
+		c.key1.Set(0x00)
 	}
+	c.clock.Sync() // Events rescheduled at the new speed
+	c.speedSwitchDelay = speedSwitchCycles
 }
 
-// Tick read the next opcode at address PC and execute corresponding instruction
-func (c *CPU) Tick() (clockUsed uint8) {
-	if addrInterrupt := c.interrupts.GetNext(); addrInterrupt != 0x0000 {
-		c.halt = false
-		call(c, addrInterrupt)
-		return 8
+// Stopped returns true while the CPU is in STOP mode (system clock stopped)
+func (c *CPU) Stopped() bool {
+	return c.stopped
+}
+
+// joypadLineLow returns true if a selected joypad line is low (key pressed).
+// The line is peeked : the program doesn't read P1.
+func (c *CPU) joypadLineLow() bool {
+	return c.bus.Peek(0xFF00)&0x0F != 0x0F
+}
+
+// Tick read the next opcode at address PC and execute corresponding instruction.
+// The clock is advanced during the execution, on each memory access.
+// It returns the number of machine cycles used.
+func (c *CPU) Tick() (cyclesUsed uint8) {
+	c.cycles = 0
+	if c.locked || c.stopped || c.speedSwitchDelay > 0 || c.halt {
+		c.loop.pure = false
+	}
+	if c.locked {
+		// Nothing can wake up the CPU, but the clock keeps running
+		c.idle()
+		return c.cycles
+	}
+	if c.stopped {
+		// Clock stopped : wake up when a joypad line goes low
+		if c.joypadLineLow() {
+			c.stopped = false
+		}
+		return c.cycles
+	}
+	if c.speedSwitchDelay > 0 {
+		c.speedSwitchDelay--
+		c.idle()
+		return c.cycles
 	}
 	if c.halt {
-		return 1
+		// Any pending interrupt wakes the CPU up, even with IME disabled
+		if !c.interrupts.Pending() {
+			if c.FastForward {
+				// Nothing happens until the next event of the components
+				c.cycles += c.clock.Idle(haltMaxCycles)
+			} else {
+				c.idle()
+			}
+			return c.cycles
+		}
+		c.halt = false
+		c.idle()
+	}
+	if c.interrupts.MasterEnabled() && c.interrupts.Pending() {
+		c.dispatchInterrupt()
+		return c.cycles
+	}
+
+	pc := c.regs.GetPC()
+	if !c.runExecHooks() {
+		return c.cycles
+	}
+	c.loop.instruction(&c.regs, c.imeDelay)
+	c.execute()
+
+	// EI takes effect after the next instruction
+	if c.imeDelay > 0 {
+		c.imeDelay--
+		if c.imeDelay == 0 {
+			c.interrupts.EnableMaster()
+		}
+	}
+	if c.FastForward {
+		c.trackLoop(pc)
 	}
-	code := c.readUint8()
+	return c.cycles
+}
+
+// runExecHooks calls the exec hooks. It returns false if a hook stopped the execution (Break).
+func (c *CPU) runExecHooks() bool {
+	for _, hook := range c.execHooks {
+		hook()
+		if c.breaking {
+			c.breaking = false
+			return false
+		}
+	}
+	return true
+}
+
+// execute fetch and run the next instruction
+func (c *CPU) execute() {
+	code := c.fetchOpcode()
 	if code == 0xCB {
 		code = c.readUint8()
 		if instructionCBList[code] != nil {
-			return instructionCBList[code](c)
+			instructionCBList[code](c)
+			return
 		}
 		log.Fatalf("INVALID CB OPCODE : %X \n", code)
-		return 0
+		return
 	}
 	if instructionList[code] != nil {
-		return instructionList[code](c)
+		instructionList[code](c)
+		return
+	}
+	c.lock(code)
+}
+
+// lock freezes the CPU after an illegal opcode (0xD3, 0xDB, 0xDD, 0xE3, 0xE4, 0xEB, 0xEC, 0xED, 0xF4, 0xFC, 0xFD)
+func (c *CPU) lock(code uint8) {
+	c.locked = true
+	c.lockedPC = c.regs.GetPC() - 1
+	c.lockedOpcode = code
+}
+
+// Locked returns true if the CPU is locked by an illegal opcode, with its address and value
+func (c *CPU) Locked() (locked bool, pc uint16, opcode uint8) {
+	return c.locked, c.lockedPC, c.lockedOpcode
+}
+
+// dispatchInterrupt calls the highest priority pending interrupt (5 machine cycles).
+//
+// The interrupt is chosen after the push of PC high byte : if this push
+// overwrites IE (SP = 0x0000) and disables it, the dispatch is cancelled and PC is set to 0x0000.
+func (c *CPU) dispatchInterrupt() {
+	c.interrupts.DisableMaster()
+	c.idle()
+	c.idle()
+
+	pc := c.regs.GetPC()
+	if c.haltBug { // EI ; HALT with an interrupt pending : HALT runs again after the handler
+		c.haltBug = false
+		pc--
 	}
-	log.Fatalf("INVALID OPCODE : %X \n", code)
-	return 0
+	sp := c.regs.GetSP()
+	c.write(sp-1, uint8(pc>>8))
+	addr := c.interrupts.Acknowledge()
+	c.write(sp-2, uint8(pc&0x00FF))
+	c.regs.SetSP(sp - 2)
+
+	c.regs.SetPC(addr)
+	c.idle()
 }
 
 // NewCPU return a new initialised GameBoy CPU
-func NewCPU(memory *mmu.MMU, interrupts *interrupt.Manager) *CPU {
+func NewCPU(memory Bus, interrupts *interrupt.Manager, io *ioports.IOPorts, clock Clock) *CPU {
 	var regs = registers.Registers{}
 
 	// SHORTCUT TO INIT CPU & MEMORY WITHOUT BOOT SEQUENCE
@@ -128,8 +350,10 @@ func NewCPU(memory *mmu.MMU, interrupts *interrupt.Manager) *CPU {
 	memory.Write(0xFFFF, 0x00) // IE
 
 	return &CPU{
-		mmu:        memory,
+		bus:        memory,
 		interrupts: interrupts,
+		clock:      clock,
+		key1:       io.NewPtr(0xFF4D),
 		regs:       regs,
 	}
 }
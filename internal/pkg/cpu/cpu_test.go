@@ -0,0 +1,364 @@
+package cpu
+
+import (
+	"testing"
+
+	"github.com/jmontupet/gbcore/internal/pkg/cartridge"
+	"github.com/jmontupet/gbcore/internal/pkg/gpu"
+	"github.com/jmontupet/gbcore/internal/pkg/hram"
+	"github.com/jmontupet/gbcore/internal/pkg/interrupt"
+	"github.com/jmontupet/gbcore/internal/pkg/ioports"
+	"github.com/jmontupet/gbcore/internal/pkg/joypad"
+	"github.com/jmontupet/gbcore/internal/pkg/mmu"
+	"github.com/jmontupet/gbcore/internal/pkg/serial"
+	"github.com/jmontupet/gbcore/internal/pkg/timers"
+	"github.com/jmontupet/gbcore/internal/pkg/unusableaddr"
+	"github.com/jmontupet/gbcore/internal/pkg/wram"
+	"github.com/jmontupet/gbcore/pkg/disasm"
+	"github.com/jmontupet/gbcore/pkg/nullio"
+)
+
+// testClock counts machine cycles and runs an optional callback on each of them
+type testClock struct {
+	cycles  int
+	onCycle func()
+}
+
+func (c *testClock) MCycle() {
+	c.cycles++
+	if c.onCycle != nil {
+		c.onCycle()
+	}
+}
+
+func (c *testClock) Idle(max uint8) uint8 {
+	c.MCycle()
+	return 1
+}
+
+func (c *testClock) Sync() {}
+
+func (c *testClock) ResetDiv() {}
+
+func (c *testClock) Skippable() uint64 { return 0 }
+
+// newTestCPU returns a CPU running program at 0x0100 (ROM only cartridge)
+func newTestCPU(t *testing.T, program ...uint8) (*CPU, *mmu.MMU, *testClock) {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x0100:], program)
+	cart, err := cartridge.NewCartridge(rom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io := ioports.NewGBIOPorts(true)
+	interrupt := interrupt.NewInterrupt(io)
+	memory := mmu.NewMMU(
+		cart,
+		gpu.NewGBGPU(io, nullio.NewNullFrameDrawer(), false),
+		io,
+		hram.NewGBHRAM(),
+		wram.NewWram(io),
+		interrupt,
+		joypad.NewJoypad(io),
+		serial.NewSerial(io),
+		timers.NewTimers(io),
+		unusableaddr.NewUnusableAddr(),
+	)
+	clock := &testClock{}
+	return NewCPU(memory, interrupt, io, clock), memory, clock
+}
+
+func TestInstructionCycles(t *testing.T) {
+	tests := []struct {
+		name     string
+		program  []uint8
+		expected []uint8 // Machine cycles for each instruction
+	}{
+		{"NOP", []uint8{0x00}, []uint8{1}},
+		{"LD BC,nn / INC BC / LD (BC),A", []uint8{0x01, 0x00, 0xC0, 0x03, 0x02}, []uint8{3, 2, 2}},
+		{"LD (nn),SP", []uint8{0x08, 0x00, 0xC0}, []uint8{5}},
+		{"PUSH BC / POP BC", []uint8{0xC5, 0xC1}, []uint8{4, 3}},
+		{"ADD HL,BC / LD SP,HL", []uint8{0x09, 0xF9}, []uint8{2, 2}},
+		{"ADD SP,n / LD HL,SP+n", []uint8{0xE8, 0x01, 0xF8, 0x01}, []uint8{4, 3}},
+		{"JR n", []uint8{0x18, 0x00}, []uint8{3}},
+		{"JR NZ,n (not taken)", []uint8{0xAF, 0x20, 0x00}, []uint8{1, 2}},
+		{"JR Z,n (taken)", []uint8{0xAF, 0x28, 0x00}, []uint8{1, 3}},
+		{"JP nn", []uint8{0xC3, 0x03, 0x01}, []uint8{4}},
+		{"JP NZ,nn (not taken)", []uint8{0xAF, 0xC2, 0x00, 0x00}, []uint8{1, 3}},
+		{"CALL nn / RET", []uint8{0xCD, 0x04, 0x01, 0x00, 0xC9}, []uint8{6, 4}},
+		{"CALL NZ,nn (not taken)", []uint8{0xAF, 0xC4, 0x00, 0x00}, []uint8{1, 3}},
+		{"CALL Z,nn / RET Z / RET NZ", []uint8{0xAF, 0xCC, 0x06, 0x01, 0xC0, 0x00, 0xC8}, []uint8{1, 6, 5, 2}},
+		{"RST 0x38", []uint8{0xFF}, []uint8{4}},
+		{"INC (HL)", []uint8{0x21, 0x00, 0xC0, 0x34}, []uint8{3, 3}},
+		{"CB RLC B / RLC (HL) / BIT 0,(HL)", []uint8{0x21, 0x00, 0xC0, 0xCB, 0x00, 0xCB, 0x06, 0xCB, 0x46}, []uint8{3, 2, 4, 3}},
+	}
+
+	for _, test := range tests {
+		cpu, _, clock := newTestCPU(t, test.program...)
+		for i, expected := range test.expected {
+			before := clock.cycles
+			got := cpu.Tick()
+			if got != expected || clock.cycles-before != int(expected) {
+				t.Errorf("%s (#%d) : expected %d cycles, got %d (clock %d)", test.name, i, expected, got, clock.cycles-before)
+			}
+		}
+	}
+}
+
+func TestMemoryAccessTiming(t *testing.T) {
+	// LD BC,0x1234 ; PUSH BC
+	cpu, memory, clock := newTestCPU(t, 0x01, 0x34, 0x12, 0xC5)
+	cpu.Tick()
+
+	// HRAM value seen by the other components on each machine cycle
+	var hi, lo []uint8
+	clock.onCycle = func() {
+		hi = append(hi, memory.Read(0xFFFD))
+		lo = append(lo, memory.Read(0xFFFC))
+	}
+	memory.Write(0xFFFD, 0)
+	memory.Write(0xFFFC, 0)
+	cpu.Tick()
+
+	// Fetch, internal, write high, write low
+	if expected := []uint8{0x00, 0x00, 0x00, 0x12}; string(hi) != string(expected) {
+		t.Errorf("high byte : expected %v, got %v", expected, hi)
+	}
+	if expected := []uint8{0x00, 0x00, 0x00, 0x00}; string(lo) != string(expected) {
+		t.Errorf("low byte : expected %v, got %v", expected, lo)
+	}
+	if got := memory.Read(0xFFFC); got != 0x34 {
+		t.Errorf("low byte after PUSH : expected 0x34, got 0x%02X", got)
+	}
+}
+
+func TestEIDelay(t *testing.T) {
+	// EI ; NOP ; NOP
+	cpu, memory, _ := newTestCPU(t, 0xFB, 0x00, 0x00)
+	memory.Write(0xFFFF, 0x01) // IE : V-Blank
+	memory.Write(0xFF0F, 0x01) // IF : V-Blank
+
+	cpu.Tick() // EI
+	cpu.Tick() // NOP : IME not set yet
+	if pc := cpu.regs.GetPC(); pc != 0x0102 {
+		t.Fatalf("interrupt dispatched before the end of the instruction following EI (PC 0x%04X)", pc)
+	}
+	if cycles := cpu.Tick(); cycles != 5 || cpu.regs.GetPC() != 0x0040 {
+		t.Fatalf("expected dispatch to 0x0040 in 5 cycles, got 0x%04X in %d", cpu.regs.GetPC(), cycles)
+	}
+	if ret := pop(cpu); ret != 0x0102 {
+		t.Errorf("expected return address 0x0102, got 0x%04X", ret)
+	}
+	if memory.Read(0xFF0F)&0x01 != 0 {
+		t.Error("interrupt request not acknowledged")
+	}
+
+	// EI ; DI : interrupts never enabled
+	cpu, memory, _ = newTestCPU(t, 0xFB, 0xF3, 0x00)
+	memory.Write(0xFFFF, 0x01)
+	memory.Write(0xFF0F, 0x01)
+	cpu.Tick()
+	cpu.Tick()
+	cpu.Tick()
+	if pc := cpu.regs.GetPC(); pc != 0x0103 {
+		t.Errorf("DI didn't cancel EI (PC 0x%04X)", pc)
+	}
+}
+
+func TestExecHookBreak(t *testing.T) {
+	// EI ; NOP ; NOP : break before the NOP following EI
+	cpu, memory, _ := newTestCPU(t, 0xFB, 0x00, 0x00)
+	memory.Write(0xFFFF, 0x01)
+	memory.Write(0xFF0F, 0x01)
+	breaks, calls := 1, 0
+	cpu.AddExecHook(func() {
+		if cpu.regs.GetPC() == 0x0101 && breaks > 0 {
+			breaks--
+			cpu.Break()
+		}
+	})
+	cpu.AddExecHook(func() { calls++ })
+
+	cpu.Tick() // EI
+	if cycles := cpu.Tick(); cycles != 0 || cpu.regs.GetPC() != 0x0101 || calls != 1 {
+		t.Fatalf("break : %d cycles, PC 0x%04X, %d calls of the next hook", cycles, cpu.regs.GetPC(), calls)
+	}
+	// The NOP runs on the next Tick, IME is still delayed
+	cpu.Tick()
+	if pc := cpu.regs.GetPC(); pc != 0x0102 || calls != 2 {
+		t.Fatalf("after the break : PC 0x%04X, %d calls of the next hook", pc, calls)
+	}
+	if cpu.Tick(); cpu.regs.GetPC() != 0x0040 {
+		t.Errorf("expected dispatch to 0x0040, got 0x%04X", cpu.regs.GetPC())
+	}
+}
+
+func TestHalt(t *testing.T) {
+	// HALT ; NOP : wake up without dispatch when IME is disabled
+	cpu, memory, _ := newTestCPU(t, 0x76, 0x00)
+	memory.Write(0xFFFF, 0x04) // IE : Timer
+	cpu.Tick()
+	for i := 0; i < 10; i++ {
+		if cycles := cpu.Tick(); cycles != 1 {
+			t.Fatalf("halted CPU used %d cycles", cycles)
+		}
+	}
+	if pc := cpu.regs.GetPC(); pc != 0x0101 {
+		t.Fatalf("halted CPU moved PC to 0x%04X", pc)
+	}
+	memory.Write(0xFF0F, 0x04)
+	cpu.Tick()
+	if pc := cpu.regs.GetPC(); pc != 0x0102 {
+		t.Errorf("CPU not woken up by a pending interrupt (PC 0x%04X)", pc)
+	}
+
+	// HALT bug : HALT ; INC A, with IME disabled and an interrupt pending
+	cpu, memory, _ = newTestCPU(t, 0x76, 0x3C)
+	memory.Write(0xFFFF, 0x04)
+	memory.Write(0xFF0F, 0x04)
+	a := cpu.regs.GetA()
+	cpu.Tick()
+	cpu.Tick()
+	if pc := cpu.regs.GetPC(); pc != 0x0101 {
+		t.Fatalf("HALT bug : PC incremented to 0x%04X", pc)
+	}
+	cpu.Tick()
+	if got := cpu.regs.GetA(); got != a+2 || cpu.regs.GetPC() != 0x0102 {
+		t.Errorf("HALT bug : expected INC A executed twice, got A=0x%02X PC=0x%04X", got, cpu.regs.GetPC())
+	}
+}
+
+// TestHaltBugDispatch : EI ; HALT with an interrupt pending, the interrupt is dispatched
+// right after HALT, which is pushed as the return address.
+func TestHaltBugDispatch(t *testing.T) {
+	cpu, memory, _ := newTestCPU(t, 0xFB, 0x76, 0x00) // EI ; HALT ; NOP
+	memory.Write(0xFFFF, 0x04)                        // IE : Timer
+	memory.Write(0xFF0F, 0x04)                        // IF : Timer
+	for i := 0; i < 3 && cpu.regs.GetPC() != 0x0050; i++ {
+		cpu.Tick()
+	}
+	if pc := cpu.regs.GetPC(); pc != 0x0050 {
+		t.Fatalf("interrupt not dispatched (PC 0x%04X)", pc)
+	}
+	sp := cpu.regs.GetSP()
+	if ret := uint16(memory.Read(sp+1))<<8 | uint16(memory.Read(sp)); ret != 0x0101 {
+		t.Errorf("return address : expected the HALT address 0x0101, got 0x%04X", ret)
+	}
+	cpu.Tick() // Handler first opcode (NOP)
+	if pc := cpu.regs.GetPC(); pc != 0x0051 {
+		t.Errorf("handler first opcode : expected PC 0x0051, got 0x%04X", pc)
+	}
+}
+
+func TestInterruptCancelledByIEPush(t *testing.T) {
+	cpu, memory, _ := newTestCPU(t, 0x00)
+	memory.Write(0xFFFF, 0x02) // IE : LCD STAT
+	memory.Write(0xFF0F, 0x02) // IF : LCD STAT
+	cpu.interrupts.EnableMaster()
+	cpu.regs.SetSP(0x0000) // PC high byte (0x01) pushed into IE
+
+	if cycles := cpu.Tick(); cycles != 5 {
+		t.Errorf("expected 5 cycles, got %d", cycles)
+	}
+	if pc := cpu.regs.GetPC(); pc != 0x0000 {
+		t.Errorf("expected cancelled dispatch to 0x0000, got 0x%04X", pc)
+	}
+}
+
+func TestStop(t *testing.T) {
+	// STOP ; (skipped) ; NOP
+	cpu, memory, _ := newTestCPU(t, 0x10, 0xFF, 0x00)
+	memory.Write(0xFF00, 0x20) // Select direction keys
+	cpu.Tick()
+	if !cpu.Stopped() || cpu.regs.GetPC() != 0x0102 {
+		t.Fatalf("expected stopped CPU at 0x0102, got %v at 0x%04X", cpu.Stopped(), cpu.regs.GetPC())
+	}
+	if cycles := cpu.Tick(); cycles != 0 || !cpu.Stopped() {
+		t.Fatalf("stopped CPU used %d cycles", cycles)
+	}
+
+	// The joypad lines are polled without CPU reads
+	reads := 0
+	memory.AddReadHook(0xFF00, 0xFF00, func(addr uint16, value uint8) uint8 {
+		reads++
+		return value
+	})
+	cpu.Tick()
+	if reads != 0 {
+		t.Errorf("stopped CPU read P1 %d times", reads)
+	}
+}
+
+func TestSpeedSwitch(t *testing.T) {
+	// STOP ; (skipped) ; NOP
+	cpu, memory, clock := newTestCPU(t, 0x10, 0x00, 0x00)
+	memory.Write(0xFF4D, 0x81) // Prepare switch, bit 7 read only
+	if got := memory.Read(0xFF4D); got != 0x7F {
+		t.Fatalf("KEY1 : expected 0x7F, got 0x%02X", got)
+	}
+	cpu.Tick()
+	if cpu.Stopped() || !cpu.DoubleSpeed {
+		t.Fatal("speed not switched")
+	}
+	if got := memory.Read(0xFF4D); got != 0xFE {
+		t.Errorf("KEY1 : expected 0xFE, got 0x%02X", got)
+	}
+
+	before := clock.cycles
+	for cpu.regs.GetPC() != 0x0103 {
+		cpu.Tick()
+	}
+	if pause := clock.cycles - before - 1; pause != speedSwitchCycles {
+		t.Errorf("expected a %d cycles pause, got %d", speedSwitchCycles, pause)
+	}
+}
+
+func TestIllegalOpcodeLock(t *testing.T) {
+	cpu, memory, clock := newTestCPU(t, 0x00, 0xDD, 0x00)
+	memory.Write(0xFFFF, 0x01)
+	cpu.Tick()
+	cpu.Tick()
+	locked, pc, opcode := cpu.Locked()
+	if !locked || pc != 0x0101 || opcode != 0xDD {
+		t.Fatalf("expected lock at 0x0101 (0xDD), got %v at 0x%04X (0x%02X)", locked, pc, opcode)
+	}
+
+	// Interrupts can't restart the CPU, the clock keeps running
+	cpu.interrupts.EnableMaster()
+	memory.Write(0xFF0F, 0x01)
+	before := clock.cycles
+	for i := 0; i < 10; i++ {
+		cpu.Tick()
+	}
+	if clock.cycles-before != 10 || cpu.regs.GetPC() != 0x0102 {
+		t.Errorf("locked CPU : %d cycles, PC 0x%04X", clock.cycles-before, cpu.regs.GetPC())
+	}
+}
+
+// TestDisasmCycles checks the disassembler table against the CPU timings
+func TestDisasmCycles(t *testing.T) {
+	for i := 0; i < 0x200; i++ {
+		code, next := uint8(i), uint8(0x00)
+		if i >= 0x100 {
+			code, next = 0xCB, uint8(i)
+		} else if code == 0xCB {
+			continue
+		}
+		opcode := disasm.Lookup(code, next)
+		if opcode.Illegal {
+			continue
+		}
+
+		cpu, _, _ := newTestCPU(t, code, next, 0x00)
+		expected := opcode.Cycles
+		if opcode.CyclesTaken != opcode.Cycles {
+			// Initial flags : Z & C set -> "Z" and "C" conditions are true
+			if code>>3&1 == 1 {
+				expected = opcode.CyclesTaken
+			}
+		}
+		if got := cpu.Tick(); got != expected {
+			t.Errorf("%02X %02X %q : expected %d cycles, got %d", code, next, opcode.Mnemonic, expected, got)
+		}
+	}
+}
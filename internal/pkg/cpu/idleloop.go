@@ -0,0 +1,156 @@
+package cpu
+
+import (
+	"github.com/jmontupet/gbcore/internal/pkg/cpu/registers"
+)
+
+// Idle loops are busy-wait loops polling a register or a RAM flag, such as :
+//
+//	wait: LDH A, [$44]
+//	      CP $90
+//	      JR NZ, wait
+//
+// An iteration without writes, ending with the registers it started with, is a fixed point :
+// the next iterations are identical as long as no interrupt is dispatched and the values read
+// don't change. Both only happen on the events of the components (line change, interrupt request...),
+// so the iterations are skipped, checking the reads and the interrupts at each instruction, until
+// the instruction where something changes. This instruction is then executed normally.
+
+// Limits of the idle loops
+const (
+	maxLoopLength       = 16 // Bytes from the start to the backward jump
+	maxLoopInstructions = 8
+	maxLoopReads        = 4
+)
+
+// Registers counting without events : loops polling them are not idle
+const (
+	divAddr  uint16 = 0xFF04
+	timaAddr uint16 = 0xFF05
+)
+
+type loopInstruction struct {
+	offset uint // Machine cycles from the loop start
+	regs   registers.Registers
+}
+
+type loopRead struct {
+	offset uint // Machine cycles from the loop start, read cycle included
+	addr   uint16
+	value  uint8
+}
+
+// idleLoop records the current iteration of a small loop
+type idleLoop struct {
+	start  uint16 // Loop start : target of the backward jump
+	jump   uint16 // Address of the backward jump
+	ime    bool
+	cycles uint // Machine cycles since the start
+	pure   bool // No writes, no counters read, no interrupt, EI nor HALT since the start
+
+	instructions   [maxLoopInstructions]loopInstruction
+	nbInstructions int
+	reads          [maxLoopReads]loopRead
+	nbReads        int
+}
+
+// reset starts recording a new iteration (the records are overwritten)
+func (l *idleLoop) reset(start, jump uint16, ime bool) {
+	l.start, l.jump, l.ime = start, jump, ime
+	l.cycles, l.pure = 0, true
+	l.nbInstructions, l.nbReads = 0, 0
+}
+
+// instruction records the start of an instruction
+func (l *idleLoop) instruction(regs *registers.Registers, imeDelay uint8) {
+	if !l.pure {
+		return
+	}
+	if l.nbInstructions == maxLoopInstructions || imeDelay != 0 {
+		l.pure = false
+		return
+	}
+	l.instructions[l.nbInstructions] = loopInstruction{offset: l.cycles, regs: *regs}
+	l.nbInstructions++
+}
+
+// read records a value read, cycles being the machine cycles of the instruction until the read
+func (l *idleLoop) read(addr uint16, value uint8, cycles uint8) {
+	if !l.pure {
+		return
+	}
+	if l.nbReads == maxLoopReads || addr == divAddr || addr == timaAddr {
+		l.pure = false
+		return
+	}
+	l.reads[l.nbReads] = loopRead{offset: l.cycles + uint(cycles), addr: addr, value: value}
+	l.nbReads++
+}
+
+// inLoopArea returns true if the code at addr can't change without CPU writes : ROM, WRAM, HRAM
+func inLoopArea(addr uint16) bool {
+	return addr < 0x8000 || addr >= 0xC000 && addr < 0xE000 || addr >= 0xFF80 && addr < 0xFFFF
+}
+
+// trackLoop follows the iterations of the small loops, once the instruction at pc is executed.
+// When an iteration is a fixed point, the next identical ones are skipped.
+func (c *CPU) trackLoop(pc uint16) {
+	loop := &c.loop
+	loop.cycles += uint(c.cycles)
+	start := c.regs.GetPC()
+	if start > pc || pc-start > maxLoopLength || !inLoopArea(start) { // Not a backward jump
+		return
+	}
+	ime := c.interrupts.MasterEnabled()
+	if loop.pure && loop.start == start && loop.jump == pc && loop.nbInstructions > 0 &&
+		loop.instructions[0].regs == c.regs && loop.ime == ime {
+		c.skipLoop()
+	}
+	loop.reset(start, pc, ime)
+}
+
+// skipLoop runs the identical iterations of the loop without executing them, up to the instruction
+// dispatching an interrupt or reading a new value. The CPU registers are set back to this instruction.
+func (c *CPU) skipLoop() {
+	loop := &c.loop
+	if len(c.execHooks) > 0 {
+		return
+	}
+	for {
+		for i := 0; i < loop.nbInstructions; i++ {
+			inst := &loop.instructions[i]
+			end := loop.cycles
+			if i+1 < loop.nbInstructions {
+				end = loop.instructions[i+1].offset
+			}
+			length := end - inst.offset
+			next := c.clock.Skippable()
+			if next == 0 || uint(c.cycles)+length > haltMaxCycles ||
+				c.interrupts.MasterEnabled() && c.interrupts.Pending() ||
+				!c.loopReadsUnchanged(inst.offset, end, next) {
+				c.regs = inst.regs
+				return
+			}
+			for length > 0 {
+				cycles := c.clock.Idle(uint8(length))
+				c.cycles += cycles
+				length -= uint(cycles)
+			}
+		}
+	}
+}
+
+// loopReadsUnchanged returns true if the reads of the instruction in [start, end] get the recorded values :
+// they happen before the next event and the current values are the same.
+func (c *CPU) loopReadsUnchanged(start, end uint, next uint64) bool {
+	for i := 0; i < c.loop.nbReads; i++ {
+		r := &c.loop.reads[i]
+		if r.offset <= start || r.offset > end {
+			continue
+		}
+		if uint64(r.offset-start) >= next || c.bus.Peek(r.addr) != r.value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,275 @@
+package cpu
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jmontupet/gbcore/internal/pkg/interrupt"
+	"github.com/jmontupet/gbcore/internal/pkg/ioports"
+)
+
+// SM83 single step tests (JSON vectors, one file per opcode : "00.json" ... "cb ff.json").
+// They are not distributed with the sources and are searched in $GBCORE_SM83_TESTS, or in testdata/sm83/.
+// A subset in the same format, a few cases per group of opcodes, is in testdata/sm83subset/ (see gen.go).
+const singleStepEnv = "GBCORE_SM83_TESTS"
+
+type singleStepState struct {
+	PC  uint16      `json:"pc"`
+	SP  uint16      `json:"sp"`
+	A   uint8       `json:"a"`
+	F   uint8       `json:"f"`
+	B   uint8       `json:"b"`
+	C   uint8       `json:"c"`
+	D   uint8       `json:"d"`
+	E   uint8       `json:"e"`
+	H   uint8       `json:"h"`
+	L   uint8       `json:"l"`
+	IME uint8       `json:"ime"`
+	IE  *uint8      `json:"ie"`
+	RAM [][2]uint16 `json:"ram"`
+}
+
+type singleStepTest struct {
+	Name    string           `json:"name"`
+	Initial singleStepState  `json:"initial"`
+	Final   singleStepState  `json:"final"`
+	Cycles  [][3]interface{} `json:"cycles"` // [addr, value, "r-m" | "-wm" | "---"] or null for internal cycles
+}
+
+// busAccess is a machine cycle seen on the test bus
+type busAccess struct {
+	kind  byte // 'r', 'w' or '-' (internal cycle)
+	addr  uint16
+	value uint8
+}
+
+func (a busAccess) String() string {
+	if a.kind == '-' {
+		return "---"
+	}
+	return fmt.Sprintf("%c 0x%04X = 0x%02X", a.kind, a.addr, a.value)
+}
+
+// flatBus is a 64KB memory without mapping, recording the CPU machine cycles.
+// It is used both as the CPU Bus and Clock : each cycle starts as internal and
+// becomes a read or a write when the CPU accesses the memory.
+type flatBus struct {
+	memory   [0x10000]uint8
+	accesses []busAccess
+}
+
+func (b *flatBus) MCycle() {
+	b.accesses = append(b.accesses, busAccess{kind: '-'})
+}
+
+func (b *flatBus) Idle(max uint8) uint8 {
+	b.MCycle()
+	return 1
+}
+
+func (b *flatBus) Sync() {}
+
+func (b *flatBus) ResetDiv() {}
+
+func (b *flatBus) Skippable() uint64 { return 0 }
+
+// record sets the access of the current machine cycle.
+// Accesses outside of a cycle (NewCPU initialisation) are not recorded.
+func (b *flatBus) record(access busAccess) {
+	if len(b.accesses) > 0 {
+		b.accesses[len(b.accesses)-1] = access
+	}
+}
+
+func (b *flatBus) Peek(addr uint16) uint8 { return b.memory[addr] }
+
+func (b *flatBus) Read(addr uint16) uint8 {
+	value := b.memory[addr]
+	b.record(busAccess{'r', addr, value})
+	return value
+}
+
+func (b *flatBus) Fetch(addr uint16, opcode bool) uint8 {
+	return b.Read(addr)
+}
+
+func (b *flatBus) Write(addr uint16, value uint8) {
+	b.memory[addr] = value
+	b.record(busAccess{'w', addr, value})
+}
+
+func singleStepDir(t *testing.T) string {
+	dir := os.Getenv(singleStepEnv)
+	if dir == "" {
+		dir = filepath.Join("testdata", "sm83")
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Skipf("SM83 TESTS NOT FOUND IN %s (SET %s)", dir, singleStepEnv)
+	}
+	return dir
+}
+
+func loadSingleStepTests(t *testing.T, path string) []singleStepTest {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Skipf("TEST FILE NOT FOUND : %s", path)
+	}
+	var tests []singleStepTest
+	if err := json.Unmarshal(data, &tests); err != nil {
+		t.Fatalf("INVALID TEST FILE %s : %v", path, err)
+	}
+	return tests
+}
+
+// newSingleStepCPU returns a CPU in the test initial state, on a flat bus
+func newSingleStepCPU(state *singleStepState) (*CPU, *flatBus) {
+	bus := &flatBus{}
+	io := ioports.NewGBIOPorts(true)
+	interrupts := interrupt.NewInterrupt(io)
+	c := NewCPU(bus, interrupts, io, bus)
+	bus.memory = [0x10000]uint8{} // Drop the IO initialisation
+
+	c.regs.SetPC(state.PC)
+	c.regs.SetSP(state.SP)
+	c.regs.SetA(state.A)
+	c.regs.SetF(state.F)
+	c.regs.SetB(state.B)
+	c.regs.SetC(state.C)
+	c.regs.SetD(state.D)
+	c.regs.SetE(state.E)
+	c.regs.SetH(state.H)
+	c.regs.SetL(state.L)
+	if state.IME != 0 {
+		interrupts.EnableMaster()
+	}
+	if state.IE != nil {
+		bus.memory[0xFFFF] = *state.IE
+	}
+	for _, ram := range state.RAM {
+		bus.memory[ram[0]] = uint8(ram[1])
+	}
+	return c, bus
+}
+
+// parseCycle converts a JSON bus cycle to a busAccess
+func parseCycle(cycle [3]interface{}) busAccess {
+	kind, _ := cycle[2].(string)
+	access := busAccess{kind: '-'}
+	switch {
+	case len(kind) == 3 && kind[0] == 'r':
+		access.kind = 'r'
+	case len(kind) == 3 && kind[1] == 'w':
+		access.kind = 'w'
+	default:
+		return access
+	}
+	addr, _ := cycle[0].(float64)
+	value, _ := cycle[1].(float64)
+	access.addr = uint16(addr)
+	access.value = uint8(value)
+	return access
+}
+
+func runSingleStepTest(t *testing.T, test *singleStepTest) {
+	c, bus := newSingleStepCPU(&test.Initial)
+	c.execute()
+
+	final := &test.Final
+	regs := []struct {
+		name      string
+		got, want uint16
+	}{
+		{"PC", c.regs.GetPC(), final.PC},
+		{"SP", c.regs.GetSP(), final.SP},
+		{"A", uint16(c.regs.GetA()), uint16(final.A)},
+		{"F", uint16(c.regs.GetF()), uint16(final.F)},
+		{"B", uint16(c.regs.GetB()), uint16(final.B)},
+		{"C", uint16(c.regs.GetC()), uint16(final.C)},
+		{"D", uint16(c.regs.GetD()), uint16(final.D)},
+		{"E", uint16(c.regs.GetE()), uint16(final.E)},
+		{"H", uint16(c.regs.GetH()), uint16(final.H)},
+		{"L", uint16(c.regs.GetL()), uint16(final.L)},
+	}
+	for _, r := range regs {
+		if r.got != r.want {
+			t.Errorf("%s : %s = 0x%X, want 0x%X", test.Name, r.name, r.got, r.want)
+		}
+	}
+
+	// EI only schedules IME for after the next instruction
+	ime := c.interrupts.MasterEnabled() || c.imeDelay > 0
+	if ime != (final.IME != 0) {
+		t.Errorf("%s : IME = %t, want %t", test.Name, ime, final.IME != 0)
+	}
+
+	for _, ram := range final.RAM {
+		if got := bus.memory[ram[0]]; got != uint8(ram[1]) {
+			t.Errorf("%s : (0x%04X) = 0x%02X, want 0x%02X", test.Name, ram[0], got, ram[1])
+		}
+	}
+
+	if len(bus.accesses) != len(test.Cycles) {
+		t.Errorf("%s : %d MACHINE CYCLES, want %d", test.Name, len(bus.accesses), len(test.Cycles))
+		return
+	}
+	for i, cycle := range test.Cycles {
+		want := parseCycle(cycle)
+		if got := bus.accesses[i]; got != want {
+			t.Errorf("%s : CYCLE %d : %s, want %s", test.Name, i, got, want)
+		}
+	}
+}
+
+func TestSingleStep(t *testing.T) {
+	dir := singleStepDir(t)
+
+	run := func(name string) {
+		t.Run(name, func(t *testing.T) {
+			for _, test := range loadSingleStepTests(t, filepath.Join(dir, name+".json")) {
+				test := test
+				runSingleStepTest(t, &test)
+				if t.Failed() {
+					return // First failing vector only
+				}
+			}
+		})
+	}
+	for code, instruction := range instructionList {
+		// 0xCB is a prefix, 0x10 (STOP) and 0x76 (HALT) depend on the system state
+		if instruction == nil || code == 0xCB || code == 0x10 || code == 0x76 {
+			continue
+		}
+		run(fmt.Sprintf("%02x", code))
+	}
+	for code, instruction := range instructionCBList {
+		if instruction == nil {
+			continue
+		}
+		run(fmt.Sprintf("cb %02x", code))
+	}
+}
+
+// TestSingleStepSubset runs the vectors of testdata/sm83subset, available without the full set
+func TestSingleStepSubset(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "sm83subset", "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("NO TEST FILE IN testdata/sm83subset : %v", err)
+	}
+	for _, file := range files {
+		file := file
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".json"), func(t *testing.T) {
+			for _, test := range loadSingleStepTests(t, file) {
+				test := test
+				runSingleStepTest(t, &test)
+				if t.Failed() {
+					return // First failing vector only
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,601 @@
+//go:build ignore
+// +build ignore
+
+// gen writes the SM83 single step vectors of this directory (one file per group of opcodes),
+// in the format of the SingleStepTests sm83 vectors :
+//
+//	go run gen.go
+//
+// The expected states and bus cycles come from this reference model of the documented
+// instructions (Pan Docs, gbctr). It is independent of the emulator CPU : only the opcodes
+// of the groups are modelled.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+type state struct {
+	PC  uint16      `json:"pc"`
+	SP  uint16      `json:"sp"`
+	A   uint8       `json:"a"`
+	B   uint8       `json:"b"`
+	C   uint8       `json:"c"`
+	D   uint8       `json:"d"`
+	E   uint8       `json:"e"`
+	F   uint8       `json:"f"`
+	H   uint8       `json:"h"`
+	L   uint8       `json:"l"`
+	IME uint8       `json:"ime"`
+	IE  uint8       `json:"ie"`
+	RAM [][2]uint16 `json:"ram"`
+}
+
+type vector struct {
+	Name    string           `json:"name"`
+	Initial state            `json:"initial"`
+	Final   state            `json:"final"`
+	Cycles  [][3]interface{} `json:"cycles"`
+}
+
+var groups = []struct {
+	name  string
+	codes []int // 0xCBxx : prefixed opcodes
+}{
+	{"ld_r8", []int{0x41, 0x57, 0x6B, 0x7E, 0x70, 0x06, 0x36}},
+	{"ld_r16", []int{0x01, 0x31, 0x08, 0xF9}},
+	{"ld_mem", []int{0x02, 0x1A, 0x22, 0x2A, 0x32, 0x3A, 0xE0, 0xF0, 0xE2, 0xF2, 0xEA, 0xFA}},
+	{"alu", []int{0x80, 0x8E, 0x90, 0x9E, 0xA3, 0xAF, 0xB6, 0xB8, 0xC6, 0xCE, 0xD6, 0xDE, 0xE6, 0xEE, 0xF6, 0xFE}},
+	{"inc_dec", []int{0x04, 0x0D, 0x34, 0x35, 0x03, 0x1B, 0x33, 0x3B}},
+	{"add16", []int{0x09, 0x29, 0x39, 0xE8, 0xF8}},
+	{"stack", []int{0xC5, 0xF5, 0xC1, 0xF1}},
+	{"jump", []int{0xC3, 0xC2, 0xDA, 0xE9, 0x18, 0x20, 0x38}},
+	{"call", []int{0xCD, 0xC4, 0xDC, 0xC9, 0xC0, 0xD8, 0xD9, 0xC7, 0xFF}},
+	{"misc", []int{0x00, 0x07, 0x0F, 0x17, 0x1F, 0x27, 0x2F, 0x37, 0x3F, 0xF3, 0xFB}},
+	{"cb", []int{0xCB00, 0xCB06, 0xCB11, 0xCB1E, 0xCB27, 0xCB2E, 0xCB37, 0xCB3E,
+		0xCB46, 0xCB7F, 0xCB86, 0xCBBF, 0xCBC0, 0xCBFE}},
+}
+
+const casesPerOpcode = 4
+
+// Flags
+const (
+	flagZ uint8 = 0x80
+	flagN uint8 = 0x40
+	flagH uint8 = 0x20
+	flagC uint8 = 0x10
+)
+
+// cpu is the reference model. The memory is random : an address gets a value on its first access.
+type cpu struct {
+	pc, sp                 uint16
+	a, f, b, c, d, e, h, l uint8
+	ime                    uint8
+	mem                    map[uint16]uint8
+	initial                map[uint16]uint8
+	cycles                 [][3]interface{}
+	rnd                    *rand.Rand
+}
+
+func (c *cpu) peek(addr uint16) uint8 {
+	if _, ok := c.mem[addr]; !ok {
+		v := uint8(c.rnd.Intn(0x100))
+		c.mem[addr] = v
+		c.initial[addr] = v
+	}
+	return c.mem[addr]
+}
+
+func (c *cpu) read(addr uint16) uint8 {
+	v := c.peek(addr)
+	c.cycles = append(c.cycles, [3]interface{}{addr, v, "r-m"})
+	return v
+}
+
+func (c *cpu) write(addr uint16, v uint8) {
+	c.peek(addr)
+	c.mem[addr] = v
+	c.cycles = append(c.cycles, [3]interface{}{addr, v, "-wm"})
+}
+
+func (c *cpu) internal() {
+	c.cycles = append(c.cycles, [3]interface{}{nil, nil, "---"})
+}
+
+func (c *cpu) imm() uint8 {
+	v := c.read(c.pc)
+	c.pc++
+	return v
+}
+
+func (c *cpu) imm16() uint16 {
+	lo := c.imm()
+	return uint16(c.imm())<<8 | uint16(lo)
+}
+
+func (c *cpu) hl() uint16 { return uint16(c.h)<<8 | uint16(c.l) }
+func (c *cpu) setHL(v uint16) {
+	c.h, c.l = uint8(v>>8), uint8(v)
+}
+
+// r16 : BC, DE, HL, SP
+func (c *cpu) r16(p uint8) uint16 {
+	switch p {
+	case 0:
+		return uint16(c.b)<<8 | uint16(c.c)
+	case 1:
+		return uint16(c.d)<<8 | uint16(c.e)
+	case 2:
+		return c.hl()
+	}
+	return c.sp
+}
+
+func (c *cpu) setR16(p uint8, v uint16) {
+	switch p {
+	case 0:
+		c.b, c.c = uint8(v>>8), uint8(v)
+	case 1:
+		c.d, c.e = uint8(v>>8), uint8(v)
+	case 2:
+		c.setHL(v)
+	default:
+		c.sp = v
+	}
+}
+
+// r8 : B, C, D, E, H, L, [HL], A
+func (c *cpu) r8(i uint8) uint8 {
+	switch i {
+	case 0:
+		return c.b
+	case 1:
+		return c.c
+	case 2:
+		return c.d
+	case 3:
+		return c.e
+	case 4:
+		return c.h
+	case 5:
+		return c.l
+	case 6:
+		return c.read(c.hl())
+	}
+	return c.a
+}
+
+func (c *cpu) setR8(i uint8, v uint8) {
+	switch i {
+	case 0:
+		c.b = v
+	case 1:
+		c.c = v
+	case 2:
+		c.d = v
+	case 3:
+		c.e = v
+	case 4:
+		c.h = v
+	case 5:
+		c.l = v
+	case 6:
+		c.write(c.hl(), v)
+	default:
+		c.a = v
+	}
+}
+
+func flags(z, n, h, cy bool) uint8 {
+	var f uint8
+	if z {
+		f |= flagZ
+	}
+	if n {
+		f |= flagN
+	}
+	if h {
+		f |= flagH
+	}
+	if cy {
+		f |= flagC
+	}
+	return f
+}
+
+func (c *cpu) carry() uint8 { return c.f >> 4 & 1 }
+
+// cond : NZ, Z, NC, C
+func (c *cpu) cond(y uint8) bool {
+	switch y & 3 {
+	case 0:
+		return c.f&flagZ == 0
+	case 1:
+		return c.f&flagZ != 0
+	case 2:
+		return c.f&flagC == 0
+	}
+	return c.f&flagC != 0
+}
+
+// alu : ADD, ADC, SUB, SBC, AND, XOR, OR, CP
+func (c *cpu) alu(op uint8, v uint8) {
+	a := c.a
+	switch op {
+	case 0, 1:
+		cy := uint8(0)
+		if op == 1 {
+			cy = c.carry()
+		}
+		r := uint16(a) + uint16(v) + uint16(cy)
+		c.a = uint8(r)
+		c.f = flags(c.a == 0, false, a&0xF+v&0xF+cy > 0xF, r > 0xFF)
+	case 2, 3, 7:
+		cy := uint8(0)
+		if op == 3 {
+			cy = c.carry()
+		}
+		r := uint8(int(a) - int(v) - int(cy))
+		c.f = flags(r == 0, true, int(a&0xF)-int(v&0xF)-int(cy) < 0, int(a)-int(v)-int(cy) < 0)
+		if op != 7 {
+			c.a = r
+		}
+	case 4:
+		c.a = a & v
+		c.f = flags(c.a == 0, false, true, false)
+	case 5:
+		c.a = a ^ v
+		c.f = flags(c.a == 0, false, false, false)
+	case 6:
+		c.a = a | v
+		c.f = flags(c.a == 0, false, false, false)
+	}
+}
+
+// rot : RLC, RRC, RL, RR, SLA, SRA, SWAP, SRL. It returns the result and the carry.
+func (c *cpu) rot(op uint8, v uint8) (uint8, bool) {
+	switch op {
+	case 0:
+		return v<<1 | v>>7, v&0x80 != 0
+	case 1:
+		return v>>1 | v<<7, v&1 != 0
+	case 2:
+		return v<<1 | c.carry(), v&0x80 != 0
+	case 3:
+		return v>>1 | c.carry()<<7, v&1 != 0
+	case 4:
+		return v << 1, v&0x80 != 0
+	case 5:
+		return v>>1 | v&0x80, v&1 != 0
+	case 6:
+		return v<<4 | v>>4, false
+	}
+	return v >> 1, v&1 != 0
+}
+
+func (c *cpu) push(v uint16) {
+	c.sp--
+	c.write(c.sp, uint8(v>>8))
+	c.sp--
+	c.write(c.sp, uint8(v))
+}
+
+func (c *cpu) pop() uint16 {
+	lo := c.read(c.sp)
+	c.sp++
+	hi := c.read(c.sp)
+	c.sp++
+	return uint16(hi)<<8 | uint16(lo)
+}
+
+// addSPE returns SP + e, with the flags of ADD SP, e8 and LD HL, SP+e8
+func (c *cpu) addSPE(e uint8) uint16 {
+	c.f = flags(false, false, c.sp&0xF+uint16(e&0xF) > 0xF, c.sp&0xFF+uint16(e) > 0xFF)
+	return c.sp + uint16(int8(e))
+}
+
+func (c *cpu) execute() {
+	code := c.imm()
+	if code == 0xCB {
+		c.executeCB(c.imm())
+		return
+	}
+	y, z := code>>3&7, code&7
+	p := y >> 1
+	switch {
+	case code == 0x00:
+	case code == 0x08:
+		addr := c.imm16()
+		c.write(addr, uint8(c.sp))
+		c.write(addr+1, uint8(c.sp>>8))
+	case code == 0x18:
+		e := c.imm()
+		c.internal()
+		c.pc += uint16(int8(e))
+	case code < 0x40 && z == 0: // JR cc
+		e := c.imm()
+		if c.cond(y) {
+			c.internal()
+			c.pc += uint16(int8(e))
+		}
+	case code < 0x40 && z == 1 && y&1 == 0:
+		c.setR16(p, c.imm16())
+	case code < 0x40 && z == 1: // ADD HL, r16
+		hl, v := c.hl(), c.r16(p)
+		c.internal()
+		c.setHL(hl + v)
+		c.f = c.f&flagZ | flags(false, false, hl&0xFFF+v&0xFFF > 0xFFF, uint32(hl)+uint32(v) > 0xFFFF)
+	case code < 0x40 && z == 2: // LD [r16], A / LD A, [r16]
+		addr := c.r16(p)
+		switch p {
+		case 2:
+			c.setHL(addr + 1)
+		case 3:
+			addr = c.hl()
+			c.setHL(addr - 1)
+		}
+		if y&1 == 0 {
+			c.write(addr, c.a)
+		} else {
+			c.a = c.read(addr)
+		}
+	case code < 0x40 && z == 3: // INC / DEC r16
+		c.internal()
+		if y&1 == 0 {
+			c.setR16(p, c.r16(p)+1)
+		} else {
+			c.setR16(p, c.r16(p)-1)
+		}
+	case code < 0x40 && z == 4:
+		v := c.r8(y)
+		c.setR8(y, v+1)
+		c.f = c.f&flagC | flags(v+1 == 0, false, v&0xF == 0xF, false)
+	case code < 0x40 && z == 5:
+		v := c.r8(y)
+		c.setR8(y, v-1)
+		c.f = c.f&flagC | flags(v-1 == 0, true, v&0xF == 0, false)
+	case code < 0x40 && z == 6:
+		c.setR8(y, c.imm())
+	case code < 0x40 && y < 4: // RLCA, RRCA, RLA, RRA
+		r, cy := c.rot(y, c.a)
+		c.a = r
+		c.f = flags(false, false, false, cy)
+	case code == 0x27: // DAA
+		a, cy := c.a, c.f&flagC != 0
+		if c.f&flagN == 0 {
+			if cy || a > 0x99 {
+				a += 0x60
+				cy = true
+			}
+			if c.f&flagH != 0 || a&0xF > 9 {
+				a += 0x06
+			}
+		} else {
+			if cy {
+				a -= 0x60
+			}
+			if c.f&flagH != 0 {
+				a -= 0x06
+			}
+		}
+		c.a = a
+		c.f = c.f&flagN | flags(a == 0, false, false, cy)
+	case code == 0x2F:
+		c.a = ^c.a
+		c.f |= flagN | flagH
+	case code == 0x37:
+		c.f = c.f&flagZ | flagC
+	case code == 0x3F:
+		c.f = c.f&flagZ | (c.f^flagC)&flagC
+	case code < 0x80: // LD r8, r8
+		c.setR8(y, c.r8(z))
+	case code < 0xC0:
+		c.alu(y, c.r8(z))
+	case code == 0xE0:
+		n := c.imm()
+		c.write(0xFF00+uint16(n), c.a)
+	case code == 0xF0:
+		n := c.imm()
+		c.a = c.read(0xFF00 + uint16(n))
+	case code == 0xE8:
+		e := c.imm()
+		c.internal()
+		c.internal()
+		c.sp = c.addSPE(e)
+	case code == 0xF8:
+		e := c.imm()
+		c.internal()
+		c.setHL(c.addSPE(e))
+	case z == 0 && y < 4: // RET cc
+		c.internal()
+		if c.cond(y) {
+			c.pc = c.pop()
+			c.internal()
+		}
+	case z == 1 && y&1 == 0: // POP
+		v := c.pop()
+		if p == 3 {
+			c.a, c.f = uint8(v>>8), uint8(v)&0xF0
+		} else {
+			c.setR16(p, v)
+		}
+	case code == 0xC9, code == 0xD9:
+		c.pc = c.pop()
+		c.internal()
+		if code == 0xD9 {
+			c.ime = 1
+		}
+	case code == 0xE9:
+		c.pc = c.hl()
+	case code == 0xF9:
+		c.internal()
+		c.sp = c.hl()
+	case z == 2 && y < 4: // JP cc
+		addr := c.imm16()
+		if c.cond(y) {
+			c.internal()
+			c.pc = addr
+		}
+	case code == 0xE2:
+		c.write(0xFF00+uint16(c.c), c.a)
+	case code == 0xF2:
+		c.a = c.read(0xFF00 + uint16(c.c))
+	case code == 0xEA:
+		c.write(c.imm16(), c.a)
+	case code == 0xFA:
+		c.a = c.read(c.imm16())
+	case code == 0xC3:
+		addr := c.imm16()
+		c.internal()
+		c.pc = addr
+	case code == 0xF3:
+		c.ime = 0
+	case code == 0xFB:
+		c.ime = 1 // After the next instruction
+	case z == 4 && y < 4, code == 0xCD: // CALL cc, CALL
+		addr := c.imm16()
+		if code == 0xCD || c.cond(y) {
+			c.internal()
+			c.push(c.pc)
+			c.pc = addr
+		}
+	case z == 5 && y&1 == 0: // PUSH
+		v := c.r16(p)
+		if p == 3 {
+			v = uint16(c.a)<<8 | uint16(c.f)
+		}
+		c.internal()
+		c.push(v)
+	case z == 6:
+		c.alu(y, c.imm())
+	case z == 7: // RST
+		c.internal()
+		c.push(c.pc)
+		c.pc = uint16(y) * 8
+	default:
+		log.Fatalf("OPCODE NOT MODELLED : 0x%02X", code)
+	}
+}
+
+func (c *cpu) executeCB(code uint8) {
+	x, y, z := code>>6, code>>3&7, code&7
+	v := c.r8(z)
+	switch x {
+	case 0:
+		r, cy := c.rot(y, v)
+		c.setR8(z, r)
+		c.f = flags(r == 0, false, false, cy)
+	case 1: // BIT
+		c.f = c.f&flagC | flags(v&(1<<y) == 0, false, true, false)
+	case 2:
+		c.setR8(z, v&^(1<<y))
+	default:
+		c.setR8(z, v|1<<y)
+	}
+}
+
+// newCase returns a random initial state, the instruction at PC. For the conditional
+// instructions, even cases take the branch.
+func newCase(rnd *rand.Rand, code int, i int) *cpu {
+	c := &cpu{
+		pc:      uint16(rnd.Intn(0x10000)),
+		sp:      uint16(rnd.Intn(0x10000)),
+		a:       uint8(rnd.Intn(0x100)),
+		f:       uint8(rnd.Intn(0x10)) << 4,
+		b:       uint8(rnd.Intn(0x100)),
+		c:       uint8(rnd.Intn(0x100)),
+		d:       uint8(rnd.Intn(0x100)),
+		e:       uint8(rnd.Intn(0x100)),
+		h:       uint8(rnd.Intn(0x100)),
+		l:       uint8(rnd.Intn(0x100)),
+		ime:     uint8(rnd.Intn(2)),
+		mem:     map[uint16]uint8{},
+		initial: map[uint16]uint8{},
+		rnd:     rnd,
+	}
+	if code > 0xFF {
+		c.mem[c.pc], c.mem[c.pc+1] = 0xCB, uint8(code)
+	} else {
+		c.mem[c.pc] = uint8(code)
+	}
+	for addr, v := range c.mem {
+		c.initial[addr] = v
+	}
+	if code <= 0xFF && isConditional(uint8(code)) {
+		y := uint8(code) >> 3 & 3
+		c.f &^= flagZ | flagC
+		if (y&1 == 1) == (i%2 == 0) { // Z or C set : taken on even cases
+			if y < 2 {
+				c.f |= flagZ
+			} else {
+				c.f |= flagC
+			}
+		}
+	}
+	return c
+}
+
+func isConditional(code uint8) bool {
+	switch code {
+	case 0x20, 0x28, 0x30, 0x38, // JR cc
+		0xC0, 0xC8, 0xD0, 0xD8, // RET cc
+		0xC2, 0xCA, 0xD2, 0xDA, // JP cc
+		0xC4, 0xCC, 0xD4, 0xDC: // CALL cc
+		return true
+	}
+	return false
+}
+
+func (c *cpu) state() state {
+	return state{PC: c.pc, SP: c.sp, A: c.a, F: c.f, B: c.b, C: c.c, D: c.d, E: c.e, H: c.h, L: c.l, IME: c.ime}
+}
+
+func ram(mem map[uint16]uint8, addrs []uint16) [][2]uint16 {
+	out := make([][2]uint16, 0, len(addrs))
+	for _, addr := range addrs {
+		out = append(out, [2]uint16{addr, uint16(mem[addr])})
+	}
+	return out
+}
+
+func main() {
+	rnd := rand.New(rand.NewSource(0x5383))
+	for _, group := range groups {
+		var lines []string
+		for _, code := range group.codes {
+			for i := 0; i < casesPerOpcode; i++ {
+				c := newCase(rnd, code, i)
+				initial := c.state()
+				c.execute()
+				addrs := make([]uint16, 0, len(c.initial))
+				for addr := range c.initial {
+					addrs = append(addrs, addr)
+				}
+				sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+				initial.RAM = ram(c.initial, addrs)
+				final := c.state()
+				final.RAM = ram(c.mem, addrs)
+
+				name := fmt.Sprintf("%02x %04d", code, i)
+				if code > 0xFF {
+					name = fmt.Sprintf("cb %02x %04d", code&0xFF, i)
+				}
+				data, err := json.Marshal(vector{Name: name, Initial: initial, Final: final, Cycles: c.cycles})
+				if err != nil {
+					log.Fatal(err)
+				}
+				lines = append(lines, string(data))
+			}
+		}
+		out := "[\n" + strings.Join(lines, ",\n") + "\n]\n"
+		if err := ioutil.WriteFile(group.name+".json", []byte(out), 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
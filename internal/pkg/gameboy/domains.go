@@ -0,0 +1,117 @@
+package gameboy
+
+import (
+	"fmt"
+
+	"github.com/jmontupet/gbcore/internal/pkg/ioports"
+	"github.com/jmontupet/gbcore/pkg/coreio"
+)
+
+// memoryDomain is a coreio.MemoryDomain on a slice (data) or on accessors (peek & poke)
+type memoryDomain struct {
+	name string
+	size int
+	data []uint8
+	peek func(offset int) uint8
+	poke func(offset int, value uint8)
+}
+
+func newSliceDomain(name string, data []uint8) *memoryDomain {
+	return &memoryDomain{name: name, size: len(data), data: data}
+}
+
+func (d *memoryDomain) Name() string { return d.name }
+func (d *memoryDomain) Size() int    { return d.size }
+
+func (d *memoryDomain) Peek(offset int) uint8 {
+	switch {
+	case offset < 0 || offset >= d.size:
+		return 0xFF
+	case d.data != nil:
+		return d.data[offset]
+	default:
+		return d.peek(offset)
+	}
+}
+
+func (d *memoryDomain) Poke(offset int, value uint8) {
+	switch {
+	case offset < 0 || offset >= d.size:
+	case d.data != nil:
+		d.data[offset] = value
+	default:
+		d.poke(offset, value)
+	}
+}
+
+func (d *memoryDomain) ReadBlock(offset int, buf []uint8) int {
+	if offset < 0 || offset >= d.size {
+		return 0
+	}
+	if d.data != nil {
+		return copy(buf, d.data[offset:])
+	}
+	n := d.size - offset
+	if n > len(buf) {
+		n = len(buf)
+	}
+	for i := 0; i < n; i++ {
+		buf[i] = d.peek(offset + i)
+	}
+	return n
+}
+
+// MemoryDomains returns the memory areas of the console, all banks included :
+// ROM, VRAM0-1, WRAM0-7, CARTRAM0-N (0x2000 bytes banks), OAM, IO, HRAM, BGPAL & OBJPAL.
+// VRAM1, WRAM2-7 and the palettes (CGB only) are not returned in DMG mode.
+// IO is accessed like Peek and Poke : the registers are current (timers, LCD...) and
+// a poke has the effects of a CPU write (DIV reset, DMA start...).
+func (gb *gameboy) MemoryDomains() []coreio.MemoryDomain {
+	domains := []coreio.MemoryDomain{newSliceDomain("ROM", gb.cart.ROM())}
+	vramBanks := uint8(1)
+	if gb.cgb {
+		vramBanks = 2
+	}
+	for bank := uint8(0); bank < vramBanks; bank++ {
+		bank := bank
+		domains = append(domains, &memoryDomain{
+			name: fmt.Sprintf("VRAM%d", bank),
+			size: 0x2000,
+			peek: func(offset int) uint8 { return gb.gpu.PeekVRAM(bank, uint16(offset)) },
+			poke: func(offset int, value uint8) { gb.gpu.PokeVRAM(bank, uint16(offset), value) },
+		})
+	}
+	for bank := uint(0); gb.WRAMBank(bank) != nil; bank++ {
+		domains = append(domains, newSliceDomain(fmt.Sprintf("WRAM%d", bank), gb.WRAMBank(bank)))
+	}
+	ram := gb.cart.RAM()
+	for bank := 0; bank*0x2000 < len(ram); bank++ {
+		end := (bank + 1) * 0x2000
+		if end > len(ram) {
+			end = len(ram)
+		}
+		domains = append(domains, newSliceDomain(fmt.Sprintf("CARTRAM%d", bank), ram[bank*0x2000:end]))
+	}
+	domains = append(domains,
+		&memoryDomain{
+			name: "OAM",
+			size: 0xA0,
+			peek: func(offset int) uint8 { return gb.gpu.PeekOAM(uint16(offset)) },
+			poke: func(offset int, value uint8) { gb.gpu.PokeOAM(uint16(offset), value) },
+		},
+		&memoryDomain{
+			name: "IO",
+			size: 0x80,
+			peek: func(offset int) uint8 { return gb.mmu.Peek(ioports.AddrStart + uint16(offset)) },
+			poke: func(offset int, value uint8) { gb.mmu.Poke(ioports.AddrStart+uint16(offset), value) },
+		},
+		newSliceDomain("HRAM", gb.hram.Data()),
+	)
+	if gb.cgb {
+		domains = append(domains,
+			newSliceDomain("BGPAL", gb.gpu.PaletteRAM(false)),
+			newSliceDomain("OBJPAL", gb.gpu.PaletteRAM(true)),
+		)
+	}
+	return domains
+}
@@ -0,0 +1,146 @@
+package gameboy
+
+import (
+	"testing"
+
+	"github.com/jmontupet/gbcore/internal/pkg/cartridge"
+	"github.com/jmontupet/gbcore/pkg/coreio"
+	"github.com/jmontupet/gbcore/pkg/nullio"
+)
+
+// newDomainsGameBoy returns a gameboy with a MBC1 cartridge and 32KB of RAM
+func newDomainsGameBoy(t *testing.T, cgb bool) *gameboy {
+	rom := make([]byte, 0x10000)
+	if cgb {
+		rom[0x0143] = 0x80
+	}
+	rom[0x0147] = 0x03 // MBC1 + RAM + BATTERY
+	rom[0x0148] = 0x01 // 4 banks
+	rom[0x0149] = 0x03 // 32KB
+	rom[0x4000] = 0xAB
+	cart, err := cartridge.NewCartridge(rom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewGameBoy(cart,
+		nullio.NewNullFrameDrawer(), nullio.NewNullInputsManager(), nullio.NewNullAudioPlayer(),
+	).(*gameboy)
+}
+
+func domainsByName(gb *gameboy) map[string]coreio.MemoryDomain {
+	domains := map[string]coreio.MemoryDomain{}
+	for _, d := range gb.MemoryDomains() {
+		domains[d.Name()] = d
+	}
+	return domains
+}
+
+func TestMemoryDomains(t *testing.T) {
+	gb := newDomainsGameBoy(t, true)
+	domains := domainsByName(gb)
+	for name, size := range map[string]int{
+		"ROM": 0x10000, "VRAM0": 0x2000, "VRAM1": 0x2000, "WRAM0": 0x1000, "WRAM7": 0x1000,
+		"CARTRAM0": 0x2000, "CARTRAM3": 0x2000, "OAM": 0xA0, "IO": 0x80, "HRAM": 0x7F, "BGPAL": 64, "OBJPAL": 64,
+	} {
+		if d := domains[name]; d == nil || d.Size() != size {
+			t.Errorf("%s : expected size 0x%X, got %v", name, size, d)
+		}
+	}
+	if len(domains) != 1+2+8+4+5 {
+		t.Errorf("unexpected domains : %d", len(domains))
+	}
+
+	// VRAM banks, independently of VBK
+	gb.Poke(0xFF4F, 1)
+	gb.Poke(0x8000, 0x12)
+	gb.Poke(0x9800, 0x2F) // Tile attributes
+	gb.Poke(0xFF4F, 0)
+	gb.Poke(0x8000, 0x34)
+	if got := domains["VRAM1"].Peek(0x0000); got != 0x12 {
+		t.Errorf("VRAM1 : expected 0x12, got 0x%02X", got)
+	}
+	if got := domains["VRAM1"].Peek(0x1800); got != 0x2F {
+		t.Errorf("VRAM1 attributes : expected 0x2F, got 0x%02X", got)
+	}
+	if got := domains["VRAM0"].Peek(0x0000); got != 0x34 {
+		t.Errorf("VRAM0 : expected 0x34, got 0x%02X", got)
+	}
+	domains["VRAM1"].Poke(0x0001, 0x56)
+	if got := gb.Peek(0x8001); got != 0x00 {
+		t.Errorf("VRAM1 poke visible in bank 0 : 0x%02X", got)
+	}
+
+	// WRAM banks, independently of SVBK
+	domains["WRAM3"].Poke(0x0010, 0x78)
+	if got := gb.Peek(0xD010); got != 0x00 {
+		t.Errorf("WRAM3 poke visible in bank 1 : 0x%02X", got)
+	}
+	gb.Poke(0xFF70, 3)
+	if got := gb.Peek(0xD010); got != 0x78 {
+		t.Errorf("WRAM3 : expected 0x78, got 0x%02X", got)
+	}
+
+	// OAM, palettes, ROM
+	domains["OAM"].Poke(0x04, 0x77)
+	if got := gb.Peek(0xFE04); got != 0x77 {
+		t.Errorf("OAM : expected 0x77, got 0x%02X", got)
+	}
+	gb.Poke(0xFF68, 0x81)
+	gb.Poke(0xFF69, 0x11)
+	if got := domains["BGPAL"].Peek(1); got != 0x11 {
+		t.Errorf("BGPAL : expected 0x11, got 0x%02X", got)
+	}
+	if got := domains["ROM"].Peek(0x4000); got != 0xAB {
+		t.Errorf("ROM : expected 0xAB, got 0x%02X", got)
+	}
+
+	// Out of range & bulk read
+	if got := domains["HRAM"].Peek(0x7F); got != 0xFF {
+		t.Errorf("out of range : expected 0xFF, got 0x%02X", got)
+	}
+	buf := make([]uint8, 4)
+	if n := domains["VRAM1"].ReadBlock(0, buf); n != 4 || buf[0] != 0x12 || buf[1] != 0x56 {
+		t.Errorf("VRAM1 ReadBlock : %d %v", n, buf)
+	}
+	if n := domains["OAM"].ReadBlock(0x9E, buf); n != 2 {
+		t.Errorf("OAM ReadBlock at the end : %d", n)
+	}
+}
+
+// TestMemoryDomainIO checks the IO domain sees the registers of the lazy components and reschedules them
+func TestMemoryDomainIO(t *testing.T) {
+	gb := newProgramGameBoy(t, 0xF3, 0x76, 0x3C, 0x18, 0xFE) // DI, HALT, INC A : the idle time is skipped
+	io := domainsByName(gb)["IO"]
+	for gb.Cycles() < 1000 {
+		gb.Step()
+	}
+	for _, addr := range []uint16{0xFF04, 0xFF41, 0xFF44} { // DIV, STAT, LY
+		if got, expected := io.Peek(int(addr-0xFF00)), gb.mmu.Read(addr); got != expected {
+			t.Errorf("0x%04X : expected 0x%02X, got 0x%02X", addr, expected, got)
+		}
+	}
+
+	// The halted CPU wakes up on the timer interrupt : TAC poked, the timer event is scheduled
+	gb.Poke(0xFFFF, 0x04) // IE : timer
+	io.Poke(0x05, 0x00)   // TIMA
+	io.Poke(0x07, 0x05)   // TAC : timer started, 4 machine cycles per increment
+	start := gb.Cycles()
+	for gb.Registers().PC == 0x0152 && gb.Cycles()-start < 17556 {
+		gb.Step()
+	}
+	if woken := gb.Cycles() - start; woken > 0x100*4+8 {
+		t.Errorf("timer not rescheduled : woken up after %d machine cycles", woken)
+	}
+}
+
+func TestMemoryDomainsDMG(t *testing.T) {
+	domains := domainsByName(newDomainsGameBoy(t, false))
+	for _, name := range []string{"VRAM1", "WRAM2", "BGPAL", "OBJPAL"} {
+		if domains[name] != nil {
+			t.Errorf("%s available in DMG mode", name)
+		}
+	}
+	if domains["WRAM1"] == nil {
+		t.Errorf("WRAM1 not available")
+	}
+}
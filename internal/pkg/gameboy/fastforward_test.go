@@ -0,0 +1,131 @@
+package gameboy
+
+import (
+	"hash/crc32"
+	"testing"
+
+	"github.com/jmontupet/gbcore/internal/pkg/cartridge"
+	"github.com/jmontupet/gbcore/pkg/coreio"
+	"github.com/jmontupet/gbcore/pkg/nullio"
+)
+
+// hashFrameDrawer records the hash of each frame
+type hashFrameDrawer struct {
+	hashes []uint32
+}
+
+func (d *hashFrameDrawer) SwapFrameBuffer(
+	frameBuffer *coreio.FrameBuffer, colors *coreio.FrameColors,
+) (*coreio.FrameBuffer, *coreio.FrameColors) {
+	d.hashes = append(d.hashes, crc32.ChecksumIEEE(frameBuffer[:])^crc32.ChecksumIEEE(colors[:]))
+	return frameBuffer, colors
+}
+
+// idleROM waits in the common ways, changing the display between the waits :
+//
+//	0040  JP $0200          ; VBlank : sets the flag, scrolls
+//	0050  RETI              ; Timer
+//	0150  LD A, $05
+//	0152  LDH [$07], A      ; Timer 262144Hz
+//	0154  LD A, $05
+//	0156  LDH [$FF], A      ; IE : VBlank + Timer
+//	0158  EI
+//	0159  LDH A, [$44]      ; Wait LY = 72
+//	015B  CP $48
+//	015D  JR NZ, $0159
+//	015F  LD HL, $8000
+//	0162  INC [HL]          ; Tile 0 data
+//	0163  XOR A
+//	0164  LD [$C100], A
+//	0167  LD A, [$C100]     ; Wait the VBlank flag
+//	016A  AND A
+//	016B  JR Z, $0167
+//	016D  LDH A, [$04]      ; Wait DIV & 0x0F = 0
+//	016F  AND $0F
+//	0171  JR NZ, $016D
+//	0173  LD HL, $9800
+//	0176  INC [HL]          ; Tile map
+//	0177  HALT
+//	0178  NOP
+//	0179  JR $0159
+//	0200  PUSH AF
+//	0201  LD A, $01
+//	0203  LD [$C100], A
+//	0206  LDH A, [$43]
+//	0208  INC A
+//	0209  LDH [$43], A      ; SCX
+//	020B  POP AF
+//	020C  RETI
+func idleROM() []byte {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x0040:], []byte{0xC3, 0x00, 0x02})
+	copy(rom[0x0050:], []byte{0xD9})
+	copy(rom[0x0100:], []byte{0xC3, 0x50, 0x01})
+	copy(rom[0x0150:], []byte{
+		0x3E, 0x05, 0xE0, 0x07, 0x3E, 0x05, 0xE0, 0xFF, 0xFB,
+		0xF0, 0x44, 0xFE, 0x48, 0x20, 0xFA,
+		0x21, 0x00, 0x80, 0x34,
+		0xAF, 0xEA, 0x00, 0xC1,
+		0xFA, 0x00, 0xC1, 0xA7, 0x28, 0xFA,
+		0xF0, 0x04, 0xE6, 0x0F, 0x20, 0xFA,
+		0x21, 0x00, 0x98, 0x34,
+		0x76, 0x00,
+		0x18, 0xDE,
+	})
+	copy(rom[0x0200:], []byte{0xF5, 0x3E, 0x01, 0xEA, 0x00, 0xC1, 0xF0, 0x43, 0x3C, 0xE0, 0x43, 0xF1, 0xD9})
+	return rom
+}
+
+// runFrames runs the ROM for a number of frames and returns the steps used
+func runFrames(t *testing.T, gb *gameboy, frames int) (steps int) {
+	count := 0
+	gb.AddFrameHook(func() { count++ })
+	for count < frames {
+		gb.Step()
+		steps++
+	}
+	return steps
+}
+
+// TestFastForwardDeterminism checks the idle time skipping doesn't change the emulation :
+// same frames, and same state at the same time
+func TestFastForwardDeterminism(t *testing.T) {
+	const frames = 60
+	type result struct {
+		drawer *hashFrameDrawer
+		steps  int
+		cycles uint64
+		regs   coreio.Registers
+	}
+	run := func(fastForward bool) result {
+		cart, err := cartridge.NewCartridge(idleROM())
+		if err != nil {
+			t.Fatal(err)
+		}
+		drawer := &hashFrameDrawer{}
+		gb := NewGameBoy(cart, drawer, nullio.NewNullInputsManager(), nullio.NewNullAudioPlayer()).(*gameboy)
+		gb.SetFastForward(fastForward)
+		steps := runFrames(t, gb, frames)
+		return result{drawer, steps, gb.Cycles(), gb.Registers()}
+	}
+	on, off := run(true), run(false)
+
+	if len(on.drawer.hashes) < frames-1 || len(on.drawer.hashes) != len(off.drawer.hashes) {
+		t.Fatalf("frames drawn : %d %d", len(on.drawer.hashes), len(off.drawer.hashes))
+	}
+	for i := range on.drawer.hashes {
+		if on.drawer.hashes[i] != off.drawer.hashes[i] {
+			t.Fatalf("frame %d differs : %08X %08X", i, on.drawer.hashes[i], off.drawer.hashes[i])
+		}
+	}
+	if on.drawer.hashes[0] == on.drawer.hashes[len(on.drawer.hashes)-1] {
+		t.Errorf("display not updated")
+	}
+	if on.cycles != off.cycles || on.regs != off.regs {
+		t.Errorf("state differs : %d %+v, %d %+v", on.cycles, on.regs, off.cycles, off.regs)
+	}
+	if on.steps*4 > off.steps {
+		t.Errorf("idle time not skipped : %d steps, %d without fast-forward", on.steps, off.steps)
+	}
+	t.Logf("steps : %d, %d without fast-forward", on.steps, off.steps)
+}
@@ -7,9 +7,11 @@ import (
 	"github.com/jmontupet/gbcore/internal/pkg/constants"
 
 	"github.com/jmontupet/gbcore/pkg/coreio"
+	"github.com/jmontupet/gbcore/pkg/nullio"
 
 	"github.com/jmontupet/gbcore/internal/pkg/audio"
 	"github.com/jmontupet/gbcore/internal/pkg/joypad"
+	"github.com/jmontupet/gbcore/internal/pkg/serial"
 	"github.com/jmontupet/gbcore/internal/pkg/unusableaddr"
 
 	"github.com/jmontupet/gbcore/internal/pkg/interrupt"
@@ -23,10 +25,33 @@ import (
 	"github.com/jmontupet/gbcore/internal/pkg/hram"
 	"github.com/jmontupet/gbcore/internal/pkg/ioports"
 	"github.com/jmontupet/gbcore/internal/pkg/mmu"
+	"github.com/jmontupet/gbcore/internal/pkg/scheduler"
 )
 
 type GameBoy interface {
 	Run()
+	Step() uint8
+	Cycles() uint64
+	SetEventHandler(handler coreio.EventHandler)
+	Peek(addr uint16) uint8
+	Poke(addr uint16, value uint8)
+	Bank(addr uint16) uint
+	Registers() coreio.Registers
+	SetRegisters(regs coreio.Registers)
+	AddExecHook(hook func(regs coreio.Registers))
+	Break(handler func())
+	AddFrameHook(hook func())
+	SetAccessHook(hook func(addr uint16, value uint8, write bool))
+	AddBusReadHook(start, end uint16, hook coreio.ReadHook) int
+	AddBusWriteHook(start, end uint16, hook coreio.WriteHook) int
+	AddBusExecHook(start, end uint16, hook coreio.ExecHook) int
+	RemoveBusHook(id int) bool
+	SetCodeDataLog(log []uint8)
+	WRAMBank(bank uint) []uint8
+	HRAM() []uint8
+	CartridgeRAM() []uint8
+	MemoryDomains() []coreio.MemoryDomain
+	SetFastForward(enabled bool)
 }
 
 type gameboy struct {
@@ -36,8 +61,223 @@ type gameboy struct {
 	mmu    *mmu.MMU
 	joypad *joypad.Joypad
 	timers *timers.Timers
+	serial *serial.Serial
+	cart   cartridge.Cartridge
+	wram   *wram.WRam
+	hram   *hram.HRAM
+	io     *ioports.IOPorts
+	cgb    bool
+
+	scheduler *scheduler.Scheduler // Clocks the components on their events
+
+	line     uint8 // Current LY, updated on each line change
+	stepLine uint8 // LY at the start of the current Step
+	vblank   bool  // LY in the VBlank lines at the end of the last Step
+
+	frameHooks []func() // Called at the start of each VBlank
+	onBreak    func()   // Called at the end of the Step stopped by Break
 
 	inputsManager coreio.InputsManager
+	eventHandler  coreio.EventHandler
+
+	lockReported bool
+}
+
+func (gb *gameboy) Peek(addr uint16) uint8        { return gb.mmu.Peek(addr) }
+func (gb *gameboy) Poke(addr uint16, value uint8) { gb.mmu.Poke(addr, value) }
+func (gb *gameboy) Bank(addr uint16) uint         { return gb.mmu.Bank(addr) }
+
+// WRAMBank returns a WRAM bank without copy (nil if not available : banks 2-7 in DMG mode)
+func (gb *gameboy) WRAMBank(bank uint) []uint8 {
+	if bank > 7 || !gb.cgb && bank > 1 {
+		return nil
+	}
+	return gb.wram.BankData(bank)
+}
+
+func (gb *gameboy) HRAM() []uint8         { return gb.hram.Data() }
+func (gb *gameboy) CartridgeRAM() []uint8 { return gb.cart.RAM() }
+
+func (gb *gameboy) Registers() coreio.Registers {
+	regs := gb.cpu.Regs()
+	return coreio.Registers{
+		A: regs.GetA(), F: regs.GetF(),
+		B: regs.GetB(), C: regs.GetC(),
+		D: regs.GetD(), E: regs.GetE(),
+		H: regs.GetH(), L: regs.GetL(),
+		SP: regs.GetSP(),
+		PC: regs.GetPC(),
+	}
+}
+
+// SetRegisters replaces the CPU registers (the low nibble of F is always 0)
+func (gb *gameboy) SetRegisters(r coreio.Registers) {
+	regs := gb.cpu.Regs()
+	regs.SetA(r.A)
+	regs.SetF(r.F & 0xF0)
+	regs.SetB(r.B)
+	regs.SetC(r.C)
+	regs.SetD(r.D)
+	regs.SetE(r.E)
+	regs.SetH(r.H)
+	regs.SetL(r.L)
+	regs.SetSP(r.SP)
+	regs.SetPC(r.PC)
+}
+
+func (gb *gameboy) SetAccessHook(hook func(addr uint16, value uint8, write bool)) {
+	gb.mmu.SetAccessHook(hook)
+}
+
+func (gb *gameboy) AddBusReadHook(start, end uint16, hook coreio.ReadHook) int {
+	return gb.mmu.AddReadHook(start, end, hook)
+}
+func (gb *gameboy) AddBusWriteHook(start, end uint16, hook coreio.WriteHook) int {
+	return gb.mmu.AddWriteHook(start, end, hook)
+}
+func (gb *gameboy) AddBusExecHook(start, end uint16, hook coreio.ExecHook) int {
+	return gb.mmu.AddExecHook(start, end, hook)
+}
+func (gb *gameboy) RemoveBusHook(id int) bool { return gb.mmu.RemoveHook(id) }
+
+func (gb *gameboy) SetCodeDataLog(log []uint8) {
+	gb.mmu.SetCodeDataLog(log)
+}
+
+func (gb *gameboy) AddExecHook(hook func(regs coreio.Registers)) {
+	gb.cpu.AddExecHook(func() { hook(gb.Registers()) })
+}
+
+// Break stops the execution before the current instruction (from an exec hook).
+// handler is called at the end of the Step, between two instructions.
+func (gb *gameboy) Break(handler func()) {
+	gb.onBreak = handler
+	gb.cpu.Break()
+}
+
+// AddFrameHook registers a function called at the start of each VBlank (after the instruction)
+func (gb *gameboy) AddFrameHook(hook func()) {
+	gb.frameHooks = append(gb.frameHooks, hook)
+}
+
+// checkFrame runs the frame hooks when VBlank starts
+func (gb *gameboy) checkFrame() {
+	vblank := gb.line >= constants.ScreenHeight
+	if vblank && !gb.vblank {
+		for _, hook := range gb.frameHooks {
+			hook()
+		}
+	}
+	gb.vblank = vblank
+}
+
+func (gb *gameboy) SetEventHandler(handler coreio.EventHandler) {
+	gb.eventHandler = handler
+}
+
+// checkEvents reports the CPU state changes to the event handler
+func (gb *gameboy) checkEvents() {
+	if locked, pc, opcode := gb.cpu.Locked(); locked && !gb.lockReported {
+		gb.lockReported = true
+		gb.eventHandler.HandleEvent(coreio.Event{
+			Type:   coreio.EventCPULocked,
+			PC:     pc,
+			Opcode: opcode,
+		})
+	}
+}
+
+// MCycle advances the time by one CPU machine cycle : the components whose event is due are run.
+// Called by the CPU before each memory access and on internal cycles.
+func (gb *gameboy) MCycle() {
+	gb.scheduler.Tick()
+}
+
+// Idle skips up to max machine cycles without CPU activity, until the next event
+func (gb *gameboy) Idle(max uint8) uint8 {
+	return uint8(gb.scheduler.Skip(uint64(max)))
+}
+
+// Sync brings all the components up to date
+func (gb *gameboy) Sync() {
+	gb.scheduler.Sync()
+}
+
+// ResetDiv resets DIV and the timers internal counter (STOP), the components being up to date
+func (gb *gameboy) ResetDiv() {
+	gb.scheduler.Sync()
+	gb.timers.ResetDiv()
+	gb.scheduler.Sync()
+}
+
+// Skippable returns the machine cycles before the next event, when an idle loop can be skipped.
+// Nothing is skipped when the CPU accesses are observed (hooks), or once the line changed during
+// the Step : the frame hooks and the inputs must see the same instruction boundaries.
+func (gb *gameboy) Skippable() uint64 {
+	if gb.line != gb.stepLine || gb.mmu.Observed() {
+		return 0
+	}
+	return gb.scheduler.Next() - gb.scheduler.Now()
+}
+
+// SetFastForward enables the skipping of the idle CPU time : HALT and idle loops (enabled by default)
+func (gb *gameboy) SetFastForward(enabled bool) {
+	gb.cpu.FastForward = enabled
+}
+
+// Cycles returns the machine cycles executed since power on
+func (gb *gameboy) Cycles() uint64 { return gb.scheduler.Now() }
+
+// systemClocks returns the system clocks of a machine cycle : 4, 2 in double speed.
+// The CPU clocked components see 4 CPU clocks per machine cycle in both speeds.
+func (gb *gameboy) systemClocks() int {
+	if gb.cpu.DoubleSpeed {
+		return 2
+	}
+	return 4
+}
+
+// ppu clocks the GPU in dots (system clocks)
+type ppu struct{ gb *gameboy }
+
+func (p ppu) Advance(n uint64) {
+	p.gb.line = p.gb.gpu.Tick(int(n) * p.gb.systemClocks())
+}
+
+func (p ppu) NextEvent() uint64 {
+	dots := p.gb.systemClocks()
+	return uint64((p.gb.gpu.NextEvent() + dots - 1) / dots)
+}
+
+// apu clocks the APU in system clocks : its frame sequencer runs at 512 Hz in both speeds
+type apu struct{ gb *gameboy }
+
+func (a apu) Advance(n uint64) {
+	a.gb.apu.Advance(int(n) * a.gb.systemClocks())
+}
+
+func (a apu) NextEvent() uint64 {
+	clocks := a.gb.apu.NextEvent()
+	if clocks == scheduler.Never {
+		return scheduler.Never
+	}
+	n := uint64(a.gb.systemClocks())
+	return (clocks + n - 1) / n
+}
+
+// Step runs the CPU for one instruction (or interrupt dispatch / idle time), without pacing.
+// It returns the machine cycles used : with fast-forward, the skipped idle time is included.
+func (gb *gameboy) Step() uint8 {
+	gb.stepLine = gb.line
+	cycles := gb.cpu.Tick()
+	gb.checkEvents()
+	gb.checkFrame()
+	if handler := gb.onBreak; handler != nil {
+		// The handler can step the emulation
+		gb.onBreak = nil
+		handler()
+	}
+	return cycles
 }
 
 func (gb *gameboy) Run() {
@@ -49,18 +289,14 @@ func (gb *gameboy) Run() {
 
 	prevLine := uint8(0)
 	for {
-		nbClockUsed := gb.cpu.Tick()
-		var clockMul uint8 = 4
-		if gb.cpu.DoubleSpeed {
-			clockMul = 2
+		gb.Step() // Components are ticked by the CPU (MCycle)
+		if gb.cpu.Stopped() {
+			// Low power mode : only the joypad can restart the CPU
+			gb.joypad.UpdateInput(uint8(gb.inputsManager.CurrentInput()))
+			<-ticker
+			continue
 		}
-
-		line := gb.gpu.Tick(nbClockUsed * 4)
-		gb.timers.Tick(nbClockUsed * clockMul)
-		gb.mmu.GetOamDMA().Tick(nbClockUsed * clockMul)
-		gb.mmu.GetVramDMA().Tick(nbClockUsed * 4)
-
-		// gb.apu.Tick(nbClockUsed)
+		line := gb.line
 
 		if line%frameDiv == 0 && prevLine%frameDiv != 0 { // 0 - 153
 			gb.joypad.UpdateInput(uint8(gb.inputsManager.CurrentInput()))
@@ -80,26 +316,44 @@ func NewGameBoy(
 ) GameBoy {
 	cgb := cartridge.ReadCGBCompatible(cart)
 
-	io := ioports.NewGBIOPorts()
+	io := ioports.NewGBIOPorts(cgb)
 	timers := timers.NewTimers(io)
 	hram := hram.NewGBHRAM()
 	wram := wram.NewWram(io)
 	interrupt := interrupt.NewInterrupt(io)
 	joypad := joypad.NewJoypad(io)
+	serial := serial.NewSerial(io)
 
 	unusableAddr := unusableaddr.NewUnusableAddr()
 	gpu := gpu.NewGBGPU(io, renderer, cgb)
-	mmu := mmu.NewMMU(cart, gpu, io, hram, wram, interrupt, joypad, unusableAddr)
-	proc := cpu.NewCPU(mmu, interrupt)
-	apu := audio.NewAPU(io, audioPlayer)
+	mmu := mmu.NewMMU(cart, gpu, io, hram, wram, interrupt, joypad, serial, timers, unusableAddr)
 
-	return &gameboy{
-		cpu:           proc,
+	gb := &gameboy{
 		gpu:           gpu,
-		apu:           apu,
+		apu:           audio.NewAPU(io, audioPlayer),
 		mmu:           mmu,
 		timers:        timers,
+		serial:        serial,
+		cart:          cart,
+		wram:          wram,
+		hram:          hram,
+		io:            io,
+		cgb:           cgb,
 		joypad:        joypad,
 		inputsManager: inputsManager,
+		eventHandler:  nullio.NewNullEventHandler(),
 	}
+	gb.cpu = cpu.NewCPU(mmu, interrupt, io, gb)
+	gb.cpu.FastForward = true
+
+	// Same order as the hardware clocks : CPU clocked components, then system clocked ones.
+	gb.scheduler = scheduler.New()
+	gb.scheduler.Add(timers)
+	gb.scheduler.Add(mmu.GetOamDMA())
+	gb.scheduler.Add(serial)
+	gb.scheduler.Add(ppu{gb})
+	gb.scheduler.Add(apu{gb})
+	gb.scheduler.Add(mmu.GetVramDMA())
+	mmu.SetSync(gb.scheduler.Sync)
+	return gb
 }
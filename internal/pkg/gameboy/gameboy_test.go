@@ -0,0 +1,67 @@
+package gameboy
+
+import (
+	"testing"
+
+	"github.com/jmontupet/gbcore/internal/pkg/cartridge"
+	"github.com/jmontupet/gbcore/pkg/nullio"
+)
+
+func newProgramGameBoy(t testing.TB, program ...byte) *gameboy {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x0100:], []byte{0xC3, 0x50, 0x01})
+	copy(rom[0x0150:], program)
+	cart, err := cartridge.NewCartridge(rom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewGameBoy(cart,
+		nullio.NewNullFrameDrawer(), nullio.NewNullInputsManager(), nullio.NewNullAudioPlayer(),
+	).(*gameboy)
+}
+
+// TestHaltSkip checks a halted CPU skips to the next event instead of running each machine cycle
+func TestHaltSkip(t *testing.T) {
+	gb := newProgramGameBoy(t, 0xF3, 0x76, 0x18, 0xFE) // DI, HALT
+	gb.Step()
+	gb.Step()
+	const frameCycles = 17556
+	start, steps := gb.Cycles(), 0
+	frames := 0
+	gb.AddFrameHook(func() { frames++ })
+	for gb.Cycles()-start < 2*frameCycles {
+		gb.Step()
+		steps++
+	}
+	if steps > frameCycles/4 {
+		t.Errorf("%d halted steps for 2 frames", steps)
+	}
+	if frames != 2 {
+		t.Errorf("%d frames while halted", frames)
+	}
+}
+
+// BenchmarkStep runs a copy loop mixing ROM, WRAM, IO and VRAM accesses :
+//
+//	0150  LD HL, $4000
+//	0153  LD DE, $C000
+//	0156  LD B, $00
+//	0158  LD A, [HL+]
+//	0159  LD [DE], A
+//	015A  INC DE
+//	015B  LDH A, [$44]
+//	015D  LD [$8000], A
+//	0160  DEC B
+//	0161  JR NZ, $0158
+//	0163  JR $0150
+func BenchmarkStep(b *testing.B) {
+	gb := newProgramGameBoy(b,
+		0x21, 0x00, 0x40, 0x11, 0x00, 0xC0, 0x06, 0x00,
+		0x2A, 0x12, 0x13, 0xF0, 0x44, 0xEA, 0x00, 0x80,
+		0x05, 0x20, 0xF5, 0x18, 0xEB,
+	)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gb.Step()
+	}
+}
@@ -0,0 +1,342 @@
+package gameboy
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jmontupet/gbcore/internal/pkg/cartridge"
+	"github.com/jmontupet/gbcore/pkg/coreio"
+	"github.com/jmontupet/gbcore/pkg/nullio"
+)
+
+// Test ROMs are not distributed with the sources.
+// They are searched in $GBCORE_TESTROMS, or in testdata/ :
+//
+//	blargg/cpu_instrs/cpu_instrs.gb
+//	blargg/instr_timing/instr_timing.gb
+//	blargg/mem_timing/mem_timing.gb
+//	blargg/halt_bug.gb
+//	mooneye/acceptance/**/*.gb
+//	mooneye/misc/bits/unused_hwio-C.gb
+//	acid2/dmg-acid2.gb, acid2/dmg-acid2.png
+//	acid2/cgb-acid2.gbc, acid2/cgb-acid2.png
+const testROMsEnv = "GBCORE_TESTROMS"
+
+// Emulated time limits, in machine cycles (~1MHz)
+const blarggMaxCycles = 120 * 1048576
+const mooneyeMaxCycles = 10 * 1048576
+
+func testROMsDir(t *testing.T, sub string) string {
+	dir := os.Getenv(testROMsEnv)
+	if dir == "" {
+		dir = "testdata"
+	}
+	dir = filepath.Join(dir, sub)
+	if _, err := os.Stat(dir); err != nil {
+		t.Skipf("TEST ROMS NOT FOUND IN %s (SET %s)", dir, testROMsEnv)
+	}
+	return dir
+}
+
+func newTestROMGameBoy(t *testing.T, path string, renderer coreio.FrameDrawer) *gameboy {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cart, err := cartridge.NewCartridge(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewGameBoy(
+		cart,
+		renderer,
+		nullio.NewNullInputsManager(),
+		nullio.NewNullAudioPlayer(),
+	).(*gameboy)
+}
+
+// runTestROM executes the ROM until done returns true, the CPU is locked or maxCycles is reached.
+func runTestROM(gb *gameboy, maxCycles uint, done func() bool) (finished bool) {
+	for cycles := uint(0); cycles < maxCycles; {
+		used := uint(gb.cpu.Tick())
+		if used == 0 { // Stopped
+			used = 1
+		}
+		cycles += used
+		if locked, _, _ := gb.cpu.Locked(); locked {
+			return done()
+		}
+		if done() {
+			return true
+		}
+	}
+	return false
+}
+
+// Blargg's ROMs write their output to the serial port, and newer ones also
+// to the cartridge RAM : A000 = status (0x80 while running), A001-A003 = DE B0 61, A004 = text.
+func blarggMemoryResult(gb *gameboy) (status uint8, text string, ok bool) {
+	if gb.mmu.Peek(0xA001) != 0xDE || gb.mmu.Peek(0xA002) != 0xB0 || gb.mmu.Peek(0xA003) != 0x61 {
+		return 0, "", false
+	}
+	status = gb.mmu.Peek(0xA000)
+	if status == 0x80 {
+		return status, "", false
+	}
+	var buf bytes.Buffer
+	for addr := uint16(0xA004); addr <= 0xBFFF; addr++ {
+		c := gb.mmu.Peek(addr)
+		if c == 0 {
+			break
+		}
+		buf.WriteByte(c)
+	}
+	return status, buf.String(), true
+}
+
+func TestBlargg(t *testing.T) {
+	dir := testROMsDir(t, "blargg")
+
+	roms := []string{
+		"cpu_instrs/cpu_instrs.gb",
+		"instr_timing/instr_timing.gb",
+		"mem_timing/mem_timing.gb",
+		"halt_bug.gb",
+	}
+	for _, rom := range roms {
+		path := filepath.Join(dir, filepath.FromSlash(rom))
+		t.Run(rom, func(t *testing.T) {
+			if _, err := os.Stat(path); err != nil {
+				t.Skipf("ROM NOT FOUND : %s", path)
+			}
+			gb := newTestROMGameBoy(t, path, nullio.NewNullFrameDrawer())
+
+			var serialOut bytes.Buffer
+			gb.serial.OnTransfer = func(value uint8) { serialOut.WriteByte(value) }
+
+			runTestROM(gb, blarggMaxCycles, func() bool {
+				if _, _, ok := blarggMemoryResult(gb); ok {
+					return true
+				}
+				out := serialOut.String()
+				return strings.Contains(out, "Passed") || strings.Contains(out, "Failed")
+			})
+
+			if status, text, ok := blarggMemoryResult(gb); ok {
+				if status != 0 {
+					t.Errorf("FAILED (STATUS 0x%02X) :\n%s", status, text)
+				}
+				return
+			}
+			out := serialOut.String()
+			switch {
+			case strings.Contains(out, "Passed"):
+			case strings.Contains(out, "Failed"):
+				t.Errorf("FAILED :\n%s", out)
+			default:
+				t.Errorf("NO RESULT (TIMEOUT OR CPU LOCKED) :\n%s", out)
+			}
+		})
+	}
+}
+
+// mooneyeModelSupported returns false for ROMs targeting only other hardware models.
+// Model suffixes : "-dmg0", "-GS", "-dmgABCmgb", "-cgb", "-C", ... ("C" = CGB family)
+func mooneyeModelSupported(name string) bool {
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	i := strings.LastIndex(name, "-")
+	if i < 0 {
+		return true
+	}
+	model := name[i+1:]
+	switch {
+	case model == "cgb", model == "cgbABCDE":
+		return true
+	case strings.ToUpper(model) == model:
+		return strings.Contains(model, "C")
+	case strings.HasPrefix(model, "dmg"), strings.HasPrefix(model, "mgb"),
+		strings.HasPrefix(model, "sgb"), strings.HasPrefix(model, "agb"), strings.HasPrefix(model, "ags"):
+		return false
+	}
+	return true
+}
+
+func TestMooneye(t *testing.T) {
+	dir := testROMsDir(t, filepath.Join("mooneye", "acceptance"))
+
+	var roms []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && filepath.Ext(path) == ".gb" {
+			roms = append(roms, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range roms {
+		path := path
+		name, _ := filepath.Rel(dir, path)
+		t.Run(filepath.ToSlash(name), func(t *testing.T) {
+			runMooneyeROM(t, path)
+		})
+	}
+}
+
+// TestMooneyeRegisters runs the CGB register read masks test (unused bits and registers read as 1).
+// The DMG one (acceptance/bits/unused_hwio-GS) is skipped : the emulated hardware is a CGB.
+func TestMooneyeRegisters(t *testing.T) {
+	dir := testROMsDir(t, "mooneye")
+	for _, rom := range []string{"misc/bits/unused_hwio-C.gb"} {
+		path := filepath.Join(dir, filepath.FromSlash(rom))
+		t.Run(rom, func(t *testing.T) {
+			if _, err := os.Stat(path); err != nil {
+				t.Skipf("ROM NOT FOUND : %s", path)
+			}
+			runMooneyeROM(t, path)
+		})
+	}
+}
+
+func runMooneyeROM(t *testing.T, path string) {
+	if !mooneyeModelSupported(filepath.Base(path)) {
+		t.Skip("OTHER HARDWARE MODEL")
+	}
+	gb := newTestROMGameBoy(t, path, nullio.NewNullFrameDrawer())
+
+	// The test ends with LD B,B : registers hold the Fibonacci sequence on success, 0x42 on failure
+	var result *coreio.Registers
+	gb.AddExecHook(func(regs coreio.Registers) {
+		if result == nil && gb.mmu.Peek(regs.PC) == 0x40 {
+			r := regs
+			result = &r
+		}
+	})
+	runTestROM(gb, mooneyeMaxCycles, func() bool { return result != nil })
+
+	switch {
+	case result == nil:
+		t.Error("NO RESULT (TIMEOUT OR CPU LOCKED)")
+	case result.B == 3 && result.C == 5 && result.D == 8 &&
+		result.E == 13 && result.H == 21 && result.L == 34:
+	default:
+		t.Errorf("FAILED : B:%02X C:%02X D:%02X E:%02X H:%02X L:%02X",
+			result.B, result.C, result.D, result.E, result.H, result.L)
+	}
+}
+
+// frameRecorder keeps a copy of the last frame : indexed (DMG) or RGB555 (CGB)
+type frameRecorder struct {
+	frames int
+	buffer coreio.FrameBuffer
+	colors coreio.FrameColors
+	rgb    coreio.RGBFrameBuffer
+}
+
+func (r *frameRecorder) SwapFrameBuffer(
+	frameBuffer *coreio.FrameBuffer, colors *coreio.FrameColors,
+) (*coreio.FrameBuffer, *coreio.FrameColors) {
+	r.frames++
+	r.buffer, r.colors = *frameBuffer, *colors
+	return frameBuffer, colors
+}
+
+func (r *frameRecorder) SwapRGBFrameBuffer(frameBuffer *coreio.RGBFrameBuffer) *coreio.RGBFrameBuffer {
+	r.frames++
+	r.rgb = *frameBuffer
+	return frameBuffer
+}
+
+// acid2Pixel returns the pixel compared to the reference : DMG shade (0-3), or CGB color (5 bits channels)
+func (r *frameRecorder) acid2Pixel(x, y int, cgb bool) [3]uint8 {
+	if cgb {
+		c := r.rgb[y*160+x]
+		return [3]uint8{uint8(c & 0x1F), uint8(c >> 5 & 0x1F), uint8(c >> 10 & 0x1F)}
+	}
+	c := r.colors[int(r.buffer[y*160+x])*3:]
+	for shade, intensity := range [...]uint8{0xED, 0x99, 0x66, 0x21} {
+		if c[0] == intensity {
+			return [3]uint8{uint8(shade)}
+		}
+	}
+	return [3]uint8{0xFF}
+}
+
+// acid2RefPixel converts a pixel of the reference image : shades of gray for DMG, RGB555 scaled to 8 bits for CGB
+func acid2RefPixel(img image.Image, x, y int, cgb bool) [3]uint8 {
+	r, g, b, _ := img.At(img.Bounds().Min.X+x, img.Bounds().Min.Y+y).RGBA()
+	if cgb {
+		return [3]uint8{uint8(r>>8) >> 3, uint8(g>>8) >> 3, uint8(b>>8) >> 3}
+	}
+	return [3]uint8{3 - uint8((r>>8+42)/85)}
+}
+
+func TestAcid2(t *testing.T) {
+	dir := testROMsDir(t, "acid2")
+
+	roms := []struct {
+		rom, ref string
+		cgb      bool
+	}{
+		{"dmg-acid2.gb", "dmg-acid2.png", false},
+		{"cgb-acid2.gbc", "cgb-acid2.png", true},
+	}
+	for _, rom := range roms {
+		rom := rom
+		t.Run(rom.rom, func(t *testing.T) {
+			path := filepath.Join(dir, rom.rom)
+			if _, err := os.Stat(path); err != nil {
+				t.Skipf("ROM NOT FOUND : %s", path)
+			}
+			f, err := os.Open(filepath.Join(dir, rom.ref))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref, err := png.Decode(f)
+			f.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			screen := &frameRecorder{}
+			gb := newTestROMGameBoy(t, path, screen)
+
+			// The test ends with LD B,B once the screen is drawn : a full frame is recorded after
+			end := -1
+			gb.AddExecHook(func(regs coreio.Registers) {
+				if end < 0 && gb.mmu.Peek(regs.PC) == 0x40 {
+					end = screen.frames + 2
+				}
+			})
+			if !runTestROM(gb, mooneyeMaxCycles, func() bool { return end >= 0 && screen.frames >= end }) {
+				t.Fatal("NO RESULT (TIMEOUT OR CPU LOCKED)")
+			}
+
+			mismatches := 0
+			for y := 0; y < 144; y++ {
+				for x := 0; x < 160; x++ {
+					got, want := screen.acid2Pixel(x, y, rom.cgb), acid2RefPixel(ref, x, y, rom.cgb)
+					if got != want {
+						if mismatches == 0 {
+							t.Errorf("FIRST MISMATCH AT (%d, %d) : %v, EXPECTED %v", x, y, got, want)
+						}
+						mismatches++
+					}
+				}
+			}
+			if mismatches > 0 {
+				t.Errorf("%d PIXELS DIFFERENT FROM THE REFERENCE", mismatches)
+			}
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package gpu
+
+// Mode 3 draws the line pixel by pixel, as the LCD controller does : a fetcher reads the BG or window
+// tiles into a FIFO, shifted out to the LCD one pixel per dot. The objects are fetched when the shifter
+// reaches them, stalling the output. Mode 3 lasts 172 dots, plus :
+//   - SCX mod 8 dots : the pixels of the first tile left of the screen are discarded
+//   - 6 dots when the window starts : the FIFO is cleared and the first window tile fetched
+//   - 6 to 11 dots per object : the BG fetch in progress ends (0-5 dots), then the object is fetched (6 dots)
+//
+// The registers are read when the tiles are fetched or the pixels output : the changes of SCX, palettes
+// (CGB palettes data included) or LCDC during the line are visible. The GPU is lazy : the line is drawn up to the current dot on each
+// Tick, before the CPU accesses the registers.
+
+const (
+	tileFetchDots      = 6  // Tile number, data low, data high : 2 dots each
+	firstTileFetchDots = 12 // The first tile of the line is fetched twice
+	objFetchDots       = 6
+	objFetchMaxWait    = 5 // Dots waiting for the BG fetch, when the object is on the first pixel of a tile
+
+	maxLineObjects = 10
+)
+
+// bgPixel : Bits 0-1 color, Bits 2-4 CGB palette, Bit 7 BG-to-OAM priority
+type bgPixel uint8
+
+type objPixel struct {
+	color    uint8 // 0 : transparent
+	palette  uint8 // OBP0-1, OBP0-7 in CGB mode
+	behindBG bool  // OBJ-to-BG Priority
+	index    uint8 // OAM index : CGB priority
+}
+
+// lineRenderer is the state of the pixel pipeline during mode 3
+type lineRenderer struct {
+	line    uint8
+	dots    int   // Dots since the start of mode 3
+	x       uint8 // Pixels output to the LCD
+	discard uint8 // Pixels to drop before the first output : SCX fine scroll, window left of the screen
+
+	// BG & window fetcher
+	tileX     uint8 // Tile fetched, from the first one of the line (BG) or of the window
+	fetchDots int   // Dots since the fetch start : the tile is ready to push after tileFetchDots
+	window    bool  // Fetching the window
+
+	bgFIFO [8]bgPixel
+	bgLen  uint8 // Pixels left, shifted out from bgFIFO[8-bgLen]
+
+	objFIFO [8]objPixel // Pixels of the next 8 dots, objFIFO[objHead] is output next
+	objHead uint8
+	objLeft int // Pixels output before the object FIFO is empty
+	objDots int // Dots left in the object fetch : the output is stalled
+	objects [maxLineObjects]uint8
+	nbObjs  int // Objects on the line, OAM indexes sorted by X
+	nextObj int // Next object to fetch, the previous one during a fetch
+
+	// Registers read once per Tick : the CPU can't write them during a Tick
+	bgEnable  bool
+	objEnable bool
+	bgShades  [4]uint8    // DMG colors mapped with BGP
+	objShades [2][4]uint8 // DMG colors mapped with OBP0 & OBP1
+	stop      int         // Next pixel where the window or an object can start
+}
+
+// startLine prepares the drawing of the line : OAM scan (the 10 first objects on the line, in OAM order,
+// then sorted by X for the fetch order), SCX fine scroll and window trigger
+func (gpu *GPU) startLine(line uint8) {
+	r := &gpu.lineRenderer
+	*r = lineRenderer{
+		line:      line,
+		discard:   gpu.scx.Get() & 7,
+		fetchDots: tileFetchDots - firstTileFetchDots,
+	}
+	if line == gpu.wy.Get() {
+		gpu.windowTriggered = true
+	}
+
+	var height uint8 = 8
+	if gpu.spriteSize.Get() {
+		height = 16
+	}
+	for i := uint8(0); i < 40 && r.nbObjs < maxLineObjects; i++ {
+		sprite := gpu._oam.GetSprite(i)
+		if top := int(line) + 16; top < int(sprite.Y) || top >= int(sprite.Y)+int(height) {
+			continue
+		}
+		// Insertion sort by X, stable : OAM order for the same X
+		j := r.nbObjs
+		for ; j > 0 && gpu._oam.GetSprite(r.objects[j-1]).X > sprite.X; j-- {
+			r.objects[j] = r.objects[j-1]
+		}
+		r.objects[j] = i
+		r.nbObjs++
+	}
+}
+
+// renderLine draws the line up to the dot (since the start of mode 3).
+// It returns true once the 160 pixels are output : mode 3 is over.
+func (gpu *GPU) renderLine(dot int) bool {
+	r := &gpu.lineRenderer
+	gpu.loadLineRegisters()
+	for r.dots < dot && r.x < nbFrameRow {
+		// Object fetch : the shifter is stalled
+		if r.objDots > 0 {
+			r.dots++
+			r.objDots--
+			if r.objDots == 0 {
+				gpu.fetchObject(r.objects[r.nextObj-1])
+			}
+			continue
+		}
+
+		if r.bgLen > 0 && int(r.x) >= r.stop {
+			stalled := false
+			if !r.window && gpu.windowStarts() {
+				r.window = true
+				r.bgLen = 0
+				r.tileX = 0
+				r.fetchDots = 0
+				if wx := gpu.wx.Get(); wx < 7 {
+					r.discard = 7 - wx
+				}
+			} else if r.discard == 0 {
+				stalled = gpu.objectStarts()
+			}
+			r.stop = gpu.nextStop()
+			if stalled {
+				r.dots++
+				continue
+			}
+		}
+
+		// Shifter : one pixel per dot, several up to the next stop while the FIFO isn't empty
+		n := 1
+		if r.bgLen > 0 {
+			if r.discard > 0 {
+				r.discard--
+				r.bgLen--
+			} else {
+				n = minDots(minDots(int(r.bgLen), dot-r.dots), r.stop-int(r.x))
+				if n < 1 {
+					n = 1
+				}
+				gpu.outputPixels(n)
+			}
+		}
+		r.dots += n
+
+		// BG fetcher : the tile is pushed once the FIFO is empty
+		r.fetchDots += n
+		if r.fetchDots >= tileFetchDots && r.bgLen == 0 {
+			gpu.fetchTile()
+			r.fetchDots = 0
+		}
+	}
+	return r.x == nbFrameRow
+}
+
+// loadLineRegisters reads the registers used for each pixel
+func (gpu *GPU) loadLineRegisters() {
+	r := &gpu.lineRenderer
+	r.bgEnable = gpu.bgEnable.Get()
+	r.objEnable = gpu.spriteEnable.Get()
+	bgp, obp0, obp1 := gpu.bgPalette.Get(), gpu.spritePalette0.Get(), gpu.spritePalette1.Get()
+	for c := uint8(0); c < 4; c++ {
+		r.bgShades[c] = bgp >> (c * 2) & 0x03
+		r.objShades[0][c] = obp0 >> (c * 2) & 0x03
+		r.objShades[1][c] = obp1 >> (c * 2) & 0x03
+	}
+	r.stop = gpu.nextStop()
+}
+
+// nextStop returns the next pixel where the window or an object can start
+func (gpu *GPU) nextStop() int {
+	r := &gpu.lineRenderer
+	stop := nbFrameRow
+	if !r.window && gpu.winEnable.Get() && gpu.windowTriggered {
+		if wx := int(gpu.wx.Get()); wx < 7 && r.x == 0 {
+			stop = 0
+		} else if wx >= 7 && wx-7 >= int(r.x) {
+			stop = wx - 7
+		}
+	}
+	if r.nextObj < r.nbObjs {
+		if x := int(gpu._oam.GetSprite(r.objects[r.nextObj]).X) - 8; x < stop {
+			stop = x
+		}
+	}
+	return stop
+}
+
+// windowStarts returns true if the window starts at the next pixel output
+func (gpu *GPU) windowStarts() bool {
+	r := &gpu.lineRenderer
+	if !gpu.winEnable.Get() || !gpu.windowTriggered {
+		return false
+	}
+	wx := gpu.wx.Get()
+	if wx < 7 {
+		return r.x == 0
+	}
+	return r.discard == 0 && r.x+7 == wx
+}
+
+// objectStarts starts the fetch of the next object if it is on the next pixel output.
+// The objects are skipped while disabled.
+func (gpu *GPU) objectStarts() bool {
+	r := &gpu.lineRenderer
+	for r.nextObj < r.nbObjs {
+		sprite := gpu._oam.GetSprite(r.objects[r.nextObj])
+		if int(sprite.X)-8 > int(r.x) {
+			return false
+		}
+		r.nextObj++
+		if !r.objEnable {
+			continue
+		}
+		// The BG fetch in progress ends first : up to 5 dots, always 5 for an object at X = 0
+		wait := objFetchMaxWait - r.fetchDots
+		if sprite.X == 0 {
+			wait = objFetchMaxWait
+		}
+		if wait < 0 {
+			wait = 0
+		}
+		if r.fetchDots < objFetchMaxWait {
+			r.fetchDots = objFetchMaxWait
+		}
+		r.objDots = wait + objFetchDots - 1 // This dot included
+		return true
+	}
+	return false
+}
+
+// fetchTile pushes the next 8 pixels of the BG or window to the FIFO
+func (gpu *GPU) fetchTile() {
+	r := &gpu.lineRenderer
+	var tileMap, tileX, y uint8
+	if r.window {
+		if gpu.winTileMap.Get() {
+			tileMap = 1
+		}
+		tileX, y = r.tileX&0x1F, gpu.windowLine
+	} else {
+		if gpu.bgTileMap.Get() {
+			tileMap = 1
+		}
+		tileX, y = (gpu.scx.Get()>>3+r.tileX)&0x1F, r.line+gpu.scy.Get()
+	}
+	info := gpu._vram.GetTileInfo(tileMap, tileX, y>>3)
+	attr := info.palette<<2 | info.bgToOAMPriority<<7
+
+	var buff [8]uint8
+	pixels := gpu._vram.GetTileData(gpu.tileData.Get(), info.tileID, info.bank).
+		appendPixelsLine(buff[:0], y&7, 0, 8, attr, info.hFlip == 1, info.vFlip == 1)
+	for i, p := range pixels {
+		r.bgFIFO[i] = bgPixel(p)
+	}
+	r.bgLen = 8
+	r.tileX++
+}
+
+// fetchObject mixes the object pixels in the object FIFO.
+// A pixel already in the FIFO keeps the priority (lower X first), except for a lower OAM index in CGB mode.
+func (gpu *GPU) fetchObject(index uint8) {
+	r := &gpu.lineRenderer
+	sprite := gpu._oam.GetSprite(index)
+
+	var height uint8 = 8
+	if gpu.spriteSize.Get() {
+		height = 16
+	}
+	row := (r.line + 16 - sprite.Y) & (height - 1)
+	if sprite.YFlip {
+		row = height - 1 - row
+	}
+	tileID := sprite.TileID
+	if height == 16 {
+		tileID = tileID&0xFE | row>>3
+		row &= 7
+	}
+	palette := sprite.PaletteNumber
+	if gpu.cgb {
+		palette = sprite.ColorPalette
+	}
+
+	var buff [8]uint8
+	pixels := gpu._vram.GetTileData(true, tileID, sprite.BankNumber).
+		appendPixelsLine(buff[:0], row, 0, 8, 0, sprite.XFlip, false)
+	for i, color := range pixels {
+		offset := int(sprite.X) - 8 + i - int(r.x)
+		if offset < 0 || color == 0 {
+			continue
+		}
+		slot := &r.objFIFO[(int(r.objHead)+offset)&7]
+		if slot.color == 0 || gpu.cgb && index < slot.index {
+			*slot = objPixel{color: color, palette: palette, behindBG: sprite.ObjToBgPriority, index: index}
+		}
+		if offset >= r.objLeft {
+			r.objLeft = offset + 1
+		}
+	}
+}
+
+// outputPixels shifts n pixels out of the FIFO, mixes them with the objects and draws the result on the LCD.
+// The colors are resolved with the palettes when output : DMG shades, CGB RGB555 colors.
+func (gpu *GPU) outputPixels(n int) {
+	r := &gpu.lineRenderer
+	line := gpu.frameBuffer[int(r.line)*nbFrameRow:]
+	rgbLine := gpu.rgbFrame[int(r.line)*nbFrameRow:]
+	for ; n > 0 && r.objLeft > 0; n-- {
+		r.objLeft--
+		bg := r.bgFIFO[8-r.bgLen]
+		r.bgLen--
+		obj := r.objFIFO[r.objHead]
+		r.objFIFO[r.objHead] = objPixel{}
+		r.objHead = (r.objHead + 1) & 7
+
+		color := uint8(bg) & 0x03
+		if !gpu.cgb && !r.bgEnable { // DMG : BG & window blank
+			color = 0
+		}
+
+		var value uint8
+		switch {
+		case obj.color != 0 && r.objEnable &&
+			(color == 0 || gpu.cgb && !r.bgEnable || !obj.behindBG && bg&0x80 == 0):
+			if gpu.cgb {
+				value = obj.palette<<2 | obj.color
+			} else {
+				value = obj.palette<<2 | r.objShades[obj.palette][obj.color]
+			}
+		case gpu.cgb:
+			value = 0b100000 | uint8(bg)&0x1F
+		case !r.bgEnable:
+			value = 0b100000
+		default:
+			value = 0b100000 | r.bgShades[color]
+		}
+		if gpu.cgb {
+			rgbLine[r.x] = gpu.palettesManager.color(value)
+		} else {
+			line[r.x] = value
+		}
+		r.x++
+	}
+
+	// Without objects
+	r.objHead = (r.objHead + uint8(n)) & 7
+	for ; n > 0; n-- {
+		bg := r.bgFIFO[8-r.bgLen]
+		r.bgLen--
+		switch {
+		case gpu.cgb:
+			rgbLine[r.x] = gpu.palettesManager.color(0b100000 | uint8(bg)&0x1F)
+		case !r.bgEnable:
+			line[r.x] = 0b100000
+		default:
+			line[r.x] = 0b100000 | r.bgShades[bg&0x03]
+		}
+		r.x++
+	}
+}
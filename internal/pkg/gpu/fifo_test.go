@@ -0,0 +1,289 @@
+package gpu
+
+import (
+	"testing"
+
+	"github.com/jmontupet/gbcore/internal/pkg/ioports"
+	"github.com/jmontupet/gbcore/pkg/coreio"
+	"github.com/jmontupet/gbcore/pkg/nullio"
+)
+
+// newTestGPU returns a GPU with the LCD, BG and objects enabled (tile data at 8000).
+// Tile 1 is filled with color 3, tile 0 with color 0.
+func newTestGPU(cgb bool) (*GPU, *ioports.IOPorts) {
+	io := ioports.NewGBIOPorts(cgb)
+	gpu := NewGBGPU(io, nullio.NewNullFrameDrawer(), cgb)
+	io.Write(0xFF40, 0x93)
+	io.Write(0xFF47, identityPalette)
+	for i := uint16(0x10); i < 0x20; i++ {
+		gpu.PokeVRAM(0, i, 0xFF)
+	}
+	return gpu, io
+}
+
+// setCGBPalettes sets each CGB palettes color to its pixel value (RGB555) : 0b100000 | palette << 2 | color
+// for the BG, palette << 2 | color for the objects
+func setCGBPalettes(gpu *GPU) {
+	gpu.Write(0xFF68, 0x80) // Auto increment
+	gpu.Write(0xFF6A, 0x80)
+	for i := uint8(0); i < 32; i++ {
+		gpu.Write(0xFF69, 0b100000|i)
+		gpu.Write(0xFF69, 0)
+		gpu.Write(0xFF6B, i)
+		gpu.Write(0xFF6B, 0)
+	}
+}
+
+// setObject sets the position and tile of an object
+func setObject(gpu *GPU, id uint8, x, y, tile, attr uint8) {
+	offset := uint16(id) * 4
+	gpu.PokeOAM(offset, y)
+	gpu.PokeOAM(offset+1, x)
+	gpu.PokeOAM(offset+2, tile)
+	gpu.PokeOAM(offset+3, attr)
+}
+
+// tickToMode3 runs the GPU dot by dot up to the start of mode 3
+func tickToMode3(gpu *GPU) {
+	for gpu.getMode() != ModeVRAM {
+		gpu.Tick(1)
+	}
+}
+
+func TestMode3Length(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(gpu *GPU, io *ioports.IOPorts)
+		want  int
+	}{
+		{"BG only", func(gpu *GPU, io *ioports.IOPorts) {}, 172},
+		{"SCX fine scroll", func(gpu *GPU, io *ioports.IOPorts) { io.Write(0xFF43, 0x0D) }, 177},
+		{"window", func(gpu *GPU, io *ioports.IOPorts) {
+			io.Write(0xFF40, 0xB3)
+			io.Write(0xFF4B, 87)
+		}, 178},
+		{"object at X 0", func(gpu *GPU, io *ioports.IOPorts) { setObject(gpu, 0, 0, 16, 1, 0) }, 183},
+		{"object on a tile start", func(gpu *GPU, io *ioports.IOPorts) { setObject(gpu, 0, 24, 16, 1, 0) }, 183},
+		{"object in a tile", func(gpu *GPU, io *ioports.IOPorts) { setObject(gpu, 0, 27, 16, 1, 0) }, 180},
+		{"objects in the same tile", func(gpu *GPU, io *ioports.IOPorts) {
+			setObject(gpu, 0, 24, 16, 1, 0)
+			setObject(gpu, 1, 26, 16, 1, 0)
+		}, 189},
+		{"10 objects per line", func(gpu *GPU, io *ioports.IOPorts) {
+			for i := uint8(0); i < 12; i++ {
+				setObject(gpu, i, 8+16*i, 16, 1, 0)
+			}
+		}, 172 + 10*11},
+		{"objects disabled", func(gpu *GPU, io *ioports.IOPorts) {
+			io.Write(0xFF40, 0x91)
+			setObject(gpu, 0, 8, 16, 1, 0)
+		}, 172},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Dot by dot
+			gpu, io := newTestGPU(false)
+			tt.setup(gpu, io)
+			tickToMode3(gpu)
+			dots := 0
+			for gpu.getMode() == ModeVRAM {
+				gpu.Tick(1)
+				dots++
+			}
+			if dots != tt.want {
+				t.Errorf("mode 3 length : %d, expected %d", dots, tt.want)
+			}
+
+			// Up to the events : H-Blank starts on an event
+			gpu, io = newTestGPU(false)
+			tt.setup(gpu, io)
+			tickToMode3(gpu)
+			start := gpu.lineCycles
+			for gpu.getMode() == ModeVRAM {
+				gpu.Tick(gpu.NextEvent())
+			}
+			if dots := gpu.lineCycles - start; dots != tt.want {
+				t.Errorf("mode 3 length with events : %d, expected %d", dots, tt.want)
+			}
+		})
+	}
+}
+
+// TestMidLineChanges checks the registers written during mode 3 apply to the next pixels
+func TestMidLineChanges(t *testing.T) {
+	tickToX := func(gpu *GPU, x uint8) {
+		for gpu.lineRenderer.x < x {
+			gpu.Tick(1)
+		}
+	}
+	line := func(gpu *GPU) []uint8 {
+		for gpu.getMode() == ModeVRAM {
+			gpu.Tick(1)
+		}
+		return gpu.frameBuffer[:nbFrameRow]
+	}
+
+	t.Run("SCX", func(t *testing.T) {
+		gpu, io := newTestGPU(false)
+		gpu.PokeVRAM(0, 0x1800+10, 1) // BG map column 10
+		tickToMode3(gpu)
+		tickToX(gpu, 40)
+		io.Write(0xFF43, 16)
+		pixels := line(gpu)
+		// The tiles fetched after the change are 2 columns to the right : column 10 at 64-71
+		for x, p := range pixels {
+			want := uint8(0b100000)
+			if x >= 64 && x < 72 {
+				want |= 3
+			}
+			if p != want {
+				t.Fatalf("pixel %d : 0x%02X, expected 0x%02X", x, p, want)
+			}
+		}
+	})
+
+	t.Run("BGP", func(t *testing.T) {
+		gpu, io := newTestGPU(false)
+		tickToMode3(gpu)
+		tickToX(gpu, 80)
+		io.Write(0xFF47, 0xE7) // Color 0 : shade 3
+		pixels := line(gpu)
+		if pixels[79] != 0b100000 || pixels[80] != 0b100011 {
+			t.Errorf("pixels 79-80 : 0x%02X 0x%02X", pixels[79], pixels[80])
+		}
+	})
+
+	t.Run("BCPD", func(t *testing.T) {
+		gpu, _ := newTestGPU(true)
+		setCGBPalettes(gpu)
+		tickToMode3(gpu)
+		tickToX(gpu, 80)
+		gpu.Write(0xFF68, 0x80) // BG palette 0, color 0
+		gpu.Write(0xFF69, 0x1F)
+		gpu.Write(0xFF69, 0x7C)
+		line(gpu)
+		if c := gpu.rgbFrame[79]; c != 0b100000 {
+			t.Errorf("pixel 79 : 0x%04X, expected 0x0020", c)
+		}
+		if c := gpu.rgbFrame[80]; c != 0x7C1F {
+			t.Errorf("pixel 80 : 0x%04X, expected 0x7C1F", c)
+		}
+
+		// Up to the end of the frame : the RGB555 frame is sent to the renderer
+		recorder := &rgbFrameRecorder{}
+		gpu.rgbRenderer = recorder
+		for gpu.getMode() != ModeVBlank {
+			gpu.Tick(1)
+		}
+		if recorder.frames != 1 || recorder.frame[79] != 0b100000 || recorder.frame[80] != 0x7C1F {
+			t.Errorf("frame %d : pixels 0x%04X 0x%04X", recorder.frames, recorder.frame[79], recorder.frame[80])
+		}
+	})
+}
+
+func TestObjectPriority(t *testing.T) {
+	for _, cgb := range []bool{false, true} {
+		gpu, io := newTestGPU(cgb)
+		setCGBPalettes(gpu)
+		io.Write(0xFF48, identityPalette)
+		io.Write(0xFF49, identityPalette)
+		setObject(gpu, 0, 20, 16, 1, 0x10|0x01) // OBP1, CGB palette 1
+		setObject(gpu, 1, 16, 16, 1, 0x00)
+		tickToMode3(gpu)
+		for gpu.getMode() == ModeVRAM {
+			gpu.Tick(1)
+		}
+		// DMG : lower X first, CGB : lower OAM index first
+		want := uint8(0b000011)
+		if cgb {
+			want = 0b000111
+		}
+		p := gpu.frameBuffer[12]
+		if cgb {
+			p = uint8(gpu.rgbFrame[12])
+		}
+		if p != want {
+			t.Errorf("CGB %v : overlapping pixel 0x%02X, expected 0x%02X", cgb, p, want)
+		}
+	}
+}
+
+// TestTickGranularity checks the frame drawn is the same ticking dot by dot or up to the events
+func TestTickGranularity(t *testing.T) {
+	var frames [2][]uint8
+	for i := range frames {
+		gpu, io := newTestGPU(false)
+		io.Write(0xFF40, 0xF3) // Window map 9C00
+		io.Write(0xFF43, 0x05)
+		io.Write(0xFF4A, 40)
+		io.Write(0xFF4B, 60)
+		io.Write(0xFF48, 0x1B)
+		for j := uint16(0); j < 0x400; j += 3 {
+			gpu.PokeVRAM(0, 0x1800+j, 1)
+			gpu.PokeVRAM(0, 0x1C00+j/2, 1)
+		}
+		for j := uint8(0); j < 40; j++ {
+			setObject(gpu, j, j*5, 16+j*3, 1, j&1<<7)
+		}
+		for gpu.ly.Get() < nbFrameLines {
+			if i == 0 {
+				gpu.Tick(1)
+			} else {
+				gpu.Tick(gpu.NextEvent())
+			}
+		}
+		frames[i] = append([]uint8(nil), gpu.frameBuffer[:]...)
+	}
+	for p := range frames[0] {
+		if frames[0][p] != frames[1][p] {
+			t.Fatalf("pixel (%d, %d) : 0x%02X dot by dot, 0x%02X by events",
+				p%nbFrameRow, p/nbFrameRow, frames[0][p], frames[1][p])
+		}
+	}
+}
+
+// rgbFrameRecorder keeps a copy of the last RGB555 frame
+type rgbFrameRecorder struct {
+	frames int
+	frame  coreio.RGBFrameBuffer
+}
+
+func (r *rgbFrameRecorder) SwapRGBFrameBuffer(frameBuffer *coreio.RGBFrameBuffer) *coreio.RGBFrameBuffer {
+	r.frames++
+	r.frame = *frameBuffer
+	return frameBuffer
+}
+
+// TestFlushRGBFrame checks the CGB frames are sent to a RGBFrameDrawer without color limit
+func TestFlushRGBFrame(t *testing.T) {
+	gpu, _ := newTestGPU(true)
+	recorder := &rgbFrameRecorder{}
+	gpu.rgbRenderer = recorder
+	for i := range gpu.rgbFrame {
+		gpu.rgbFrame[i] = uint16(i % 1000)
+	}
+	gpu.FlushFrameBuffer()
+	for i, c := range recorder.frame {
+		if c != uint16(i%1000) {
+			t.Fatalf("pixel %d : 0x%04X, expected 0x%04X", i, c, i%1000)
+		}
+	}
+}
+
+// TestIndexColors checks the CGB colors numbering for the renderers without RGB555 frames :
+// in order of appearance, past 64 colors the nearest color is used
+func TestIndexColors(t *testing.T) {
+	gpu, _ := newTestGPU(true)
+	for i := range gpu.rgbFrame {
+		gpu.rgbFrame[i] = uint16(i % 64 * 2) // Red 0-30 (even), green 0-3
+	}
+	gpu.rgbFrame[64] = 0x7FFF
+	gpu.indexColors()
+	if gpu.frameBuffer[63] != 63 || gpu.frameBuffer[65] != 1 {
+		t.Errorf("pixels 63, 65 : colors %d %d, expected 63 1", gpu.frameBuffer[63], gpu.frameBuffer[65])
+	}
+	// White : nearest to red 30, green 3 (0x007E)
+	if c := gpu.frameBuffer[64]; c != 63 {
+		t.Errorf("65th color : color %d, expected 63", c)
+	}
+}
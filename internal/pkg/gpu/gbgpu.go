@@ -5,7 +5,9 @@ import (
 
 	"github.com/jmontupet/gbcore/pkg/coreio"
 
+	"github.com/jmontupet/gbcore/internal/pkg/constants"
 	"github.com/jmontupet/gbcore/internal/pkg/ioports"
+	"github.com/jmontupet/gbcore/internal/pkg/memory"
 	"github.com/jmontupet/gbcore/internal/pkg/mmu/memorymap"
 )
 
@@ -13,14 +15,13 @@ const (
 	nbFrameLines = 144
 	nbFrameRow   = 160
 	oamLength    = 80   // 77-83 (80)
-	drawLength   = 172  // 169-175 (172)
-	hBlankLength = 204  // 201-207 (204)
+	drawLength   = 172  // 172-289 : variable, see lineRenderer
+	hBlankLength = 204  // 204-87 : the rest of the line
 	vBlankLength = 4560 // 4560
 
 	// Each Line
 	oamEnd     = oamLength
-	drawEnd    = oamEnd + drawLength
-	hBlanckEnd = drawEnd + hBlankLength
+	hBlanckEnd = oamEnd + drawLength + hBlankLength
 
 	// Each Fram
 	frameEnd  = nbFrameLines * hBlanckEnd
@@ -29,6 +30,9 @@ const (
 
 type gpuMode uint8
 
+// identityPalette maps each color to the same shade
+const identityPalette = 0b11100100
+
 const frameBufferSize = nbFrameRow * nbFrameLines
 
 const (
@@ -55,6 +59,12 @@ type GPU struct {
 	renderer    coreio.FrameDrawer
 	frameBuffer *coreio.FrameBuffer
 	frameColors *coreio.FrameColors
+
+	// CGB : the pixels colors are resolved when output (palettes written during the frame).
+	// The frame is sent as is to a RGBFrameDrawer, or numbered in the frame colors by FlushFrameBuffer.
+	rgbRenderer  coreio.RGBFrameDrawer // nil : renderer without RGB555 frames
+	rgbFrame     *coreio.RGBFrameBuffer
+	colorIndexes [0x8000]uint8 // Frame color + 1 of each RGB555 color, 0 : not numbered
 	// frameBufferSprite coreio.FrameBuffer
 	// frameBufferBg     coreio.FrameBuffer
 
@@ -62,6 +72,11 @@ type GPU struct {
 	frameCycles int
 	lineCycles  int
 
+	// Mode 3 pixel pipeline
+	lineRenderer    lineRenderer
+	windowTriggered bool  // WY matched LY in the frame
+	windowLine      uint8 // Window internal line counter : lines drawn with the window in the frame
+
 	// STAT
 	statInterruptOnCoincidence *ioports.BitPtr    // Bit 6 - LYC=LY Coincidence Interrupt (1=Enable) (Read/Write)
 	statInterruptOnOAM         *ioports.BitPtr    // Bit 5 - Mode 2 OAM Interrupt         (1=Enable) (Read/Write)
@@ -138,12 +153,16 @@ func (gpu *GPU) setMonoColorPalette(offset int, palette uint8) {
 
 func (gpu *GPU) FlushFrameBuffer() {
 	// Prepare colors palette
+	if gpu.cgb && gpu.rgbRenderer != nil {
+		gpu.rgbFrame = gpu.rgbRenderer.SwapRGBFrameBuffer(gpu.rgbFrame)
+		return
+	}
 	if gpu.cgb {
-		gpu.palettesManager.setPalettes(gpu.frameColors)
-	} else {
-		gpu.setMonoColorPalette(0b100000, gpu.bgPalette.Get())
-		gpu.setMonoColorPalette(0b000000, gpu.spritePalette0.Get())
-		gpu.setMonoColorPalette(0b000100, gpu.spritePalette1.Get())
+		gpu.indexColors()
+	} else { // Pixels already mapped with the palettes when drawn
+		gpu.setMonoColorPalette(0b100000, identityPalette)
+		gpu.setMonoColorPalette(0b000000, identityPalette)
+		gpu.setMonoColorPalette(0b000100, identityPalette)
 	}
 
 	// for i := 0; i < frameBufferSize; i++ {
@@ -160,15 +179,61 @@ func (gpu *GPU) FlushFrameBuffer() {
 		gpu.renderer.SwapFrameBuffer(gpu.frameBuffer, gpu.frameColors)
 }
 
-func (gpu *GPU) Tick(cycles uint8) uint8 {
-	gpu.frameCycles += int(cycles)
-	gpu.lineCycles += int(cycles)
+// indexColors numbers the RGB555 colors of the CGB frame in the frame colors, in order of appearance,
+// for the renderers without RGB555 frames. Past 64 colors in a frame, the pixels get the nearest color numbered.
+func (gpu *GPU) indexColors() {
+	nbColors := 0
+	for i, c := range gpu.rgbFrame {
+		index := gpu.colorIndexes[c]
+		if index == 0 {
+			if nbColors < constants.ScreenColors {
+				setRGB555(gpu.frameColors[nbColors*3:], c)
+				nbColors++
+				index = uint8(nbColors)
+			} else {
+				index = gpu.nearestColor(c) + 1
+			}
+			gpu.colorIndexes[c] = index
+		}
+		gpu.frameBuffer[i] = index - 1
+	}
+	gpu.colorIndexes = [0x8000]uint8{}
+}
+
+// nearestColor returns the frame color closest to the RGB555 color c
+func (gpu *GPU) nearestColor(c uint16) uint8 {
+	var rgb [3]uint8
+	setRGB555(rgb[:], c)
+	best, bestDist := 0, -1
+	for i := 0; i < constants.ScreenColors; i++ {
+		dist := 0
+		for j, v := range rgb {
+			d := int(gpu.frameColors[i*3+j]) - int(v)
+			dist += d * d
+		}
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return uint8(best)
+}
+
+// setRGB555 sets the 3 bytes of dst to the RGB555 color c
+func setRGB555(dst []uint8, c uint16) {
+	dst[0] = uint8(c&0x1F) << 3
+	dst[1] = uint8(c>>5&0x1F) << 3
+	dst[2] = uint8(c>>10&0x1F) << 3
+}
+
+// Tick runs the GPU for a number of dots (4 per machine cycle, 2 in double speed) and returns LY.
+// The dots must not go past the next event (NextEvent) : mode and line changes are only checked at the end.
+func (gpu *GPU) Tick(dots int) uint8 {
+	gpu.frameCycles += dots
+	gpu.lineCycles += dots
 	mode := gpu.getMode()
 
 	if gpu.frameCycles > frameEnd { // IN V-BLANK
 		if mode != ModeVBlank {
-			// Last Line
-			gpu.drawLine(gpu.ly.Get())
 			// VBlank interrupt
 			gpu.vBlankInterrupt.Set(true)
 			if gpu.statInterruptOnVBlank.Get() {
@@ -183,6 +248,8 @@ func (gpu *GPU) Tick(cycles uint8) uint8 {
 			gpu.frameCycles = gpu.frameCycles - vBlankEnd
 			gpu.ly.Set(0)
 			gpu.setMode(ModeOAM)
+			gpu.windowTriggered = false
+			gpu.windowLine = 0
 		}
 
 	} else { // IN FRAME
@@ -195,20 +262,21 @@ func (gpu *GPU) Tick(cycles uint8) uint8 {
 				}
 			}
 			break
-		case gpu.lineCycles < drawEnd:
-			if mode != ModeVRAM {
+		default: // Mode 3 until the line is drawn, then H-Blank
+			if mode == ModeOAM {
 				gpu.setMode(ModeVRAM)
-				gpu.drawLine(gpu.ly.Get())
+				gpu.startLine(gpu.ly.Get())
+				mode = ModeVRAM
 			}
-			break
-		case gpu.lineCycles < hBlanckEnd:
-			if mode != ModeHBlank {
+			if mode == ModeVRAM && gpu.renderLine(gpu.lineCycles-oamEnd) {
 				gpu.setMode(ModeHBlank)
+				if gpu.lineRenderer.window {
+					gpu.windowLine++
+				}
 				if gpu.statInterruptOnHBlank.Get() {
 					gpu.statInterrupt.Set(true)
 				}
 			}
-			break
 		}
 	}
 
@@ -230,154 +298,105 @@ func (gpu *GPU) Tick(cycles uint8) uint8 {
 	return gpu.ly.Get()
 }
 
-func (gpu *GPU) drawLine(screenLine uint8) {
-	if screenLine >= nbFrameLines { // if memory is corrupted...
-		return
-	}
-	if gpu.bgEnable.Get() {
-		gpu.drawBgLine(screenLine)
-	}
-	if gpu.winEnable.Get() {
-		gpu.drawWindowLine(screenLine)
+// NextEvent returns the dots before the next change : mode, line, or interrupt request.
+// While LY = LYC with the coincidence interrupt enabled, the request is renewed on each tick.
+func (gpu *GPU) NextEvent() int {
+	coincidence := gpu.ly.Get() == gpu.lyc.Get()
+	if coincidence && gpu.statInterruptOnCoincidence.Get() || coincidence != gpu.coincidenceFlag.Get() {
+		return 1
 	}
-	if gpu.spriteEnable.Get() {
-		gpu.drawSpriteLine(screenLine)
-	}
-}
-func (gpu *GPU) drawWindowLine(screenLine uint8) {
-	bgScrollY := screenLine + gpu.wy.Get()
-	bgScrollX := gpu.wx.Get() - 7
-	if screenLine < gpu.wy.Get() {
-		return
-	}
-	firstTileOffset := bgScrollX & 7
-	var winMap uint8 = 0
-	if gpu.winTileMap.Get() {
-		winMap = 1
+	next := hBlanckEnd - gpu.lineCycles // Next line
+	mode := gpu.getMode()
+
+	if gpu.frameCycles > frameEnd { // IN V-BLANK
+		if mode != ModeVBlank {
+			return 1
+		}
+		return minDots(next, vBlankEnd-gpu.frameCycles)
 	}
-	tileDataLine := bgScrollY & 7
-
-	drawPointer := uint32(screenLine) * nbFrameRow
-
-	tileDataTable := gpu.tileData.Get()
-
-	nbPixelsDraw := 0
-	var nbPixelsToDraw uint8 = 8
-drawloop:
-	for i := uint8(0); ; i++ { // Open range : break with label "drawloop"
-		tileMapInfo := gpu._vram.GetTileInfo(winMap, (bgScrollX>>3+i)&0x1F, bgScrollY>>3)
-		palettePrefix := 0b100000 | (tileMapInfo.palette)<<2
-		gpu._vram.GetTileData(tileDataTable, tileMapInfo.tileID, tileMapInfo.bank).
-			appendPixelsLine(gpu.frameBuffer[drawPointer:drawPointer], tileDataLine, firstTileOffset, nbPixelsToDraw, palettePrefix, tileMapInfo.hFlip == 1, tileMapInfo.vFlip == 1)
-
-		drawPointer += (8 - uint32(firstTileOffset))
-		nbPixelsDraw += (8 - int(firstTileOffset))
-		if diff := nbFrameRow - nbPixelsDraw; diff <= 0 { // END of the line : 160 pixels draw
-			break drawloop
-		} else if diff < 8 {
-			nbPixelsToDraw = uint8(diff)
+	// IN FRAME
+	next = minDots(next, frameEnd+1-gpu.frameCycles)
+	switch {
+	case gpu.lineCycles < oamEnd:
+		if mode != ModeOAM {
+			return 1
 		}
-		firstTileOffset = 0
+		return minDots(next, oamEnd-gpu.lineCycles)
+	case mode == ModeOAM:
+		return 1
+	case mode == ModeVRAM: // At least one dot per pixel left
+		return minDots(next, nbFrameRow-int(gpu.lineRenderer.x))
 	}
+	return next
 }
-func (gpu *GPU) drawSpriteLine(screenLine uint8) {
 
-	// println("DRAW sprites")
-	var maxHeight uint8 = 8
-	mode8x16 := gpu.spriteSize.Get()
-	if mode8x16 {
-		maxHeight = 16
+func minDots(a, b int) int {
+	if a < b {
+		return a
 	}
+	return b
+}
 
-	for i := 0; i < 40; i++ { // 40 sprites * 4 bytes
-		sprite := gpu._oam.GetSprite(uint8(i))
-		if sprite.Y == 0 || sprite.Y >= 160 { // Offscreen
-			continue
-		}
-		topLeftX := int(sprite.X) - 8
-		topLeftY := int(sprite.Y) - 16
-		if topLeftY > int(screenLine) || topLeftY+int(maxHeight) <= int(screenLine) { // Not on the line
-			continue
-		}
-
-		offscreenX := sprite.X == 0 || sprite.X >= 168 // Offscreen but affects the priority
-		if !offscreenX {
+// Proxy for _vram access & OAM
+// VRAMBank returns the VRAM bank mapped at 8000-9FFF
+func (gpu *GPU) VRAMBank() uint {
+	return uint(gpu._vram.bankFlag.Get())
+}
 
-			spriteLine := int(screenLine) - topLeftY
+// VRAM returns the VRAM (8000-9FFF), banked by VBK
+func (gpu *GPU) VRAM() memory.Memory { return &gpu._vram }
 
-			for j, p := range sprite.GetPixels(&gpu._vram, uint8(spriteLine), 0, mode8x16) {
-				pixelX := topLeftX + j
-				if pixelX >= 160 {
-					break
-				}
-				if pixelX < 0 {
-					continue
-				}
-				// Sprite data 00 is transparent
-				if p == 0 {
-					continue
-				}
-				var colorPrefix uint8 = 0b000000
-				if gpu.cgb {
-					colorPrefix |= sprite.ColorPalette << 2
-				} else {
-					if sprite.PaletteNumber == 1 {
-						colorPrefix = 0b000100
-					}
-				}
+// PeekVRAM returns the byte at offset (0000-1FFF) of a VRAM bank, independently of VBK
+func (gpu *GPU) PeekVRAM(bank uint8, offset uint16) uint8 {
+	return gpu._vram.read(bank&0x01, memorymap.VRamStart+offset&0x1FFF)
+}
 
-				// BG color 0 is always behind OBJ
-				if gpu.frameBuffer[int(screenLine)*160+pixelX]&0x03 == 0 {
-					gpu.frameBuffer[int(screenLine)*160+pixelX] = colorPrefix + p
-				}
-				// OBJ-to-BG Priority (0=OBJ Above BG, 1=OBJ Behind BG color 1-3)
-				if !sprite.ObjToBgPriority {
-					gpu.frameBuffer[int(screenLine)*160+pixelX] = colorPrefix + p
-				}
-			}
-		}
-	}
+// PokeVRAM sets the byte at offset (0000-1FFF) of a VRAM bank, independently of VBK
+func (gpu *GPU) PokeVRAM(bank uint8, offset uint16, value uint8) {
+	gpu._vram.write(bank&0x01, memorymap.VRamStart+offset&0x1FFF, value)
+}
 
+// PeekOAM returns the byte at offset (00-9F) of the OAM
+func (gpu *GPU) PeekOAM(offset uint16) uint8 {
+	return gpu._oam.Read(memorymap.OAMStart + offset)
 }
 
-func (gpu *GPU) drawBgLine(screenLine uint8) {
-	bgScrollY := screenLine + gpu.scy.Get()
-	bgScrollX := gpu.scx.Get()
-	firstTileOffset := bgScrollX & 7
+// PokeOAM sets the byte at offset (00-9F) of the OAM
+func (gpu *GPU) PokeOAM(offset uint16, value uint8) {
+	gpu._oam.internalWrite(memorymap.OAMStart+offset, value)
+}
 
-	var bgMap uint8 = 0
-	if gpu.bgTileMap.Get() {
-		bgMap = 1
+// PaletteRAM returns the CGB palettes data (BG or OBJ, 64 bytes) without copy
+func (gpu *GPU) PaletteRAM(obj bool) []uint8 {
+	if obj {
+		return gpu.palettesManager.spritePaletteData[:]
 	}
+	return gpu.palettesManager.bgPaletteData[:]
+}
 
-	tileDataLine := bgScrollY & 7 // modulus 8
-
-	drawPointer := uint32(screenLine) * nbFrameRow
-
-	tileDataTable := gpu.tileData.Get()
-	nbPixelsDraw := 0
-	nbPixelsToDraw := uint8(8)
-	// var buff [8]uint8
-drawloop:
-	for i := uint8(0); ; i++ { // Open range : break with label "drawloop"
-		tileMapInfo := gpu._vram.GetTileInfo(bgMap, (bgScrollX>>3+i)&0x1F, bgScrollY>>3)
-		palettePrefix := 0b100000 | (tileMapInfo.palette)<<2
-
-		gpu._vram.GetTileData(tileDataTable, tileMapInfo.tileID, tileMapInfo.bank).
-			appendPixelsLine(gpu.frameBuffer[drawPointer:drawPointer], tileDataLine, firstTileOffset, nbPixelsToDraw, palettePrefix, tileMapInfo.hFlip == 1, tileMapInfo.vFlip == 1)
+// Blocked returns true if the CPU can't access addr while the LCD draws (reads 0xFF, writes ignored) :
+// OAM in modes 2 and 3, VRAM and CGB palettes data in mode 3.
+func (gpu *GPU) Blocked(addr uint16) bool {
+	if !gpu.displayEnable.Get() {
+		return false
+	}
+	mode := gpu.getMode()
+	switch {
+	case addr >= memorymap.OAMStart && addr <= memorymap.OAMEnd:
+		return mode == ModeOAM || mode == ModeVRAM
+	case addr >= memorymap.VRamStart && addr <= memorymap.VRamEnd, gpu.cgb && (addr == 0xFF69 || addr == 0xFF6B):
+		return mode == ModeVRAM
+	}
+	return false
+}
 
-		drawPointer += (8 - uint32(firstTileOffset))
-		nbPixelsDraw += (8 - int(firstTileOffset))
-		if diff := nbFrameRow - nbPixelsDraw; diff <= 0 { // END of the line : 160 pixels draw
-			break drawloop
-		} else if diff < 8 {
-			nbPixelsToDraw = uint8(diff)
-		}
-		firstTileOffset = 0
+// BlockedWrite runs a CPU write ignored by the GPU (Blocked) : the palettes index is still incremented
+func (gpu *GPU) BlockedWrite(addr uint16) {
+	if addr == 0xFF69 || addr == 0xFF6B {
+		gpu.palettesManager.skipWrite(addr)
 	}
 }
 
-// Proxy for _vram access & OAM
 func (gpu *GPU) Read(addr uint16) uint8 {
 	switch {
 	case
@@ -426,6 +445,7 @@ func NewGBGPU(io *ioports.IOPorts, renderer coreio.FrameDrawer, cgb bool) *GPU {
 
 		frameBuffer: new(coreio.FrameBuffer),
 		frameColors: new(coreio.FrameColors),
+		rgbFrame:    new(coreio.RGBFrameBuffer),
 
 		statInterruptOnCoincidence: io.NewBit6Ptr(0xFF41),
 		statInterruptOnOAM:         io.NewBit5Ptr(0xFF41),
@@ -459,5 +479,6 @@ func NewGBGPU(io *ioports.IOPorts, renderer coreio.FrameDrawer, cgb bool) *GPU {
 		spritePalette0: io.NewPtr(0xFF48), // OBP0 - Object Palette 0 Data
 		spritePalette1: io.NewPtr(0xFF49), // OBP1 - Object Palette 1 Data
 	}
+	gpu.rgbRenderer, _ = renderer.(coreio.RGBFrameDrawer)
 	return gpu
 }
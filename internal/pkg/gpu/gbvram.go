@@ -32,41 +32,51 @@ type gbVRAM struct {
 }
 
 func (vram *gbVRAM) Read(addr uint16) uint8 {
+	return vram.read(vram.bankFlag.Get(), addr)
+}
+
+func (vram *gbVRAM) Write(addr uint16, value uint8) {
+	vram.write(vram.bankFlag.Get(), addr, value)
+}
+
+// read returns the value at addr in a bank, independently of VBK
+func (vram *gbVRAM) read(bank uint8, addr uint16) uint8 {
 	switch {
 	// Tile MAP 0
 	case addr >= tileMap0Start && addr <= tileMap0End:
-		if vram.bankFlag.Get() == 0 {
+		if bank == 0 {
 			return vram.tileMaps[0][addr-tileMap0Start].GetTileID()
 		}
 		return vram.tileMaps[0][addr-tileMap0Start].GetTileAttr()
 	// Tile MAP 1
 	case addr >= tileMap1Start && addr <= tileMap1End:
-		if vram.bankFlag.Get() == 0 {
+		if bank == 0 {
 			return vram.tileMaps[1][addr-tileMap1Start].GetTileID()
 		}
 		return vram.tileMaps[1][addr-tileMap1Start].GetTileAttr()
 
 	case addr >= tileData1Start && addr <= tileData0End:
 		addr -= vramOffset
-		return vram.tiles[vram.bankFlag.Get()][addr>>4][addr&15]
+		return vram.tiles[bank][addr>>4][addr&15]
 	default:
 		log.Fatalf("GPU MEMORY UNREACHABLE : 0x%04X", addr)
 		return 0
 	}
 }
 
-func (vram *gbVRAM) Write(addr uint16, value uint8) {
+// write sets the value at addr in a bank, independently of VBK
+func (vram *gbVRAM) write(bank uint8, addr uint16, value uint8) {
 	switch {
 	// Tile MAP 0
 	case addr >= tileMap0Start && addr <= tileMap0End:
-		if vram.bankFlag.Get() == 0 {
+		if bank == 0 {
 			vram.tileMaps[0][addr-tileMap0Start].SetTileID(value)
 		} else {
 			vram.tileMaps[0][addr-tileMap0Start].SetTileAttr(value)
 		}
 	// Tile MAP 1
 	case addr >= tileMap1Start && addr <= tileMap1End:
-		if vram.bankFlag.Get() == 0 {
+		if bank == 0 {
 			vram.tileMaps[1][addr-tileMap1Start].SetTileID(value)
 		} else {
 			vram.tileMaps[1][addr-tileMap1Start].SetTileAttr(value)
@@ -74,7 +84,7 @@ func (vram *gbVRAM) Write(addr uint16, value uint8) {
 
 	case addr >= tileData1Start && addr <= tileData0End:
 		addr -= vramOffset
-		vram.tiles[vram.bankFlag.Get()][addr>>4][addr&15] = value
+		vram.tiles[bank][addr>>4][addr&15] = value
 	default:
 		log.Fatalf("GPU MEMORY UNREACHABLE : 0x%04X", addr)
 	}
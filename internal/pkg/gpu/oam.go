@@ -21,9 +21,27 @@ func (oam *oam) GetSprite(id uint8) *Sprite {
 }
 
 func (oam *oam) Read(addr uint16) uint8 {
-	// No reason to read sprites info, but if needed :
-	panic("TODO : READ SPRITE DATA")
-	// return oam.Data[addr-oamOffset]
+	sprite := &oam._sprites[(addr-oamOffset)>>2]
+	switch (addr - oamOffset) & 3 {
+	case 0:
+		return sprite.Y
+	case 1:
+		return sprite.X
+	case 2:
+		return sprite.TileID
+	default:
+		var value uint8
+		if sprite.ObjToBgPriority {
+			value |= 0x80
+		}
+		if sprite.YFlip {
+			value |= 0x40
+		}
+		if sprite.XFlip {
+			value |= 0x20
+		}
+		return value | sprite.PaletteNumber<<4 | sprite.BankNumber<<3 | sprite.ColorPalette
+	}
 }
 
 func (oam *oam) Write(addr uint16, value uint8) {
@@ -81,34 +99,3 @@ type Sprite struct {
 	ColorPalette uint8
 	// ***/CGB MODE ***
 }
-
-func (t *Sprite) GetPixels(vram *gbVRAM, line uint8, offset uint8, mode8x16 bool) []uint8 {
-	tileID := t.TileID
-	if t.YFlip {
-		if mode8x16 {
-			line = uint8((int16(line) - 15) * -1)
-		} else {
-			line = uint8((int16(line) - 7) * -1)
-		}
-	}
-
-	if mode8x16 {
-		if line > 7 {
-			tileID |= 0x01
-			line %= 8
-		} else {
-			tileID &= 0xFE
-		}
-	}
-	var buff [8]uint8
-	tilePixels := vram.GetTileData(true, tileID, t.BankNumber).appendPixelsLine(buff[:0], line, offset, 8, 0, false, false)
-	pixels := make([]uint8, len(tilePixels))
-	copy(pixels, tilePixels)
-	if t.XFlip {
-		for i := len(pixels)/2 - 1; i >= 0; i-- {
-			opp := len(pixels) - 1 - i
-			pixels[i], pixels[opp] = pixels[opp], pixels[i]
-		}
-	}
-	return pixels
-}
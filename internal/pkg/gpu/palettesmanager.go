@@ -2,8 +2,6 @@ package gpu
 
 import (
 	"log"
-
-	"github.com/jmontupet/gbcore/pkg/coreio"
 )
 
 // FF68 & FF6A : Bit 0-5   Index (00-3F)
@@ -21,32 +19,14 @@ type palettesManager struct {
 	spritePaletteData    [2 * 4 * 8]uint8 // 2 bytes * 4 colors * 8 palettes. Access via FF6B
 }
 
-func (pm *palettesManager) setPalettes(colors *coreio.FrameColors) {
-	if pm.cgb {
-		var palettePointer uint16 = 0b100000 * 3
-		for i := 0; i < 64; i += 2 {
-			var color uint16 = uint16(pm.bgPaletteData[i]) | uint16(pm.bgPaletteData[i+1])<<8
-			colors[palettePointer] = (uint8(color) & 0x1F) << 3
-			palettePointer++
-			colors[palettePointer] = (uint8(color>>5) & 0x1F) << 3
-			palettePointer++
-			colors[palettePointer] = (uint8(color>>10) & 0x1F) << 3
-			palettePointer++
-		}
-
-		palettePointer = 0b000000 * 3
-		for i := 0; i < 64; i += 2 {
-			var color uint16 = uint16(pm.spritePaletteData[i]) | uint16(pm.spritePaletteData[i+1])<<8
-			colors[palettePointer] = (uint8(color) & 0x1F) << 3
-			palettePointer++
-			colors[palettePointer] = (uint8(color>>5) & 0x1F) << 3
-			palettePointer++
-			colors[palettePointer] = (uint8(color>>10) & 0x1F) << 3
-			palettePointer++
-		}
-	} else {
-		// panic("TODO : NON CGB PALETTES")
+// color returns the RGB555 color of a pixel : Bit 5 BG (1) or OBJ (0), Bits 2-4 palette, Bits 0-1 color
+func (pm *palettesManager) color(pixel uint8) uint16 {
+	data := &pm.spritePaletteData
+	if pixel&0b100000 != 0 {
+		data = &pm.bgPaletteData
 	}
+	i := (pixel & 0x1F) * 2
+	return (uint16(data[i]) | uint16(data[i+1])<<8) & 0x7FFF
 }
 
 func (pm *palettesManager) Write(addr uint16, value uint8) {
@@ -56,36 +36,42 @@ func (pm *palettesManager) Write(addr uint16, value uint8) {
 		pm.bgPaletteAutoInc = value>>7 == 1
 	case 0xFF69:
 		pm.bgPaletteData[pm.bgPaletteIndex] = value
-		if pm.bgPaletteAutoInc {
-			pm.bgPaletteIndex = (pm.bgPaletteIndex + 1) & 0x3F
-		}
+		pm.skipWrite(addr)
 	case 0xFF6A:
 		pm.spritePaletteIndex = value & 0x3F
 		pm.spritePaletteAutoInc = value>>7 == 1
 	case 0xFF6B:
 		pm.spritePaletteData[pm.spritePaletteIndex] = value
-		if pm.spritePaletteAutoInc {
-			pm.spritePaletteIndex = (pm.spritePaletteIndex + 1) & 0x3F
-		}
+		pm.skipWrite(addr)
 	default:
 		log.Fatalf("MEMORY UNREACHABLE : 0x%04X", addr)
 	}
 }
 
+// skipWrite moves the index after a data write (FF69 or FF6B), also when the write is blocked
+func (pm *palettesManager) skipWrite(addr uint16) {
+	if addr == 0xFF69 && pm.bgPaletteAutoInc {
+		pm.bgPaletteIndex = (pm.bgPaletteIndex + 1) & 0x3F
+	}
+	if addr == 0xFF6B && pm.spritePaletteAutoInc {
+		pm.spritePaletteIndex = (pm.spritePaletteIndex + 1) & 0x3F
+	}
+}
+
 func (pm *palettesManager) Read(addr uint16) uint8 {
 	switch addr {
 	case 0xFF68:
 		if pm.bgPaletteAutoInc {
-			return pm.bgPaletteIndex & 0x80
+			return pm.bgPaletteIndex | 0xC0
 		}
-		return pm.bgPaletteIndex
+		return pm.bgPaletteIndex | 0x40 // Bit 6 unused
 	case 0xFF69:
 		return pm.bgPaletteData[pm.bgPaletteIndex]
 	case 0xFF6A:
 		if pm.spritePaletteAutoInc {
-			return pm.spritePaletteIndex & 0x80
+			return pm.spritePaletteIndex | 0xC0
 		}
-		return pm.spritePaletteIndex
+		return pm.spritePaletteIndex | 0x40 // Bit 6 unused
 	case 0xFF6B:
 		return pm.spritePaletteData[pm.spritePaletteIndex]
 	default:
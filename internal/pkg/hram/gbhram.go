@@ -20,6 +20,11 @@ func (hram *HRAM) Write(addr uint16, value uint8) {
 	hram._data[addr-AddrStart] = value
 }
 
+// Data returns the HRAM data without copy
+func (hram *HRAM) Data() []uint8 {
+	return hram._data[:]
+}
+
 // NewGBHRAM returns new HRAM implementation
 func NewGBHRAM() *HRAM {
 	return &HRAM{}
@@ -34,34 +34,38 @@ type Manager struct {
 	iFlag *ioports.Ptr
 }
 
-func (interrupt *Manager) GetNext() (jumpAddr uint16) {
-	if interrupt.masterFlag {
-		iFlag := interrupt.iFlag.Get()
-		validInterrupts := iFlag & interrupt.iEnable
-		switch {
-		case validInterrupts == 0:
-			return 0x0000
-		case validInterrupts&0x01 > 0: // V-Blank
-			interrupt.iFlag.SetBit0(false)
-			jumpAddr = 0x0040
-		case validInterrupts&0x02 > 0: // LCD STAT
-			interrupt.iFlag.SetBit1(false)
-			jumpAddr = 0x0048
-		case validInterrupts&0x04 > 0: // Timer
-			interrupt.iFlag.SetBit2(false)
-			jumpAddr = 0x0050
-		case validInterrupts&0x08 > 0: // Serial
-			interrupt.iFlag.SetBit3(false)
-			jumpAddr = 0x0058
-		case validInterrupts&0x10 > 0: // Joypad
-			interrupt.iFlag.SetBit4(false)
-			jumpAddr = 0x0060
-		}
-	}
-	if jumpAddr != 0x0000 {
-		interrupt.masterFlag = false
+// MasterEnabled returns the IME flag
+func (interrupt *Manager) MasterEnabled() bool {
+	return interrupt.masterFlag
+}
+
+// Pending returns true if an enabled interrupt is requested, regardless of IME.
+func (interrupt *Manager) Pending() bool {
+	return interrupt.iFlag.Get()&interrupt.iEnable&0x1F != 0
+}
+
+// Acknowledge clears the request of the highest priority pending interrupt and returns its jump address.
+// 0x0000 is returned if no interrupt is pending anymore (dispatch cancelled).
+func (interrupt *Manager) Acknowledge() (jumpAddr uint16) {
+	validInterrupts := interrupt.iFlag.Get() & interrupt.iEnable
+	switch {
+	case validInterrupts&0x01 > 0: // V-Blank
+		interrupt.iFlag.SetBit0(false)
+		jumpAddr = 0x0040
+	case validInterrupts&0x02 > 0: // LCD STAT
+		interrupt.iFlag.SetBit1(false)
+		jumpAddr = 0x0048
+	case validInterrupts&0x04 > 0: // Timer
+		interrupt.iFlag.SetBit2(false)
+		jumpAddr = 0x0050
+	case validInterrupts&0x08 > 0: // Serial
+		interrupt.iFlag.SetBit3(false)
+		jumpAddr = 0x0058
+	case validInterrupts&0x10 > 0: // Joypad
+		interrupt.iFlag.SetBit4(false)
+		jumpAddr = 0x0060
 	}
-	return jumpAddr // OxOOOO is not a valid interrupt jump address, used as 'NO INTERRUPT' for this function ONLY !!!
+	return jumpAddr
 }
 
 func (interrupt *Manager) Read(_ uint16) uint8 {
@@ -9,17 +9,28 @@ const (
 
 // IOPorts emulate Gameboy Color IO ports
 type IOPorts struct {
-	_data [0x80]uint8
+	_data     [0x80]uint8
+	readMasks *[0x80]uint8 // CGB or DMG compatibility mode
 }
 
+// Read returns the register value as seen by the CPU : unused bits are read as 1.
 func (io *IOPorts) Read(addr uint16) uint8 {
-	return io._data[addr-AddrStart]
+	return io._data[addr-AddrStart] | io.readMasks[addr-AddrStart]
 }
 
 func (io *IOPorts) Write(addr uint16, value uint8) {
 	io._data[addr-AddrStart] = value
 }
 
+// Data returns the raw registers values (unused bits not set) without copy
+func (io *IOPorts) Data() []uint8 {
+	return io._data[:]
+}
+
+// get & set give the raw register value to the components (ptr)
+func (io *IOPorts) get(addr uint16) uint8        { return io._data[addr-AddrStart] }
+func (io *IOPorts) set(addr uint16, value uint8) { io._data[addr-AddrStart] = value }
+
 func (io *IOPorts) NewPtr(addr uint16) *Ptr {
 	return &Ptr{
 		addr: addr,
@@ -47,6 +58,11 @@ func (io *IOPorts) NewBit6Ptr(addr uint16) *BitPtr { return &BitPtr{io, addr, 0x
 func (io *IOPorts) NewBit7Ptr(addr uint16) *BitPtr { return &BitPtr{io, addr, 0x80} }
 
 // NewGBIOPorts create new IOPorts, basicaly a simple Memory implementation.
-func NewGBIOPorts() *IOPorts {
-	return &IOPorts{}
+// cgb false : DMG compatibility mode, the CGB registers are read as 0xFF.
+func NewGBIOPorts(cgb bool) *IOPorts {
+	io := &IOPorts{readMasks: &dmgReadMasks}
+	if cgb {
+		io.readMasks = &readMasks
+	}
+	return io
 }
@@ -0,0 +1,52 @@
+package ioports
+
+import "testing"
+
+func TestReadMasks(t *testing.T) {
+	io := NewGBIOPorts(true)
+	tests := []struct {
+		addr     uint16
+		value    uint8
+		expected uint8
+	}{
+		{0xFF03, 0x00, 0xFF}, // Unmapped
+		{0xFF0F, 0x01, 0xE1}, // IF
+		{0xFF41, 0x05, 0x85}, // STAT
+		{0xFF13, 0x12, 0xFF}, // NR13 write only
+		{0xFF26, 0x80, 0xF0}, // NR52
+		{0xFF30, 0x12, 0x12}, // Wave RAM
+		{0xFF42, 0x34, 0x34}, // SCY
+		{0xFF4F, 0x00, 0xFE}, // VBK
+		{0xFF70, 0x02, 0xFA}, // SVBK
+	}
+	for _, test := range tests {
+		io.Write(test.addr, test.value)
+		if got := io.Read(test.addr); got != test.expected {
+			t.Errorf("0x%04X : expected 0x%02X, got 0x%02X", test.addr, test.expected, got)
+		}
+	}
+
+	// Components see the raw value
+	io.Write(0xFF0F, 0x01)
+	if got := io.NewPtr(0xFF0F).Get(); got != 0x01 {
+		t.Errorf("IF ptr : expected 0x01, got 0x%02X", got)
+	}
+}
+
+// TestDMGReadMasks checks the CGB registers are read as 0xFF in DMG compatibility mode
+func TestDMGReadMasks(t *testing.T) {
+	io := NewGBIOPorts(false)
+	for _, addr := range []uint16{0xFF4D, 0xFF4F, 0xFF55, 0xFF68, 0xFF69, 0xFF6A, 0xFF6B, 0xFF70} {
+		io.Write(addr, 0x00)
+		if got := io.Read(addr); got != 0xFF {
+			t.Errorf("0x%04X : expected 0xFF, got 0x%02X", addr, got)
+		}
+	}
+	io.Write(0xFF41, 0x05)
+	if got := io.Read(0xFF41); got != 0x85 {
+		t.Errorf("STAT : expected 0x85, got 0x%02X", got)
+	}
+	if got := NewGBIOPorts(true).Read(0xFF68); got != 0x40 {
+		t.Errorf("CGB BCPS : expected 0x40, got 0x%02X", got)
+	}
+}
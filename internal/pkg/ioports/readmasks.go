@@ -0,0 +1,103 @@
+package ioports
+
+// readMasks contains, for each IO register, the bits always read as 1 by the CPU in CGB mode.
+//
+// Unmapped registers and write-only bits are read as 1 (open bus).
+var readMasks = [0x80]uint8{
+	0x00: 0xC0, // P1   - Joypad (bits 6-7 unused)
+	0x01: 0x00, // SB   - Serial transfer data
+	0x02: 0x7C, // SC   - Serial transfer control (bits 2-6 unused)
+	0x03: 0xFF,
+	0x04: 0x00, // DIV  - Divider
+	0x05: 0x00, // TIMA - Timer counter
+	0x06: 0x00, // TMA  - Timer modulo
+	0x07: 0xF8, // TAC  - Timer control (bits 3-7 unused)
+	0x08: 0xFF, 0x09: 0xFF, 0x0A: 0xFF, 0x0B: 0xFF, 0x0C: 0xFF, 0x0D: 0xFF, 0x0E: 0xFF,
+	0x0F: 0xE0, // IF   - Interrupt flag (bits 5-7 unused)
+
+	0x10: 0x80, // NR10 - Channel 1 sweep
+	0x11: 0x3F, // NR11 - Channel 1 length (write only) / duty
+	0x12: 0x00, // NR12 - Channel 1 envelope
+	0x13: 0xFF, // NR13 - Channel 1 frequency lo (write only)
+	0x14: 0xBF, // NR14 - Channel 1 frequency hi (only bit 6 readable)
+	0x15: 0xFF,
+	0x16: 0x3F, // NR21 - Channel 2 length (write only) / duty
+	0x17: 0x00, // NR22 - Channel 2 envelope
+	0x18: 0xFF, // NR23 - Channel 2 frequency lo (write only)
+	0x19: 0xBF, // NR24 - Channel 2 frequency hi (only bit 6 readable)
+	0x1A: 0x7F, // NR30 - Channel 3 on/off
+	0x1B: 0xFF, // NR31 - Channel 3 length (write only)
+	0x1C: 0x9F, // NR32 - Channel 3 output level
+	0x1D: 0xFF, // NR33 - Channel 3 frequency lo (write only)
+	0x1E: 0xBF, // NR34 - Channel 3 frequency hi (only bit 6 readable)
+	0x1F: 0xFF,
+	0x20: 0xFF, // NR41 - Channel 4 length (write only)
+	0x21: 0x00, // NR42 - Channel 4 envelope
+	0x22: 0x00, // NR43 - Channel 4 polynomial counter
+	0x23: 0xBF, // NR44 - Channel 4 counter/consecutive (only bit 6 readable)
+	0x24: 0x00, // NR50 - Channel control / volume
+	0x25: 0x00, // NR51 - Sound output terminal selection
+	0x26: 0x70, // NR52 - Sound on/off (bits 4-6 unused)
+	0x27: 0xFF, 0x28: 0xFF, 0x29: 0xFF, 0x2A: 0xFF, 0x2B: 0xFF, 0x2C: 0xFF, 0x2D: 0xFF, 0x2E: 0xFF, 0x2F: 0xFF,
+	// 0x30 - 0x3F : Wave pattern RAM
+
+	0x40: 0x00, // LCDC - LCD control
+	0x41: 0x80, // STAT - LCD status (bit 7 unused)
+	0x42: 0x00, // SCY
+	0x43: 0x00, // SCX
+	0x44: 0x00, // LY
+	0x45: 0x00, // LYC
+	0x46: 0x00, // DMA
+	0x47: 0x00, // BGP
+	0x48: 0x00, // OBP0
+	0x49: 0x00, // OBP1
+	0x4A: 0x00, // WY
+	0x4B: 0x00, // WX
+	0x4C: 0xFF,
+	0x4D: 0x7E, // KEY1 - Prepare speed switch (bits 1-6 unused)
+	0x4E: 0xFF,
+	0x4F: 0xFE, // VBK  - VRAM bank (bits 1-7 unused)
+	0x50: 0xFF, // Boot ROM disable (write only)
+	0x51: 0xFF, // HDMA1 - Write only
+	0x52: 0xFF, // HDMA2 - Write only
+	0x53: 0xFF, // HDMA3 - Write only
+	0x54: 0xFF, // HDMA4 - Write only
+	0x55: 0x00, // HDMA5
+	0x56: 0x3C, // RP   - Infrared port (bits 2-5 unused)
+	0x57: 0xFF, 0x58: 0xFF, 0x59: 0xFF, 0x5A: 0xFF, 0x5B: 0xFF, 0x5C: 0xFF, 0x5D: 0xFF, 0x5E: 0xFF, 0x5F: 0xFF,
+	0x60: 0xFF, 0x61: 0xFF, 0x62: 0xFF, 0x63: 0xFF, 0x64: 0xFF, 0x65: 0xFF, 0x66: 0xFF, 0x67: 0xFF,
+	0x68: 0x40, // BCPS - Background palette index (bit 6 unused)
+	0x69: 0x00, // BCPD - Background palette data
+	0x6A: 0x40, // OCPS - Sprite palette index (bit 6 unused)
+	0x6B: 0x00, // OCPD - Sprite palette data
+	0x6C: 0xFE, // OPRI - Object priority mode (bits 1-7 unused)
+	0x6D: 0xFF, 0x6E: 0xFF, 0x6F: 0xFF,
+	0x70: 0xF8, // SVBK - WRAM bank (bits 3-7 unused)
+	0x71: 0xFF,
+	0x72: 0x00, // Undocumented (read/write)
+	0x73: 0x00, // Undocumented (read/write)
+	0x74: 0x00, // Undocumented (read/write)
+	0x75: 0x8F, // Undocumented (bits 4-6 read/write)
+	0x76: 0x00, // PCM12 - Channels 1 & 2 amplitudes (read only)
+	0x77: 0x00, // PCM34 - Channels 3 & 4 amplitudes (read only)
+	0x78: 0xFF, 0x79: 0xFF, 0x7A: 0xFF, 0x7B: 0xFF, 0x7C: 0xFF, 0x7D: 0xFF, 0x7E: 0xFF, 0x7F: 0xFF,
+}
+
+// dmgReadMasks contains the read masks in DMG compatibility mode (CGB running a DMG cartridge) :
+// the CGB only registers are read as 0xFF.
+var dmgReadMasks = readMasks
+
+func init() {
+	for _, addr := range [...]uint16{
+		0xFF4D, // KEY1
+		0xFF4F, // VBK
+		0xFF55, // HDMA5
+		0xFF68, // BCPS
+		0xFF69, // BCPD
+		0xFF6A, // OCPS
+		0xFF6B, // OCPD
+		0xFF70, // SVBK
+	} {
+		dmgReadMasks[addr-AddrStart] = 0xFF
+	}
+}
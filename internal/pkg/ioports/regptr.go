@@ -6,12 +6,12 @@ type Ptr struct {
 }
 
 func (ptr *Ptr) Get() uint8 {
-	return ptr.io.Read(ptr.addr)
+	return ptr.io.get(ptr.addr)
 }
 func (ptr *Ptr) Set(value uint8) {
-	ptr.io.Write(ptr.addr, value)
+	ptr.io.set(ptr.addr, value)
 }
-func (ptr *Ptr) getBitN(mask uint8) bool { return ptr.io.Read(ptr.addr)&mask != 0 }
+func (ptr *Ptr) getBitN(mask uint8) bool { return ptr.io.get(ptr.addr)&mask != 0 }
 
 func (ptr *Ptr) GetBit0() bool { return ptr.getBitN(0x01) }
 func (ptr *Ptr) GetBit1() bool { return ptr.getBitN(0x02) }
@@ -24,9 +24,9 @@ func (ptr *Ptr) GetBit7() bool { return ptr.getBitN(0x80) }
 
 func (ptr *Ptr) setBitN(set bool, mask uint8) {
 	if set {
-		ptr.io.Write(ptr.addr, ptr.io.Read(ptr.addr)|mask)
+		ptr.io.set(ptr.addr, ptr.io.get(ptr.addr)|mask)
 	} else {
-		ptr.io.Write(ptr.addr, ptr.io.Read(ptr.addr) & ^mask)
+		ptr.io.set(ptr.addr, ptr.io.get(ptr.addr) & ^mask)
 	}
 }
 func (ptr *Ptr) SetBit0(set bool) { ptr.setBitN(set, 0x01) }
@@ -53,7 +53,8 @@ func (j *Joypad) UpdateInput(input uint8) {
 }
 
 func (j *Joypad) Read(_ uint16) uint8 { // addr = 0xFF00
-	result := j._memory&selectMask | 0x0F
+	// Bits 6-7 unused : read as 1
+	result := j._memory&selectMask | 0xCF
 	if j._memory&buttonMask == 0 { // Button selected
 		result &= j.hwButton
 	}
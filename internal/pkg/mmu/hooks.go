@@ -0,0 +1,162 @@
+package mmu
+
+import (
+	"github.com/jmontupet/gbcore/pkg/coreio"
+)
+
+// Kinds of hooks, also used as flags in the pages mask
+const (
+	hookRead uint8 = 1 << iota
+	hookWrite
+	hookExec
+)
+
+// busHook intercepts the CPU accesses in [start, end]
+type busHook struct {
+	id         int
+	kind       uint8
+	start, end uint16
+	read       coreio.ReadHook
+	write      coreio.WriteHook
+	exec       coreio.ExecHook
+}
+
+// hooks is the list of bus hooks and the kinds of hooks of each 256 bytes page.
+// Without hooks on a page, an access only costs a mask check.
+type hooks struct {
+	list   []busHook
+	pages  [256]uint8
+	nextID int
+
+	accessHooks []int // Hooks registered by SetAccessHook
+}
+
+// AddReadHook registers a hook called after each CPU read (opcode fetches included) in [start, end].
+// It returns the hook id (RemoveHook). The hooks of an address are chained in registration order.
+func (m *MMU) AddReadHook(start, end uint16, hook coreio.ReadHook) int {
+	return m.addHook(busHook{kind: hookRead, start: start, end: end, read: hook})
+}
+
+// AddWriteHook registers a hook called before each CPU write in [start, end].
+// It returns the hook id (RemoveHook). The hooks of an address are chained in registration order.
+func (m *MMU) AddWriteHook(start, end uint16, hook coreio.WriteHook) int {
+	return m.addHook(busHook{kind: hookWrite, start: start, end: end, write: hook})
+}
+
+// AddExecHook registers a hook called on each opcode fetch in [start, end].
+// It returns the hook id (RemoveHook).
+func (m *MMU) AddExecHook(start, end uint16, hook coreio.ExecHook) int {
+	return m.addHook(busHook{kind: hookExec, start: start, end: end, exec: hook})
+}
+
+// RemoveHook unregisters a hook. It returns false if id is unknown.
+// A hook can remove itself (or another hook) while running.
+func (m *MMU) RemoveHook(id int) bool {
+	for i, h := range m.hooks.list {
+		if h.id == id {
+			// New list : the running hooks iterate over the current one
+			list := make([]busHook, 0, len(m.hooks.list)-1)
+			list = append(list, m.hooks.list[:i]...)
+			m.hooks.list = append(list, m.hooks.list[i+1:]...)
+			m.updateHookPages()
+			return true
+		}
+	}
+	return false
+}
+
+// Observed returns true if the CPU accesses are observed : bus hooks or code/data log
+func (m *MMU) Observed() bool {
+	return len(m.hooks.list) > 0 || m.codeDataLog != nil
+}
+
+// SetAccessHook registers the function called on each CPU memory access (nil to disable) :
+// after the reads and before the writes. It replaces the previous access hook.
+// It runs ahead of the bus hooks and observes the raw accesses : the values read from memory
+// (not overridden by read hooks) and the writes cancelled or overridden by write hooks.
+// Like the bus hooks, it disables the skipping of idle loops.
+func (m *MMU) SetAccessHook(hook func(addr uint16, value uint8, write bool)) {
+	for _, id := range m.hooks.accessHooks {
+		m.RemoveHook(id)
+	}
+	m.hooks.accessHooks = nil
+	if hook == nil {
+		return
+	}
+	m.hooks.accessHooks = []int{
+		m.addHookFirst(busHook{kind: hookRead, start: 0x0000, end: 0xFFFF, read: func(addr uint16, value uint8) uint8 {
+			hook(addr, value, false)
+			return value
+		}}),
+		m.addHookFirst(busHook{kind: hookWrite, start: 0x0000, end: 0xFFFF, write: func(addr uint16, value uint8) (uint8, bool) {
+			hook(addr, value, true)
+			return value, true
+		}}),
+	}
+}
+
+func (m *MMU) addHook(h busHook) int {
+	m.hooks.nextID++
+	h.id = m.hooks.nextID
+	m.hooks.list = append(m.hooks.list, h)
+	m.updateHookPages()
+	return h.id
+}
+
+// addHookFirst registers h ahead of the other hooks
+func (m *MMU) addHookFirst(h busHook) int {
+	m.hooks.nextID++
+	h.id = m.hooks.nextID
+	// New list : the running hooks iterate over the current one
+	list := make([]busHook, 0, len(m.hooks.list)+1)
+	m.hooks.list = append(append(list, h), m.hooks.list...)
+	m.updateHookPages()
+	return h.id
+}
+
+// updateHookPages rebuilds the kinds of hooks of each page
+func (m *MMU) updateHookPages() {
+	m.hooks.pages = [256]uint8{}
+	for _, h := range m.hooks.list {
+		for page := int(h.start >> 8); page <= int(h.end>>8); page++ {
+			m.hooks.pages[page] |= h.kind
+		}
+	}
+}
+
+// hooked returns true if hooks of kind may intercept addr
+func (m *MMU) hooked(addr uint16, kind uint8) bool {
+	return m.hooks.pages[addr>>8]&kind != 0
+}
+
+// onRead runs the read hooks of addr and returns the value seen by the CPU
+func (m *MMU) onRead(addr uint16, value uint8) uint8 {
+	for _, h := range m.hooks.list {
+		if h.kind == hookRead && addr >= h.start && addr <= h.end {
+			value = h.read(addr, value)
+		}
+	}
+	return value
+}
+
+// onWrite runs the write hooks of addr and returns the value to write. ok is false if the write is cancelled.
+func (m *MMU) onWrite(addr uint16, value uint8) (uint8, bool) {
+	for _, h := range m.hooks.list {
+		if h.kind == hookWrite && addr >= h.start && addr <= h.end {
+			var ok bool
+			if value, ok = h.write(addr, value); !ok {
+				return value, false
+			}
+		}
+	}
+	return value, true
+}
+
+// onExec runs the exec hooks of addr
+func (m *MMU) onExec(addr uint16) {
+	for _, h := range m.hooks.list {
+		if h.kind == hookExec && addr >= h.start && addr <= h.end {
+			h.exec(addr)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package mmu
+
+import (
+	"testing"
+
+	"github.com/jmontupet/gbcore/internal/pkg/cartridge"
+	"github.com/jmontupet/gbcore/internal/pkg/gpu"
+	"github.com/jmontupet/gbcore/internal/pkg/hram"
+	"github.com/jmontupet/gbcore/internal/pkg/interrupt"
+	"github.com/jmontupet/gbcore/internal/pkg/ioports"
+	"github.com/jmontupet/gbcore/internal/pkg/joypad"
+	"github.com/jmontupet/gbcore/internal/pkg/serial"
+	"github.com/jmontupet/gbcore/internal/pkg/timers"
+	"github.com/jmontupet/gbcore/internal/pkg/unusableaddr"
+	"github.com/jmontupet/gbcore/internal/pkg/wram"
+	"github.com/jmontupet/gbcore/pkg/nullio"
+)
+
+func newTestMMU(t testing.TB) *MMU {
+	rom := make([]byte, 0x8000)
+	rom[0x0150] = 0x3C
+	return newTestMMUROM(t, rom, false)
+}
+
+func newTestMMUROM(t testing.TB, rom []byte, cgb bool) *MMU {
+	cart, err := cartridge.NewCartridge(rom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io := ioports.NewGBIOPorts(cgb)
+	return NewMMU(
+		cart,
+		gpu.NewGBGPU(io, nullio.NewNullFrameDrawer(), cgb),
+		io,
+		hram.NewGBHRAM(),
+		wram.NewWram(io),
+		interrupt.NewInterrupt(io),
+		joypad.NewJoypad(io),
+		serial.NewSerial(io),
+		timers.NewTimers(io),
+		unusableaddr.NewUnusableAddr(),
+	)
+}
+
+func TestReadHooks(t *testing.T) {
+	m := newTestMMU(t)
+	m.Write(0xC000, 0x10)
+	m.Write(0xC001, 0x20)
+
+	// Chained : +1 then *2
+	inc := m.AddReadHook(0xC000, 0xC000, func(addr uint16, value uint8) uint8 { return value + 1 })
+	m.AddReadHook(0xC000, 0xC0FF, func(addr uint16, value uint8) uint8 { return value * 2 })
+	if got := m.Read(0xC000); got != 0x22 {
+		t.Errorf("expected 0x22, got 0x%02X", got)
+	}
+	if got := m.Read(0xC001); got != 0x40 {
+		t.Errorf("expected 0x40, got 0x%02X", got)
+	}
+	if got := m.Read(0xC100); got != 0x00 {
+		t.Errorf("read outside of the hooks range : 0x%02X", got)
+	}
+	if got := m.Peek(0xC000); got != 0x10 {
+		t.Errorf("Peek hooked : 0x%02X", got)
+	}
+
+	if !m.RemoveHook(inc) || m.RemoveHook(inc) {
+		t.Error("RemoveHook : hook not removed once")
+	}
+	if got := m.Read(0xC000); got != 0x20 {
+		t.Errorf("expected 0x20 after RemoveHook, got 0x%02X", got)
+	}
+}
+
+func TestWriteHooks(t *testing.T) {
+	m := newTestMMU(t)
+	m.AddWriteHook(0xC000, 0xC0FF, func(addr uint16, value uint8) (uint8, bool) {
+		return 0x99, addr != 0xC001 // Locked address
+	})
+	m.Write(0xC000, 0x10)
+	m.Write(0xC001, 0x10)
+	if got := m.Peek(0xC000); got != 0x99 {
+		t.Errorf("expected overridden value 0x99, got 0x%02X", got)
+	}
+	if got := m.Peek(0xC001); got != 0x00 {
+		t.Errorf("expected cancelled write, got 0x%02X", got)
+	}
+}
+
+func TestExecHooks(t *testing.T) {
+	m := newTestMMU(t)
+	var executed []uint16
+	var id int
+	id = m.AddExecHook(0x0100, 0x01FF, func(addr uint16) {
+		executed = append(executed, addr)
+		m.RemoveHook(id)
+	})
+	m.Fetch(0x0150, false) // Operand
+	if got := m.Fetch(0x0150, true); got != 0x3C {
+		t.Errorf("expected opcode 0x3C, got 0x%02X", got)
+	}
+	m.Fetch(0x0151, true) // Hook removed
+	if len(executed) != 1 || executed[0] != 0x0150 {
+		t.Errorf("expected [0x0150], got %v", executed)
+	}
+}
+
+func TestAccessHook(t *testing.T) {
+	m := newTestMMU(t)
+	var accesses int
+	hook := func(addr uint16, value uint8, write bool) { accesses++ }
+	m.SetAccessHook(hook)
+	m.SetAccessHook(hook) // Replaced, not added
+	m.Write(0xC000, 0x01)
+	m.Read(0xC000)
+	if accesses != 2 {
+		t.Errorf("expected 2 accesses, got %d", accesses)
+	}
+	m.SetAccessHook(nil)
+	m.Read(0xC000)
+	if accesses != 2 || len(m.hooks.list) != 0 {
+		t.Errorf("access hook not removed")
+	}
+}
+
+// TestAccessHookRaw checks the access hook observes the CPU accesses ahead of the bus hooks
+func TestAccessHookRaw(t *testing.T) {
+	m := newTestMMU(t)
+	m.Write(0xC000, 0x10)
+	m.AddReadHook(0xC000, 0xC000, func(addr uint16, value uint8) uint8 { return 0x99 })
+	m.AddWriteHook(0xC001, 0xC001, func(addr uint16, value uint8) (uint8, bool) { return value, false })
+	var values []uint8
+	m.SetAccessHook(func(addr uint16, value uint8, write bool) { values = append(values, value) })
+
+	if got := m.Read(0xC000); got != 0x99 {
+		t.Errorf("read hook : 0x%02X, expected 0x99", got)
+	}
+	m.Write(0xC001, 0x20) // Cancelled
+	if len(values) != 2 || values[0] != 0x10 || values[1] != 0x20 {
+		t.Errorf("accesses % X, expected 10 20", values)
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	m := newTestMMU(b)
+	b.Run("NoHooks", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m.Read(0xC000 + uint16(i&0xFF))
+		}
+	})
+	m.AddReadHook(0xFF80, 0xFFFE, func(addr uint16, value uint8) uint8 { return value })
+	b.Run("OtherPageHooked", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m.Read(0xC000 + uint16(i&0xFF))
+		}
+	})
+	m.AddReadHook(0xC000, 0xC0FF, func(addr uint16, value uint8) uint8 { return value })
+	b.Run("Hooked", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m.Read(0xC000 + uint16(i&0xFF))
+		}
+	})
+}
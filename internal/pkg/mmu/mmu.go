@@ -1,59 +1,155 @@
 package mmu
 
 import (
+	"github.com/jmontupet/gbcore/internal/pkg/cartridge"
 	"github.com/jmontupet/gbcore/internal/pkg/hram"
+	"github.com/jmontupet/gbcore/internal/pkg/mmu/memorymap"
 
 	"github.com/jmontupet/gbcore/internal/pkg/unusableaddr"
 
 	"github.com/jmontupet/gbcore/internal/pkg/gpu"
 
 	"github.com/jmontupet/gbcore/internal/pkg/joypad"
+	"github.com/jmontupet/gbcore/internal/pkg/serial"
+	"github.com/jmontupet/gbcore/internal/pkg/timers"
 	"github.com/jmontupet/gbcore/internal/pkg/wram"
 
 	"github.com/jmontupet/gbcore/internal/pkg/interrupt"
 	"github.com/jmontupet/gbcore/internal/pkg/ioports"
-	"github.com/jmontupet/gbcore/internal/pkg/memory"
 )
 
 type MMU struct {
-	cartridge    memory.Memory
+	cartridge    cartridge.Cartridge
 	gpu          *gpu.GPU
 	io           *ioports.IOPorts
+	key1         *ioports.MaskedPtr // Only the prepare bit is writable
 	hram         *hram.HRAM
 	wram         *wram.WRam
 	mirrorWram   *wram.TranslatedWram
 	interrupt    *interrupt.Manager
 	joypad       *joypad.Joypad
+	serial       *serial.Serial
+	timers       *timers.Timers
 	unusableAddr *unusableaddr.UnusableAddr
 	oamDMA       *OamDmaManager
 	vramDMA      *VramDmaManager
+
+	pages       pageTable // Memory directly accessed
+	hooks       hooks     // Bus hooks
+	codeDataLog []uint8   // ROM flags (cdl package), nil when disabled
+
+	sync func() // Brings the components up to date, nil when not clocked by a scheduler
 }
 
 func (mmu *MMU) GetOamDMA() *OamDmaManager   { return mmu.oamDMA }
 func (mmu *MMU) GetVramDMA() *VramDmaManager { return mmu.vramDMA }
 
+// Peek returns the value at addr without CPU restrictions (OAM DMA), side effects nor hooks.
+func (m *MMU) Peek(addr uint16) uint8 {
+	m.syncIO(addr)
+	return m.read(addr)
+}
+
+// Poke sets the value at addr without CPU restrictions (OAM DMA) nor hooks.
+// Writes to ROM or IO registers have the same effects as CPU writes (bank switching...).
+func (m *MMU) Poke(addr uint16, value uint8) {
+	m.syncIO(addr)
+	m.write(addr, value)
+	m.syncIO(addr)
+}
+
+// SetSync registers the function bringing the components up to date (scheduler).
+// It is called before the accesses to the IO registers and IE, and after the writes : a register
+// can only be seen or changed once the component is up to date, and a change reschedules its events.
+func (m *MMU) SetSync(sync func()) {
+	m.sync = sync
+}
+
+// syncIO brings the components up to date if addr is a register (HRAM excluded)
+func (m *MMU) syncIO(addr uint16) {
+	if addr >= ioports.AddrStart && (addr < hram.AddrStart || addr > hram.AddrEnd) && m.sync != nil {
+		m.sync()
+	}
+}
+
+// blocked returns true if the GPU blocks the CPU access to addr : VRAM, OAM or CGB palettes data while
+// the LCD draws. The components are synced before the VRAM and OAM accesses : the GPU mode must be current.
+func (m *MMU) blocked(addr uint16) bool {
+	switch {
+	case addr >= memorymap.VRamStart && addr <= memorymap.VRamEnd,
+		addr >= memorymap.OAMStart && addr <= memorymap.OAMEnd:
+		if m.sync != nil {
+			m.sync()
+		}
+	case addr != 0xFF69 && addr != 0xFF6B: // Palettes data : IO registers, already synced
+		return false
+	}
+	return m.gpu.Blocked(addr)
+}
+
+// SetCodeDataLog registers the ROM flags updated on each CPU or DMA access to the ROM (nil to disable).
+// log is indexed by ROM offset, see the cdl package.
+func (m *MMU) SetCodeDataLog(log []uint8) {
+	m.codeDataLog = log
+}
+
+// logROM flags the ROM byte mapped at addr in the code/data log
+func (m *MMU) logROM(addr uint16, flag uint8) {
+	if addr > memorymap.SwitchableRomEnd {
+		return
+	}
+	offset := m.cartridge.ROMBank(addr)*0x4000 + uint(addr&0x3FFF)
+	if offset < uint(len(m.codeDataLog)) {
+		m.codeDataLog[offset] |= flag
+	}
+}
+
+// Bank returns the bank currently mapped at addr (0 for unbanked areas).
+func (m *MMU) Bank(addr uint16) uint {
+	switch {
+	case addr >= memorymap.FixedRomStart && addr <= memorymap.SwitchableRomEnd:
+		return m.cartridge.ROMBank(addr)
+	case addr >= memorymap.VRamStart && addr <= memorymap.VRamEnd:
+		return m.gpu.VRAMBank()
+	case addr >= memorymap.ExternalRamStart && addr <= memorymap.ExternalRamEnd:
+		return m.cartridge.RAMBank()
+	case addr >= wram.BankedWRamStart && addr <= wram.WRamEnd:
+		return m.wram.Bank()
+	default:
+		return 0
+	}
+}
+
 func NewMMU(
-	cart memory.Memory,
+	cart cartridge.Cartridge,
 	gpu *gpu.GPU,
 	io *ioports.IOPorts,
 	hram *hram.HRAM,
 	ram *wram.WRam,
 	interrupt *interrupt.Manager,
 	joypad *joypad.Joypad,
+	serial *serial.Serial,
+	timers *timers.Timers,
 	unusableAddr *unusableaddr.UnusableAddr,
 ) *MMU {
 	mmu := &MMU{
 		cartridge:    cart,
 		gpu:          gpu,
 		io:           io,
+		key1:         io.NewMaskedPtr(0xFF4D, 0x01),
 		hram:         hram,
 		wram:         ram,
 		mirrorWram:   wram.NewTranlatedWram(ram),
 		interrupt:    interrupt,
 		joypad:       joypad,
+		serial:       serial,
+		timers:       timers,
 		unusableAddr: unusableAddr,
 	}
 	mmu.oamDMA = &OamDmaManager{mmu: mmu}
 	mmu.vramDMA = &VramDmaManager{mmu: mmu}
+	mmu.mapHandlers()
+	mmu.mapCartridge()
+	mmu.mapWRAM()
 	return mmu
 }
@@ -3,9 +3,17 @@ package mmu
 import (
 	"log"
 
+	"github.com/jmontupet/gbcore/internal/pkg/codedatalog"
 	"github.com/jmontupet/gbcore/internal/pkg/mmu/memorymap"
+	"github.com/jmontupet/gbcore/internal/pkg/scheduler"
 )
 
+// DMA INFO :
+// - 160 bytes to transfer (40 sprites * 4 bytes)
+// - 1 byte per machine cycle
+// - Transfer starts 1 machine cycle after the write in FF46
+const oamSize uint16 = memorymap.OAMEnd - memorymap.OAMStart + 1
+
 type OamDmaManager struct {
 	_dmaRegister uint8
 
@@ -13,13 +21,15 @@ type OamDmaManager struct {
 
 	transferSrc    uint16
 	transferActive bool
-	transferDst    uint16
+	transferIndex  uint16
+
+	// Transfer requested, started on next machine cycle
+	startPending bool
+	startSrc     uint16
 }
 
 func (odma *OamDmaManager) Read(addr uint16) uint8 {
 	switch {
-	case odma.transferActive:
-		return 0xFF
 	case addr == 0xFF46:
 		return odma._dmaRegister
 	default:
@@ -28,36 +38,54 @@ func (odma *OamDmaManager) Read(addr uint16) uint8 {
 	}
 }
 
+// Write in FF46 starts a new transfer, or restarts the current one.
 func (odma *OamDmaManager) Write(addr uint16, value uint8) {
 	switch {
-	case odma.transferActive:
 	case addr == 0xFF46:
-		odma.transferSrc = uint16(value) << 8
-		odma.transferDst = 0xFE00
-		odma.transferActive = true
 		odma._dmaRegister = value
+		odma.startSrc = uint16(value) << 8
+		if odma.startSrc >= 0xE000 { // FE00-FFFF sources read WRAM (DE00-DFFF)
+			odma.startSrc -= 0x2000
+		}
+		odma.startPending = true
 	default:
 		log.Fatalf("MEMORY UNREACHABLE : 0x%04X", addr)
 	}
 }
 
-func (odma *OamDmaManager) Tick(cycles uint8) {
-	if !odma.transferActive {
-		return
+// Advance runs the transfer for n machine cycles
+func (odma *OamDmaManager) Advance(n uint64) {
+	for ; n > 0 && (odma.transferActive || odma.startPending); n-- {
+		odma.tick()
 	}
-	nbByte := memorymap.OAMEnd - odma.transferDst
-	addrSrc := odma.transferSrc
-	addrDst := odma.transferDst
-	if nbByte > uint16(cycles) {
-		nbByte = uint16(cycles)
+}
+
+// NextEvent returns 1 while a transfer is pending or active : each byte transferred is an event
+func (odma *OamDmaManager) NextEvent() uint64 {
+	if odma.transferActive || odma.startPending {
+		return 1
 	}
-	odma.transferDst += nbByte
-	odma.transferSrc += nbByte
-	if odma.transferDst >= memorymap.OAMEnd {
-		odma.transferActive = false
+	return scheduler.Never
+}
+
+// tick transfers one byte per machine cycle
+func (odma *OamDmaManager) tick() {
+	if odma.transferActive {
+		src := odma.transferSrc + odma.transferIndex
+		value := odma.mmu.read(src)
+		if odma.mmu.codeDataLog != nil {
+			odma.mmu.logROM(src, codedatalog.DMA)
+		}
+		odma.mmu.write(memorymap.OAMStart+odma.transferIndex, value)
+		odma.transferIndex++
+		if odma.transferIndex >= oamSize {
+			odma.transferActive = false
+		}
 	}
-	for i := uint16(0); i < nbByte; i++ {
-		odma.mmu.Write(addrDst+i, odma.mmu.Read(addrSrc+i))
+	if odma.startPending {
+		odma.startPending = false
+		odma.transferSrc = odma.startSrc
+		odma.transferIndex = 0
+		odma.transferActive = true
 	}
-	return
 }
@@ -0,0 +1,75 @@
+package mmu
+
+import (
+	"github.com/jmontupet/gbcore/internal/pkg/memory"
+	"github.com/jmontupet/gbcore/internal/pkg/mmu/memorymap"
+)
+
+// pageTable maps each 256 bytes page of the address space to the memory directly accessed (ROM, cartridge RAM, WRAM),
+// or to the handlers of the components when the page is nil (VRAM, OAM, IO registers, disabled RAM...).
+// The direct pages are updated when the banks are switched : cartridge writes and SVBK.
+// HRAM shares its page with the IO registers : it is mapped on its own.
+type pageTable struct {
+	read  [256][]uint8
+	write [256][]uint8
+	hram  []uint8 // FF80-FFFE
+
+	handlers [256]memory.Memory
+}
+
+// mapHandlers sets the component handling each page
+func (m *MMU) mapHandlers() {
+	handle := func(start, end uint16, handler memory.Memory) {
+		for page := int(start >> 8); page <= int(end>>8); page++ {
+			m.pages.handlers[page] = handler
+		}
+	}
+	////// Cartridge bank 00 + Cartridge bank 01~NN //////
+	handle(memorymap.FixedRomStart, memorymap.SwitchableRomEnd, mbcArea{m})
+	////// VRAM  Switchable 0~1 on CGB//////
+	handle(memorymap.VRamStart, memorymap.VRamEnd, m.gpu.VRAM())
+	////// Cartridge RAM //////
+	handle(memorymap.ExternalRamStart, memorymap.ExternalRamEnd, m.cartridge)
+	////// WRAM bank 0 + WRAM bank 1 ( ~ 7 on CGB ) //////
+	handle(memorymap.WRam0Start, memorymap.WRam1End, m.wram)
+	////// Mirrored WRAM 0 & N //////
+	handle(memorymap.MirrorRamStart, memorymap.MirrorRamEnd, m.mirrorWram)
+	////// OAM + NOT USABLE //////
+	handle(memorymap.OAMStart, memorymap.NotUsableEnd, oamArea{m})
+	////// IO Registers + IE //////
+	handle(memorymap.IORegistersStart, memorymap.Interrupts, ioArea{m})
+	////// HRAM //////
+	m.pages.hram = m.hram.Data()
+}
+
+// mapCartridge maps the ROM and RAM banks selected by the cartridge
+func (m *MMU) mapCartridge() {
+	m.mapArea(memorymap.FixedRomStart, memorymap.FixedRomEnd, m.cartridge.Mapped(memorymap.FixedRomStart), false)
+	m.mapArea(memorymap.SwitchableRomStart, memorymap.SwitchableRomEnd, m.cartridge.Mapped(memorymap.SwitchableRomStart), false)
+	m.mapArea(memorymap.ExternalRamStart, memorymap.ExternalRamEnd, m.cartridge.Mapped(memorymap.ExternalRamStart), true)
+}
+
+// mapWRAM maps the WRAM banks, and their mirror at E000-FDFF
+func (m *MMU) mapWRAM() {
+	fixed, banked := m.wram.BankData(0), m.wram.BankData(m.wram.Bank())
+	m.mapArea(memorymap.WRam0Start, memorymap.WRam0End, fixed, true)
+	m.mapArea(memorymap.WRam1Start, memorymap.WRam1End, banked, true)
+	m.mapArea(memorymap.MirrorRamStart, memorymap.MirrorRamStart+0x0FFF, fixed, true)
+	m.mapArea(memorymap.MirrorRamStart+0x1000, memorymap.MirrorRamEnd, banked, true)
+}
+
+// mapArea maps the pages of [start, end] to data (mirrored if smaller), or to the components if data is nil.
+// Read only areas always write through the components.
+func (m *MMU) mapArea(start, end uint16, data []uint8, writable bool) {
+	for page := int(start >> 8); page <= int(end>>8); page++ {
+		var p []uint8
+		if len(data) > 0 {
+			offset := (page - int(start>>8)) * 0x100 % len(data)
+			p = data[offset : offset+0x100 : offset+0x100]
+		}
+		m.pages.read[page] = p
+		if writable {
+			m.pages.write[page] = p
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package mmu
+
+import (
+	"testing"
+)
+
+// newBankedTestMMU returns a MMU with a MBC1 cartridge : 8 ROM banks (first byte = bank number) and 32KB of RAM
+func newBankedTestMMU(t testing.TB) *MMU {
+	rom := make([]byte, 8*0x4000)
+	rom[0x0147] = 0x03 // MBC1 + RAM + BATTERY
+	rom[0x0148] = 0x02 // 8 banks
+	rom[0x0149] = 0x03 // 32KB
+	for bank := 1; bank < 8; bank++ {
+		rom[bank*0x4000] = uint8(bank)
+	}
+	return newTestMMUROM(t, rom, true)
+}
+
+func TestPagesCartridge(t *testing.T) {
+	m := newBankedTestMMU(t)
+	if got := m.Read(0x4000); got != 1 {
+		t.Errorf("ROM bank 1 : got 0x%02X", got)
+	}
+	m.Write(0x2000, 5)
+	if got := m.Read(0x4000); got != 5 {
+		t.Errorf("ROM bank 5 : got 0x%02X", got)
+	}
+	m.Write(0x4000, 0x00) // Upper bits of the ROM bank, not a ROM write
+	if got := m.Read(0x4000); got != 5 {
+		t.Errorf("ROM written : got 0x%02X", got)
+	}
+
+	// RAM disabled, enabled, banked
+	m.Write(0xA000, 0x11)
+	if got := m.Read(0xA000); got != 0xFF {
+		t.Errorf("RAM disabled : got 0x%02X", got)
+	}
+	m.Write(0x0000, 0x0A)
+	m.Write(0xA000, 0x11)
+	m.Write(0x6000, 0x01) // RAM banking mode
+	m.Write(0x4000, 0x02)
+	m.Write(0xA000, 0x22)
+	if got := m.cartridge.RAM()[2*0x2000]; got != 0x22 {
+		t.Errorf("RAM bank 2 : got 0x%02X", got)
+	}
+	m.Write(0x4000, 0x00)
+	if got := m.Read(0xA000); got != 0x11 {
+		t.Errorf("RAM bank 0 : got 0x%02X", got)
+	}
+	m.Write(0x0000, 0x00)
+	if got := m.Read(0xA000); got != 0xFF {
+		t.Errorf("RAM disabled again : got 0x%02X", got)
+	}
+}
+
+func TestPagesWRAM(t *testing.T) {
+	m := newBankedTestMMU(t)
+	m.Write(0xC010, 0x01)
+	m.Write(0xD010, 0x02) // Bank 1
+	m.Write(0xFF70, 2)
+	m.Write(0xD010, 0x03)
+	if got := m.Read(0xF010); got != 0x03 {
+		t.Errorf("mirror of bank 2 : got 0x%02X", got)
+	}
+	m.Write(0xFF70, 1)
+	if got := m.Read(0xD010); got != 0x02 {
+		t.Errorf("bank 1 : got 0x%02X", got)
+	}
+	m.Write(0xE010, 0x04)
+	if got := m.Read(0xC010); got != 0x04 {
+		t.Errorf("mirror write : got 0x%02X", got)
+	}
+	if got := m.wram.BankData(2)[0x10]; got != 0x03 {
+		t.Errorf("bank 2 data : got 0x%02X", got)
+	}
+}
+
+func TestPagesHRAM(t *testing.T) {
+	m := newBankedTestMMU(t)
+	m.Write(0xFF80, 0x01)
+	m.Write(0xFFFE, 0x02)
+	m.Write(0xFFFF, 0x1F) // IE
+	if got := m.hram.Data(); got[0] != 0x01 || got[0x7E] != 0x02 {
+		t.Errorf("HRAM data : 0x%02X 0x%02X", got[0], got[0x7E])
+	}
+	if got := m.Read(0xFFFE); got != 0x02 {
+		t.Errorf("HRAM read : got 0x%02X", got)
+	}
+	if got := m.Read(0xFFFF); got != 0x1F {
+		t.Errorf("IE : got 0x%02X", got)
+	}
+}
+
+// TestModeBlocking checks the CPU accesses blocked while the LCD draws (mode 3), the debugger ones are not
+func TestModeBlocking(t *testing.T) {
+	rom := make([]byte, 0x8000)
+	m := newTestMMUROM(t, rom, true)
+	m.Write(0xFF40, 0x91) // LCD on
+	m.Write(0xFF68, 0x80) // BG palette index 0, auto increment
+	for m.Read(0xFF41)&0x03 != 0x03 {
+		m.gpu.Tick(1)
+	}
+	m.Write(0x8000, 0x12)
+	m.Write(0xFE00, 0x34)
+	m.Write(0xFF69, 0x56)
+	if got := m.Peek(0x8000); got != 0x00 {
+		t.Errorf("VRAM write : got 0x%02X", got)
+	}
+	if got := m.Peek(0xFE00); got != 0x00 {
+		t.Errorf("OAM write : got 0x%02X", got)
+	}
+	if got := m.gpu.PaletteRAM(false)[0]; got != 0xFF {
+		t.Errorf("BCPD write : got 0x%02X", got)
+	}
+	if got := m.Read(0xFF68); got != 0xC1 {
+		t.Errorf("BCPS : got 0x%02X, the index must be incremented", got)
+	}
+	m.Poke(0x8000, 0x12)
+	for _, addr := range []uint16{0x8000, 0xFE00, 0xFF69} {
+		if got := m.Read(addr); got != 0xFF {
+			t.Errorf("0x%04X read : got 0x%02X", addr, got)
+		}
+	}
+	if got := m.Peek(0x8000); got != 0x12 {
+		t.Errorf("VRAM poke : got 0x%02X", got)
+	}
+
+	for m.Read(0xFF41)&0x03 != 0x00 {
+		m.gpu.Tick(1)
+	}
+	m.Write(0xFE00, 0x34)
+	if got := m.Read(0xFE00); got != 0x34 {
+		t.Errorf("HBlank OAM : got 0x%02X", got)
+	}
+	if got := m.Read(0x8000); got != 0x12 {
+		t.Errorf("HBlank VRAM : got 0x%02X", got)
+	}
+}
+
+// BenchmarkDispatch measures the CPU accesses of each area
+func BenchmarkDispatch(b *testing.B) {
+	m := newBankedTestMMU(b)
+	m.Write(0x0000, 0x0A) // RAM enabled
+	for _, area := range []struct {
+		name string
+		addr uint16
+	}{
+		{"ROM0", 0x0100},
+		{"ROMX", 0x4100},
+		{"VRAM", 0x8100},
+		{"CartRAM", 0xA100},
+		{"WRAM", 0xC100},
+		{"IO", 0xFF10},
+		{"HRAM", 0xFF80},
+	} {
+		addr := area.addr
+		b.Run("Read"+area.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m.Read(addr + uint16(i&0x0F))
+			}
+		})
+		if addr < 0x8000 {
+			continue // Bank switching
+		}
+		b.Run("Write"+area.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m.Write(addr+uint16(i&0x0F), uint8(i))
+			}
+		})
+	}
+}
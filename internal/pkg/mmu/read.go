@@ -1,44 +1,98 @@
 package mmu
 
 import (
-	"log"
-
+	"github.com/jmontupet/gbcore/internal/pkg/codedatalog"
 	"github.com/jmontupet/gbcore/internal/pkg/hram"
 	"github.com/jmontupet/gbcore/internal/pkg/ioports"
 	"github.com/jmontupet/gbcore/internal/pkg/mmu/memorymap"
-	"github.com/jmontupet/gbcore/internal/pkg/unusableaddr"
-	"github.com/jmontupet/gbcore/internal/pkg/wram"
 )
 
+// Read returns the value seen by the CPU at addr.
+// During an OAM DMA transfer, the CPU can only access IO registers and HRAM.
 func (m *MMU) Read(addr uint16) uint8 {
-	switch {
-	////// Cartridge bank 00 + Cartridge bank 01~NN //////
-	case addr >= memorymap.FixedRomStart && addr <= memorymap.SwitchableRomEnd:
-		return m.cartridge.Read(addr)
+	if m.oamDMA.transferActive && addr < ioports.AddrStart {
+		return 0xFF
+	}
+	m.syncIO(addr)
+	value := uint8(0xFF) // Blocked by the GPU
+	if !m.blocked(addr) {
+		value = m.read(addr)
+	}
+	if m.codeDataLog != nil {
+		m.logROM(addr, codedatalog.Data)
+	}
+	if m.hooked(addr, hookRead) {
+		value = m.onRead(addr, value)
+	}
+	return value
+}
 
-	////// VRAM  Switchable 0~1 on CGB//////
-	case addr >= memorymap.VRamStart && addr <= memorymap.VRamEnd:
-		return m.gpu.Read(addr)
+// Fetch returns the instruction byte (opcode or operand) seen by the CPU at addr.
+// It is a Read logged as code, the opcodes also run the exec hooks.
+func (m *MMU) Fetch(addr uint16, opcode bool) uint8 {
+	if opcode && m.hooked(addr, hookExec) {
+		m.onExec(addr)
+	}
+	if m.oamDMA.transferActive && addr < ioports.AddrStart {
+		return 0xFF
+	}
+	m.syncIO(addr)
+	value := uint8(0xFF) // Blocked by the GPU
+	if !m.blocked(addr) {
+		value = m.read(addr)
+	}
+	if m.codeDataLog != nil {
+		if opcode {
+			m.logROM(addr, codedatalog.Code)
+		} else {
+			m.logROM(addr, codedatalog.Operand)
+		}
+	}
+	if m.hooked(addr, hookRead) {
+		value = m.onRead(addr, value)
+	}
+	return value
+}
+
+func (m *MMU) read(addr uint16) uint8 {
+	if p := m.pages.read[addr>>8]; p != nil {
+		return p[addr&0xFF]
+	}
+	if addr >= ioports.AddrStart {
+		if addr >= hram.AddrStart && addr <= hram.AddrEnd {
+			return m.pages.hram[addr-hram.AddrStart]
+		}
+		return ioArea{m}.Read(addr) // Most accessed handler : direct call
+	}
+	return m.pages.handlers[addr>>8].Read(addr)
+}
 
-	////// Cartridge RAM //////
-	case addr >= memorymap.ExternalRamStart && addr <= memorymap.ExternalRamEnd:
-		return m.cartridge.Read(addr)
+// mbcArea handles 0000-7FFF : ROM reads not directly accessed and bank controller writes
+type mbcArea struct{ *MMU }
 
-	////// WRAM bank 0 + WRAM bank 1 ( ~ 7 on CGB ) //////
-	case addr >= wram.WRamStart && addr <= wram.WRamEnd:
-		return m.wram.Read(addr)
+func (a mbcArea) Read(addr uint16) uint8 { return a.cartridge.Read(addr) }
 
-	////// Mirrored WRAM 0 & N //////
-	case addr >= wram.MirrorWramStart && addr <= wram.MirrorWramEnd:
-		return m.mirrorWram.Read(addr)
+// oamArea handles FE00-FEFF
+type oamArea struct{ *MMU }
 
-	////// OAM //////
-	case addr >= memorymap.OAMStart && addr <= memorymap.OAMEnd:
+func (a oamArea) Read(addr uint16) uint8 {
+	m := a.MMU
+	if addr <= memorymap.OAMEnd {
 		return m.gpu.Read(addr)
-
+	}
 	////// NOT USABLE BUT USED //////
-	case addr >= unusableaddr.AddrStart && addr <= unusableaddr.AddrEnd:
-		return m.unusableAddr.Read(addr)
+	return m.unusableAddr.Read(addr)
+}
+
+// ioArea handles FF00-FF7F and FFFF : IO registers and IE (HRAM is mapped)
+type ioArea struct{ *MMU }
+
+func (a ioArea) Read(addr uint16) uint8 {
+	m := a.MMU
+	switch {
+	////// Interrupts Enable (IE) //////
+	case addr == memorymap.Interrupts:
+		return m.interrupt.Read(addr)
 
 	////// IO Registers //////
 	// Delegate control to Joypad
@@ -58,18 +112,7 @@ func (m *MMU) Read(addr uint16) uint8 {
 		addr == 0xFF6A,
 		addr == 0xFF6B:
 		return m.gpu.Read(addr)
-	case addr >= ioports.AddrStart && addr <= ioports.AddrEnd:
-		return m.io.Read(addr)
-
-	////// HRAM //////
-	case addr >= hram.AddrStart && addr <= hram.AddrEnd:
-		return m.hram.Read(addr)
-
-	////// Interrupts Enable (IE) //////
-	case addr == memorymap.Interrupts:
-		return m.interrupt.Read(addr)
 	default:
-		log.Fatalf("MEMORY UNREACHABLE : 0x%04X", addr)
-		return 0x00
+		return m.io.Read(addr)
 	}
 }
@@ -2,6 +2,9 @@ package mmu
 
 import (
 	"log"
+
+	"github.com/jmontupet/gbcore/internal/pkg/codedatalog"
+	"github.com/jmontupet/gbcore/internal/pkg/scheduler"
 )
 
 type VramDmaManager struct {
@@ -18,24 +21,16 @@ type VramDmaManager struct {
 
 func (vdma *VramDmaManager) Read(addr uint16) uint8 {
 	switch {
-	// FF51 - HDMA1 - CGB Mode Only - New DMA Source, High
-	case addr == 0xFF51:
-		return uint8(vdma.srcAddr >> 8)
-	// FF52 - HDMA2 - CGB Mode Only - New DMA Source, Low
-	case addr == 0xFF52:
-		return uint8(vdma.srcAddr & 0x00FF)
-	// FF53 - HDMA3 - CGB Mode Only - New DMA Destination, High
-	case addr == 0xFF53:
-		return uint8(vdma.dstAddr >> 8)
-	// FF54 - HDMA4 - CGB Mode Only - New DMA Destination, Low
-	case addr == 0xFF54:
-		return uint8(vdma.dstAddr & 0x00FF)
+	// FF51 -> FF54 - HDMA1 -> HDMA4 - Write Only
+	case addr >= 0xFF51 && addr <= 0xFF54:
+		return 0xFF
 	// FF55 - HDMA5 - CGB Mode Only - New DMA Length/Mode/Start
+	// Bit 7 cleared while the transfer is active, 0xFF when done.
 	case addr == 0xFF55:
 		if vdma.transferActive {
-			return 0b10000000
+			return uint8(vdma.transferLength/0x10-1) & 0x7F
 		}
-		return 0
+		return 0xFF
 	default:
 		log.Fatalf("READ MEMORY UNREACHABLE : 0x%04X", addr)
 		return 0x00
@@ -66,10 +61,22 @@ func (vdma *VramDmaManager) Write(addr uint16, value uint8) {
 	}
 }
 
-func (vdma *VramDmaManager) Tick(cycles uint8) {
+// NextEvent returns 1 while a transfer is requested : it is done on the next machine cycle
+func (vdma *VramDmaManager) NextEvent() uint64 {
+	if vdma.transferActive {
+		return 1
+	}
+	return scheduler.Never
+}
+
+// Advance copies the requested block at once, General Purpose or HBlank DMA
+func (vdma *VramDmaManager) Advance(n uint64) {
 	if vdma.transferActive {
 		for i := uint16(0); i < vdma.transferLength; i++ {
-			vdma.mmu.Write(vdma.dstAddr+i, vdma.mmu.Read(vdma.srcAddr+i))
+			vdma.mmu.write(vdma.dstAddr+i, vdma.mmu.read(vdma.srcAddr+i))
+			if vdma.mmu.codeDataLog != nil {
+				vdma.mmu.logROM(vdma.srcAddr+i, codedatalog.DMA)
+			}
 		}
 		vdma.transferActive = false
 	}
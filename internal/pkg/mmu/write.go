@@ -1,53 +1,84 @@
 package mmu
 
 import (
-	"log"
-
 	"github.com/jmontupet/gbcore/internal/pkg/hram"
 	"github.com/jmontupet/gbcore/internal/pkg/ioports"
 	"github.com/jmontupet/gbcore/internal/pkg/mmu/memorymap"
-	"github.com/jmontupet/gbcore/internal/pkg/unusableaddr"
-	"github.com/jmontupet/gbcore/internal/pkg/wram"
 )
 
+// Write sets the value at addr from the CPU.
+// During an OAM DMA transfer, the CPU can only access IO registers and HRAM.
 func (m *MMU) Write(addr uint16, value uint8) {
-	switch {
-	// Writing any value to this register resets it to 00h.
-	case addr == 0xFF04:
-		m.io.Write(0xFF04, 0)
-
-	////// Cartridge bank 00 + Cartridge bank 01~NN //////
-	case addr >= memorymap.FixedRomStart && addr <= memorymap.SwitchableRomEnd:
-		m.cartridge.Write(addr, value)
-
-	////// VRAM  Switchable 0~1 on CGB//////
-	case addr >= memorymap.VRamStart && addr <= memorymap.VRamEnd:
-		m.gpu.Write(addr, value)
-
-	////// Cartridge RAM //////
-	case addr >= memorymap.ExternalRamStart && addr <= memorymap.ExternalRamEnd:
-		m.cartridge.Write(addr, value)
+	if m.oamDMA.transferActive && addr < ioports.AddrStart {
+		return
+	}
+	if m.hooked(addr, hookWrite) {
+		var ok bool
+		if value, ok = m.onWrite(addr, value); !ok {
+			return
+		}
+	}
+	m.syncIO(addr)
+	if m.blocked(addr) {
+		m.gpu.BlockedWrite(addr)
+	} else {
+		m.write(addr, value)
+	}
+	m.syncIO(addr)
+}
 
-	////// WRAM bank 0 + WRAM bank 1 ( ~ 7 on CGB ) //////
-	case addr >= wram.WRamStart && addr <= wram.WRamEnd:
-		m.wram.Write(addr, value)
+func (m *MMU) write(addr uint16, value uint8) {
+	if p := m.pages.write[addr>>8]; p != nil {
+		p[addr&0xFF] = value
+		return
+	}
+	if addr >= ioports.AddrStart {
+		if addr >= hram.AddrStart && addr <= hram.AddrEnd {
+			m.pages.hram[addr-hram.AddrStart] = value
+			return
+		}
+		ioArea{m}.Write(addr, value) // Most accessed handler : direct call
+		return
+	}
+	m.pages.handlers[addr>>8].Write(addr, value)
+}
 
-	////// Mirrored WRAM 0 & N //////
-	case addr >= wram.MirrorWramStart && addr <= wram.MirrorWramEnd:
-		m.mirrorWram.Write(addr, value)
+// Write sets the cartridge bank controller registers and maps the banks selected
+func (a mbcArea) Write(addr uint16, value uint8) {
+	a.cartridge.Write(addr, value)
+	a.mapCartridge()
+}
 
-	////// OAM //////
-	case addr >= memorymap.OAMStart && addr <= memorymap.OAMEnd:
+func (a oamArea) Write(addr uint16, value uint8) {
+	m := a.MMU
+	if addr <= memorymap.OAMEnd {
 		m.gpu.Write(addr, value)
-
+		return
+	}
 	////// NOT USABLE BUT USED //////
-	case addr >= unusableaddr.AddrStart && addr <= unusableaddr.AddrEnd:
-		m.unusableAddr.Write(addr, value)
+	m.unusableAddr.Write(addr, value)
+}
+
+func (a ioArea) Write(addr uint16, value uint8) {
+	m := a.MMU
+	switch {
+	////// Interrupts Enable (IE) //////
+	case addr == memorymap.Interrupts:
+		m.interrupt.Write(addr, value)
 
 	////// IO Registers //////
+	// Writing any value to this register resets it to 00h, with the internal counter.
+	case addr == 0xFF04:
+		m.timers.ResetDiv()
+	// KEY1 : current speed (bit 7) is read only
+	case addr == 0xFF4D:
+		m.key1.Set(value)
 	// Delegate control to Joypad
 	case addr == 0xFF00:
 		m.joypad.Write(addr, value)
+	// Delegate control to Serial
+	case addr == 0xFF01, addr == 0xFF02:
+		m.serial.Write(addr, value)
 	// Delegate control to oamDMA for dma tranfer
 	case addr == 0xFF46:
 		m.oamDMA.Write(addr, value)
@@ -61,17 +92,11 @@ func (m *MMU) Write(addr uint16, value uint8) {
 		addr == 0xFF6A,
 		addr == 0xFF6B:
 		m.gpu.Write(addr, value)
-	case addr >= ioports.AddrStart && addr <= ioports.AddrEnd:
+	// SVBK : WRAM bank switched
+	case addr == 0xFF70:
 		m.io.Write(addr, value)
-
-	////// HRAM //////
-	case addr >= hram.AddrStart && addr <= hram.AddrEnd:
-		m.hram.Write(addr, value)
-
-	////// Interrupts Enable (IE) //////
-	case addr == memorymap.Interrupts:
-		m.interrupt.Write(addr, value)
+		m.mapWRAM()
 	default:
-		log.Fatalf("MEMORY UNREACHABLE : 0x%04X", addr)
+		m.io.Write(addr, value)
 	}
 }
@@ -0,0 +1,121 @@
+// Package scheduler clocks the components on their events instead of every machine cycle.
+//
+// Each component tells when its next event is due (mode change, interrupt request, end of a transfer...).
+// Between two events nothing observable happens in a component : it only runs when its event is due,
+// or when the CPU accesses its registers (Sync).
+package scheduler
+
+import "math"
+
+// Never is the delay of a component without upcoming event
+const Never uint64 = math.MaxUint64
+
+// Component is a part of the system clocked by the scheduler, in machine cycles.
+type Component interface {
+	// Advance runs the component for n machine cycles. No event is due before the last one.
+	Advance(n uint64)
+	// NextEvent returns the machine cycles before the next event (at least 1), or Never.
+	NextEvent() uint64
+}
+
+type entry struct {
+	component Component
+	synced    uint64 // Time the component is up to date with
+	event     uint64 // Time of its next event, Never if none
+}
+
+// Scheduler keeps the time of the system (machine cycles since power on)
+// and runs the components when their events are due.
+type Scheduler struct {
+	now     uint64
+	next    uint64 // Time of the earliest event
+	entries []entry
+}
+
+// New returns a scheduler without components, at time 0
+func New() *Scheduler {
+	return &Scheduler{next: Never}
+}
+
+// Add registers a component. Components due at the same time run in the order of registration.
+func (s *Scheduler) Add(c Component) {
+	s.entries = append(s.entries, entry{component: c, synced: s.now})
+	s.reschedule(&s.entries[len(s.entries)-1])
+}
+
+// Now returns the machine cycles since power on
+func (s *Scheduler) Now() uint64 { return s.now }
+
+// Next returns the time of the earliest event, Never if none
+func (s *Scheduler) Next() uint64 { return s.next }
+
+// Tick advances the time by one machine cycle, running the components whose event is due
+func (s *Scheduler) Tick() {
+	s.now++
+	if s.now >= s.next {
+		s.dispatch()
+	}
+}
+
+// Skip advances the time up to the next event, limited to max machine cycles (at least 1).
+// It returns the machine cycles skipped.
+func (s *Scheduler) Skip(max uint64) uint64 {
+	n := s.next - s.now
+	if n > max {
+		n = max
+	}
+	if n == 0 {
+		n = 1
+	}
+	s.now += n
+	if s.now >= s.next {
+		s.dispatch()
+	}
+	return n
+}
+
+// Sync brings all the components up to date and schedules their next event again.
+// Called around the CPU accesses to their registers.
+func (s *Scheduler) Sync() {
+	s.next = Never
+	for i := range s.entries {
+		e := &s.entries[i]
+		s.run(e)
+		if e.event < s.next {
+			s.next = e.event
+		}
+	}
+}
+
+// dispatch runs the components whose event is due
+func (s *Scheduler) dispatch() {
+	s.next = Never
+	for i := range s.entries {
+		e := &s.entries[i]
+		if e.event <= s.now {
+			s.run(e)
+		}
+		if e.event < s.next {
+			s.next = e.event
+		}
+	}
+}
+
+// run advances a component up to now and schedules its next event
+func (s *Scheduler) run(e *entry) {
+	if n := s.now - e.synced; n > 0 {
+		e.component.Advance(n)
+		e.synced = s.now
+	}
+	s.reschedule(e)
+}
+
+func (s *Scheduler) reschedule(e *entry) {
+	e.event = Never
+	if delay := e.component.NextEvent(); delay != Never {
+		e.event = s.now + delay
+	}
+	if e.event < s.next {
+		s.next = e.event
+	}
+}
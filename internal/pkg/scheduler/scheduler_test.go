@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"testing"
+)
+
+// counter has an event every period machine cycles, and records its runs
+type counter struct {
+	period  uint64
+	elapsed uint64
+	runs    []uint64
+	s       *Scheduler
+}
+
+func (c *counter) Advance(n uint64) {
+	c.elapsed += n
+	c.runs = append(c.runs, c.s.Now())
+}
+
+func (c *counter) NextEvent() uint64 {
+	if c.period == 0 {
+		return Never
+	}
+	return c.period - c.elapsed%c.period
+}
+
+func TestSchedulerEvents(t *testing.T) {
+	s := New()
+	fast := &counter{period: 3, s: s}
+	idle := &counter{s: s}
+	s.Add(fast)
+	s.Add(idle)
+	for i := 0; i < 10; i++ {
+		s.Tick()
+	}
+	if len(fast.runs) != 3 || fast.runs[2] != 9 {
+		t.Errorf("fast component runs : %v", fast.runs)
+	}
+	if len(idle.runs) != 0 {
+		t.Errorf("idle component runs : %v", idle.runs)
+	}
+
+	// Sync catches up all the components
+	s.Sync()
+	if fast.elapsed != 10 || idle.elapsed != 10 {
+		t.Errorf("sync : elapsed %d %d", fast.elapsed, idle.elapsed)
+	}
+
+	// Events rescheduled after a change
+	fast.period = 100
+	s.Sync()
+	for i := 0; i < 50; i++ {
+		s.Tick()
+	}
+	if len(fast.runs) != 4 {
+		t.Errorf("rescheduled component runs : %v", fast.runs)
+	}
+}
+
+func TestSchedulerSkip(t *testing.T) {
+	s := New()
+	c := &counter{period: 20, s: s}
+	s.Add(c)
+	if n := s.Skip(8); n != 8 {
+		t.Errorf("skip limited : %d", n)
+	}
+	if n := s.Skip(100); n != 12 || s.Now() != 20 || c.elapsed != 20 {
+		t.Errorf("skip to event : %d, now %d, elapsed %d", n, s.Now(), c.elapsed)
+	}
+
+	empty := New()
+	if n := empty.Skip(255); n != 255 {
+		t.Errorf("skip without events : %d", n)
+	}
+}
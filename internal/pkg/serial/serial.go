@@ -0,0 +1,85 @@
+package serial
+
+import (
+	"github.com/jmontupet/gbcore/internal/pkg/ioports"
+	"github.com/jmontupet/gbcore/internal/pkg/scheduler"
+)
+
+// Clocks to transfer one bit with the internal clock
+const bitClocks uint = 512    // 8192Hz
+const fastBitClocks uint = 16 // 262144Hz (CGB Only)
+
+// Serial emulate the link port, without link partner.
+//
+// Transfers started with the internal clock complete after 8 bits : the received
+// byte is 0xFF (nothing connected). Transfers using an external clock never complete.
+type Serial struct {
+	serialInt *ioports.BitPtr // Pointer interrupt when a transfer is complete
+
+	sb *ioports.Ptr // FF01 - SB - Serial transfer data
+	sc *ioports.Ptr // FF02 - SC - Serial Transfer Control
+	// 									Bit 7 - Transfer Start Flag (0=No transfer, 1=Start)
+	// 									Bit 1 - Clock Speed (0=Normal, 1=Fast) ** CGB Mode Only **
+	// 									Bit 0 - Shift Clock (0=External Clock, 1=Internal Clock)
+
+	transferActive bool
+	transferCount  uint // Clocks since the transfer start
+
+	// OnTransfer is called with the sent byte when a transfer starts (test ROMs output).
+	OnTransfer func(value uint8)
+}
+
+func (s *Serial) Write(addr uint16, value uint8) {
+	switch addr {
+	case 0xFF01:
+		s.sb.Set(value)
+	case 0xFF02:
+		s.sc.Set(value)
+		s.transferActive = value&0x81 == 0x81
+		s.transferCount = 0
+		if s.transferActive && s.OnTransfer != nil {
+			s.OnTransfer(s.sb.Get())
+		}
+	}
+}
+
+// mCycleClocks : the serial port is clocked by the CPU, 4 clocks per machine cycle (doubled in double speed)
+const mCycleClocks uint = 4
+
+// Advance runs the transfer for n machine cycles
+func (s *Serial) Advance(n uint64) {
+	if !s.transferActive {
+		return
+	}
+	s.transferCount += uint(n) * mCycleClocks
+	if s.transferCount >= s.transferClocks() {
+		s.transferActive = false
+		s.sb.Set(0xFF)
+		s.sc.SetBit7(false)
+		s.serialInt.Set(true)
+	}
+}
+
+// NextEvent returns the machine cycles before the end of the transfer (serial interrupt)
+func (s *Serial) NextEvent() uint64 {
+	if !s.transferActive {
+		return scheduler.Never
+	}
+	return uint64((s.transferClocks() - s.transferCount + mCycleClocks - 1) / mCycleClocks)
+}
+
+// transferClocks returns the duration of a 8 bits transfer
+func (s *Serial) transferClocks() uint {
+	if s.sc.GetBit1() {
+		return 8 * fastBitClocks
+	}
+	return 8 * bitClocks
+}
+
+func NewSerial(io *ioports.IOPorts) *Serial {
+	return &Serial{
+		sb:        io.NewPtr(0xFF01),     // SB
+		sc:        io.NewPtr(0xFF02),     // SC
+		serialInt: io.NewBit3Ptr(0xFF0F), // Interrupt
+	}
+}
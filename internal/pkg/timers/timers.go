@@ -2,6 +2,7 @@ package timers
 
 import (
 	"github.com/jmontupet/gbcore/internal/pkg/ioports"
+	"github.com/jmontupet/gbcore/internal/pkg/scheduler"
 )
 
 const cpuClock = 4194304
@@ -29,37 +30,73 @@ type Timers struct {
 	timaCount uint
 }
 
-func (t *Timers) Tick(cycles uint8) {
+// mCycleClocks : the timers are clocked by the CPU, 4 clocks per machine cycle (doubled in double speed)
+const mCycleClocks uint = 4
+
+// Advance runs the timers for n machine cycles
+func (t *Timers) Advance(n uint64) {
 	// INC DIV register
-	t.divCount += uint(cycles)
-	if t.divCount >= divClock {
-		div := t.div.Get()
-		if div == 0xFF { // Overflow
-			div = 0
-		} else {
-			div++
-		}
-		t.div.Set(div)
-		t.divCount %= divClock
+	t.divCount += uint(n) * mCycleClocks
+	t.div.Set(t.div.Get() + uint8(t.divCount/divClock))
+	t.divCount %= divClock
+
+	if !t.tac.GetBit2() { // Check if timer is started
+		return
+	}
+	// INC TIMA register
+	clock := t.cyclesTIMAInc()
+	// First cycle : after a TAC change, the counter can exceed the clock, only one increment
+	t.timaCount += mCycleClocks
+	if t.timaCount >= clock {
+		t.incTIMA()
+		t.timaCount %= clock
+	}
+	for t.timaCount += uint(n-1) * mCycleClocks; t.timaCount >= clock; t.timaCount -= clock {
+		t.incTIMA()
+	}
+}
+
+// ResetDiv resets DIV and the internal counter it is read from (DIV write, STOP).
+// The TIMA input is a bit of this counter : if it was set, its fall increments TIMA.
+func (t *Timers) ResetDiv() {
+	if t.tac.GetBit2() && t.timaCount >= t.cyclesTIMAInc()/2 {
+		t.incTIMA()
 	}
+	t.div.Set(0)
+	t.divCount = 0
+	t.timaCount = 0
+}
 
-	if t.tac.GetBit2() { // Check if timer is started
-		// INC TIMA register
-		t.timaCount += uint(cycles)
-		clock := t.cyclesTIMAInc()
-		if t.timaCount >= clock {
-			tima := t.tima.Get()
-			if tima == 0xFF { // Overflow
-				tima = t.tma.Get()
-				t.timerInt.Set(true)
-			} else {
-				tima++
-			}
-			t.tima.Set(tima)
-			t.timaCount %= clock
+// NextEvent returns the machine cycles before the TIMA overflow (timer interrupt)
+func (t *Timers) NextEvent() uint64 {
+	if !t.tac.GetBit2() {
+		return scheduler.Never
+	}
+	clock := t.cyclesTIMAInc()
+	incs := 0x100 - uint(t.tima.Get()) // Increments before the overflow
+	count := t.timaCount
+	var cycles uint64
+	if count+mCycleClocks >= clock { // Same first cycle as Advance
+		if incs == 1 {
+			return 1
 		}
+		incs--
+		count = (count + mCycleClocks) % clock
+		cycles = 1
 	}
+	return cycles + uint64((incs*clock-count+mCycleClocks-1)/mCycleClocks)
+}
 
+// incTIMA increments TIMA, reloaded with TMA with an interrupt on overflow
+func (t *Timers) incTIMA() {
+	tima := t.tima.Get()
+	if tima == 0xFF { // Overflow
+		tima = t.tma.Get()
+		t.timerInt.Set(true)
+	} else {
+		tima++
+	}
+	t.tima.Set(tima)
 }
 
 func (t *Timers) cyclesTIMAInc() uint {
@@ -0,0 +1,54 @@
+package timers
+
+import (
+	"testing"
+
+	"github.com/jmontupet/gbcore/internal/pkg/ioports"
+)
+
+func TestResetDiv(t *testing.T) {
+	tests := []struct {
+		name    string
+		advance uint64 // Machine cycles before the reset
+		tima    uint8  // TIMA after the reset
+	}{
+		{"TIMA input low", 1, 0x00},
+		{"TIMA input high : falling edge", 2, 0x01},
+		{"TIMA input high after an increment", 6, 0x02},
+	}
+	for _, tt := range tests {
+		io := ioports.NewGBIOPorts(true)
+		timers := NewTimers(io)
+		io.Write(0xFF07, 0x05) // Started, 16 clocks (4 machine cycles) per increment
+		timers.Advance(tt.advance)
+		timers.ResetDiv()
+		if tima := io.Read(0xFF05); tima != tt.tima {
+			t.Errorf("%s : expected TIMA 0x%02X, got 0x%02X", tt.name, tt.tima, tima)
+		}
+		// The next increment is a full period after the reset
+		timers.Advance(3)
+		if tima := io.Read(0xFF05); tima != tt.tima {
+			t.Errorf("%s : TIMA incremented early : 0x%02X", tt.name, tima)
+		}
+		timers.Advance(1)
+		if tima := io.Read(0xFF05); tima != tt.tima+1 {
+			t.Errorf("%s : expected TIMA 0x%02X after a period, got 0x%02X", tt.name, tt.tima+1, tima)
+		}
+	}
+}
+
+// TestResetDivCounter checks DIV is incremented a full period after the reset
+func TestResetDivCounter(t *testing.T) {
+	io := ioports.NewGBIOPorts(true)
+	timers := NewTimers(io)
+	timers.Advance(60)
+	timers.ResetDiv()
+	timers.Advance(63)
+	if div := io.Read(0xFF04); div != 0x00 {
+		t.Fatalf("DIV incremented early : 0x%02X", div)
+	}
+	timers.Advance(1)
+	if div := io.Read(0xFF04); div != 0x01 {
+		t.Fatalf("expected DIV 0x01, got 0x%02X", div)
+	}
+}
@@ -10,19 +10,14 @@ const (
 
 // UnusableAddr emulate unused address range.
 //
-// Not used but present.
-// Any game uses this range ?
-type UnusableAddr struct {
-	_data [0x60]uint8
-}
+// Nothing is connected : reads return 0xFF (open bus) and writes are ignored.
+type UnusableAddr struct{}
 
 func (unused *UnusableAddr) Read(addr uint16) uint8 {
-	return unused._data[addr-AddrStart]
+	return 0xFF
 }
 
-func (unused *UnusableAddr) Write(addr uint16, value uint8) {
-	unused._data[addr-AddrStart] = value
-}
+func (unused *UnusableAddr) Write(addr uint16, value uint8) {}
 
 // NewUnusableAddr returns simple Memory implementation for Unused memory range.
 func NewUnusableAddr() *UnusableAddr {
@@ -6,6 +6,8 @@ const (
 	WRamStart uint16 = 0xC000
 	// WRamEnd is the WRAM Memory End Addr
 	WRamEnd uint16 = 0xDFFF
+	// BankedWRamStart is the Switchable WRAM bank (1~7) Start Addr
+	BankedWRamStart uint16 = 0xD000
 
 	// MirrorWramStart is the Mirror of C000~DDFF Start Addr
 	MirrorWramStart uint16 = 0xE000
@@ -17,7 +19,5 @@ const (
 const (
 	// Fixed RAM Memory End Addr
 	fixedEnd uint16 = 0xCFFF
-	// Banked RAM Memory Start Addr
-	bankedStart uint16 = 0xD000
 	// WRamEnd is the WRAM Memory End Addr
 )
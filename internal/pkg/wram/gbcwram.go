@@ -26,12 +26,25 @@ func (io *WRam) getBank() uint8 {
 	return bank
 }
 
+// Bank returns the WRAM bank mapped at D000-DFFF (1-7)
+func (io *WRam) Bank() uint {
+	return uint(io.getBank()) + 1
+}
+
+// BankData returns the data of a bank without copy : 0 (C000-CFFF) or 1-7 (D000-DFFF)
+func (io *WRam) BankData(bank uint) []uint8 {
+	if bank == 0 {
+		return io._fixedRAM[:]
+	}
+	return io._bankedRAM[bank-1][:]
+}
+
 func (io *WRam) Read(addr uint16) uint8 {
 	switch {
 	case addr >= WRamStart && addr <= fixedEnd:
 		return io._fixedRAM[addr-WRamStart]
-	case addr >= bankedStart && addr <= WRamEnd:
-		return io._bankedRAM[io.getBank()][addr-bankedStart]
+	case addr >= BankedWRamStart && addr <= WRamEnd:
+		return io._bankedRAM[io.getBank()][addr-BankedWRamStart]
 	default:
 		log.Fatalf("MEMORY UNREACHABLE : 0x%04X", addr)
 		return 0
@@ -42,8 +55,8 @@ func (io *WRam) Write(addr uint16, value uint8) {
 	switch {
 	case addr >= WRamStart && addr <= fixedEnd:
 		io._fixedRAM[addr-WRamStart] = value
-	case addr >= bankedStart && addr <= WRamEnd:
-		io._bankedRAM[io.getBank()][addr-bankedStart] = value
+	case addr >= BankedWRamStart && addr <= WRamEnd:
+		io._bankedRAM[io.getBank()][addr-BankedWRamStart] = value
 	default:
 		log.Fatalf("MEMORY UNREACHABLE : 0x%04X", addr)
 	}
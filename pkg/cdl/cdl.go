@@ -0,0 +1,153 @@
+// Package cdl records how each ROM byte is used by the running game (Code/Data Logger).
+//
+// The log has one byte of flags per ROM byte, at the ROM file offset
+// (bank * 0x4000 + address & 0x3FFF). It is saved as is, a file of the size of the ROM
+// (see Target.ROMSize) with the flags below : 0x01 Code, 0x02 Operand, 0x04 Data, 0x08 DMA.
+// This format is gbcore's own : the CDL files of other emulators use other flags
+// (0x02 is Data for FCEUX or Mesen), they can't be loaded nor read by them.
+//
+//	log := cdl.New(emu)
+//	... play the game ...
+//	log.Save("game.cdl")
+//
+// Only the CPU accesses to the cartridge ROM are logged : code copied and
+// executed in RAM is not tracked.
+package cdl
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/jmontupet/gbcore/internal/pkg/codedatalog"
+)
+
+// Flags of a ROM byte
+const (
+	Code    = codedatalog.Code    // First byte of an executed instruction
+	Operand = codedatalog.Operand // Other bytes of an executed instruction (operands, CB opcodes)
+	Data    = codedatalog.Data    // Read by the CPU
+	DMA     = codedatalog.DMA     // Source of an OAM or VRAM DMA transfer
+)
+
+// Target is the logged emulator
+type Target interface {
+	// ROMSize returns the size of the ROM image in bytes : the file, padded with 0xFF up to
+	// the banks declared in the header if it is shorter. Overdumped files keep their size.
+	ROMSize() int
+	// SetCodeDataLog registers the flags updated on each ROM access (nil to disable)
+	SetCodeDataLog(log []uint8)
+}
+
+// Log is the code/data log of a ROM
+type Log struct {
+	target Target
+	flags  []uint8
+}
+
+// New starts logging the ROM accesses of target
+func New(target Target) *Log {
+	l := &Log{target: target, flags: make([]uint8, target.ROMSize())}
+	target.SetCodeDataLog(l.flags)
+	return l
+}
+
+// Stop stops logging. The flags are kept.
+func (l *Log) Stop() {
+	l.target.SetCodeDataLog(nil)
+}
+
+// Len returns the size of the log (ROM size)
+func (l *Log) Len() int {
+	return len(l.flags)
+}
+
+// Flags returns the flags of the ROM byte at offset
+func (l *Log) Flags(offset int) uint8 {
+	return l.flags[offset]
+}
+
+// Bytes returns a copy of the flags
+func (l *Log) Bytes() []uint8 {
+	return append([]uint8(nil), l.flags...)
+}
+
+// Reset clears all the flags
+func (l *Log) Reset() {
+	for i := range l.flags {
+		l.flags[i] = 0
+	}
+}
+
+// Stats is the number of ROM bytes having each flag
+type Stats struct {
+	Code    int
+	Operand int
+	Data    int
+	DMA     int
+	Unused  int // Never accessed
+}
+
+// Stats counts the ROM bytes by flag
+func (l *Log) Stats() Stats {
+	var s Stats
+	for _, f := range l.flags {
+		if f&Code != 0 {
+			s.Code++
+		}
+		if f&Operand != 0 {
+			s.Operand++
+		}
+		if f&Data != 0 {
+			s.Data++
+		}
+		if f&DMA != 0 {
+			s.DMA++
+		}
+		if f == 0 {
+			s.Unused++
+		}
+	}
+	return s
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("CODE %d  OPERAND %d  DATA %d  DMA %d  UNUSED %d", s.Code, s.Operand, s.Data, s.DMA, s.Unused)
+}
+
+// Merge adds the flags of a CDL file (previous sessions)
+func (l *Log) Merge(r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if len(data) != len(l.flags) {
+		return fmt.Errorf("CDL SIZE MISMATCH : 0x%X BYTES, ROM 0x%X BYTES", len(data), len(l.flags))
+	}
+	for i, f := range data {
+		l.flags[i] |= f
+	}
+	return nil
+}
+
+// WriteTo writes the CDL file
+func (l *Log) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(l.flags)
+	return int64(n), err
+}
+
+// Load merges the CDL file at path
+func (l *Log) Load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return l.Merge(f)
+}
+
+// Save writes the CDL file at path
+func (l *Log) Save(path string) error {
+	return ioutil.WriteFile(path, l.flags, 0644)
+}
@@ -0,0 +1,120 @@
+package cdl_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jmontupet/gbcore/pkg/cdl"
+	"github.com/jmontupet/gbcore/pkg/emulator"
+	"github.com/jmontupet/gbcore/pkg/nullio"
+)
+
+// testProgram :
+//
+//	0150  LD A, [$0300]
+//	0153  LD A, $02
+//	0155  LDH [$46], A   ; OAM DMA from 0200-029F
+//	0157  JR $0157       ; Not read : the ROM is unreadable during the DMA (RST $38)
+func newTestLog(t *testing.T) (*cdl.Log, emulator.Emulator) {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x0100:], []byte{0xC3, 0x50, 0x01})
+	copy(rom[0x0150:], []byte{0xFA, 0x00, 0x03, 0x3E, 0x02, 0xE0, 0x46, 0x18, 0xFE})
+	emu, err := emulator.NewEmulator(rom,
+		nullio.NewNullFrameDrawer(), nullio.NewNullInputsManager(), nullio.NewNullAudioPlayer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cdl.New(emu), emu
+}
+
+func TestLog(t *testing.T) {
+	log, emu := newTestLog(t)
+	if log.Len() != 0x8000 {
+		t.Fatalf("expected 0x8000 bytes, got 0x%X", log.Len())
+	}
+	for i := 0; i < 5; i++ {
+		emu.Step()
+	}
+	// DMA transfer (160 machine cycles)
+	for emu.Cycles() < 200 {
+		emu.Step()
+	}
+
+	tests := []struct {
+		offset int
+		flags  uint8
+	}{
+		{0x0100, cdl.Code},
+		{0x0101, cdl.Operand},
+		{0x0150, cdl.Code},
+		{0x0151, cdl.Operand},
+		{0x0152, cdl.Operand},
+		{0x0155, cdl.Code},
+		{0x0156, cdl.Operand},
+		{0x0157, 0}, // Fetched during the DMA
+		{0x0300, cdl.Data},
+		{0x0200, cdl.DMA},
+		{0x029F, cdl.DMA},
+		{0x02A0, 0},
+	}
+	for _, test := range tests {
+		if got := log.Flags(test.offset); got != test.flags {
+			t.Errorf("0x%04X : expected 0x%02X, got 0x%02X", test.offset, test.flags, got)
+		}
+	}
+	if stats := log.Stats(); stats.DMA != 0xA0 || stats.Data != 1 {
+		t.Errorf("STATS : %v", stats)
+	}
+
+	log.Stop()
+	log.Reset()
+	emu.Step()
+	if stats := log.Stats(); stats.Unused != log.Len() {
+		t.Errorf("STOPPED LOG UPDATED : %v", stats)
+	}
+}
+
+// TestLogSize checks the log has the size of the ROM image : padded short dumps, overdumps
+func TestLogSize(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		size    int
+		romSize uint8 // Header
+		logSize int
+	}{
+		{"short dump", 0x8000, 0x01, 0x10000},
+		{"overdump", 0x10000, 0x00, 0x10000},
+	} {
+		rom := make([]byte, tt.size)
+		rom[0x0147] = 0x01 // MBC1
+		rom[0x0148] = tt.romSize
+		emu, err := emulator.NewEmulator(rom,
+			nullio.NewNullFrameDrawer(), nullio.NewNullInputsManager(), nullio.NewNullAudioPlayer())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := cdl.New(emu).Len(); n != tt.logSize || n != emu.ROMSize() {
+			t.Errorf("%s : expected 0x%X bytes, got 0x%X (ROMSize 0x%X)", tt.name, tt.logSize, n, emu.ROMSize())
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	log, emu := newTestLog(t)
+	emu.Step()
+
+	var file bytes.Buffer
+	if _, err := log.WriteTo(&file); err != nil {
+		t.Fatal(err)
+	}
+	log.Reset()
+	if err := log.Merge(&file); err != nil {
+		t.Fatal(err)
+	}
+	if log.Flags(0x0100) != cdl.Code || log.Flags(0x0102) != cdl.Operand {
+		t.Errorf("FLAGS NOT MERGED : 0x%02X 0x%02X", log.Flags(0x0100), log.Flags(0x0102))
+	}
+	if err := log.Merge(bytes.NewReader(make([]byte, 0x4000))); err == nil {
+		t.Error("expected a size mismatch error")
+	}
+}
@@ -0,0 +1,144 @@
+// Package cheats applies Game Genie and GameShark codes to an emulator :
+//
+//	engine := cheats.New(emu) // Before Run
+//	engine.Add("01FF34C1", "Infinite lives")
+//	engine.SaveFile(cheats.GamePath(dir, emu.GetGameTitle()))
+//
+// Game Genie codes replace the ROM values read by the CPU (bus read hooks),
+// GameShark codes write RAM at the start of each VBlank (frame hook).
+// The engine must be used from the emulation goroutine (hooks, event handler) or before Run.
+package cheats
+
+import (
+	"github.com/jmontupet/gbcore/pkg/coreio"
+)
+
+// Target is the emulator receiving the cheats
+type Target interface {
+	Peek(addr uint16) uint8
+	Poke(addr uint16, value uint8)
+	AddBusReadHook(start, end uint16, hook coreio.ReadHook) int
+	RemoveBusHook(id int) bool
+	AddFrameHook(hook func())
+}
+
+// svbk is the CGB WRAM bank register
+const svbk uint16 = 0xFF70
+
+type entry struct {
+	Cheat
+	hook int // Bus hook of the enabled Game Genie codes, 0 if none
+}
+
+// Engine manages the cheats of a game
+type Engine struct {
+	target Target
+	cheats []*entry
+	nextID int
+}
+
+// New attaches a cheat engine to target. It must be called before running the emulation.
+func New(target Target) *Engine {
+	e := &Engine{target: target}
+	target.AddFrameHook(e.onFrame)
+	return e
+}
+
+// Add decodes and enables a cheat code. It returns the cheat id.
+func (e *Engine) Add(code string, name string) (int, error) {
+	c, err := Parse(code)
+	if err != nil {
+		return 0, err
+	}
+	e.nextID++
+	c.ID = e.nextID
+	c.Name = name
+	c.Enabled = true
+	entry := &entry{Cheat: c}
+	e.install(entry)
+	e.cheats = append(e.cheats, entry)
+	return c.ID, nil
+}
+
+// Remove deletes a cheat. It returns false if id is unknown.
+func (e *Engine) Remove(id int) bool {
+	for i, entry := range e.cheats {
+		if entry.ID == id {
+			e.uninstall(entry)
+			e.cheats = append(e.cheats[:i], e.cheats[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// SetEnabled enables or disables a cheat. It returns false if id is unknown.
+func (e *Engine) SetEnabled(id int, enabled bool) bool {
+	for _, entry := range e.cheats {
+		if entry.ID == id {
+			entry.Enabled = enabled
+			if enabled {
+				e.install(entry)
+			} else {
+				e.uninstall(entry)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// Cheats returns the cheats in insertion order
+func (e *Engine) Cheats() []Cheat {
+	cheats := make([]Cheat, len(e.cheats))
+	for i, entry := range e.cheats {
+		cheats[i] = entry.Cheat
+	}
+	return cheats
+}
+
+// Clear deletes all the cheats
+func (e *Engine) Clear() {
+	for _, entry := range e.cheats {
+		e.uninstall(entry)
+	}
+	e.cheats = nil
+}
+
+// install hooks the ROM address of an enabled Game Genie code
+func (e *Engine) install(entry *entry) {
+	if entry.Kind != GameGenie || !entry.Enabled || entry.hook != 0 {
+		return
+	}
+	c := entry.Cheat
+	entry.hook = e.target.AddBusReadHook(c.Addr, c.Addr, func(addr uint16, value uint8) uint8 {
+		if c.HasCompare && value != c.Compare {
+			return value // Other bank
+		}
+		return c.Value
+	})
+}
+
+func (e *Engine) uninstall(entry *entry) {
+	if entry.hook != 0 {
+		e.target.RemoveBusHook(entry.hook)
+		entry.hook = 0
+	}
+}
+
+// onFrame applies the enabled GameShark codes
+func (e *Engine) onFrame() {
+	for _, entry := range e.cheats {
+		if entry.Kind != GameShark || !entry.Enabled {
+			continue
+		}
+		if entry.Bank == CurrentBank {
+			e.target.Poke(entry.Addr, entry.Value)
+			continue
+		}
+		bank := e.target.Peek(svbk)
+		e.target.Poke(svbk, uint8(entry.Bank))
+		e.target.Poke(entry.Addr, entry.Value)
+		e.target.Poke(svbk, bank)
+	}
+}
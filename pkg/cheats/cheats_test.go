@@ -0,0 +1,153 @@
+package cheats
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jmontupet/gbcore/pkg/emulator"
+	"github.com/jmontupet/gbcore/pkg/nullio"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected Cheat
+	}{
+		{"3e1-40a-2a2", Cheat{Kind: GameGenie, Code: "3E1-40A-2A2", Addr: 0x5140, Value: 0x3E, Compare: 0x32, HasCompare: true, Bank: CurrentBank}},
+		{"422-00F", Cheat{Kind: GameGenie, Code: "422-00F", Addr: 0x0200, Value: 0x42, Bank: CurrentBank}},
+		{"01FF34C1", Cheat{Kind: GameShark, Code: "01FF34C1", Addr: 0xC134, Value: 0xFF, Bank: CurrentBank}},
+		{"01 05 E0 D2", Cheat{Kind: GameShark, Code: "0105E0D2", Addr: 0xD2E0, Value: 0x05, Bank: CurrentBank}},
+		{"9305E0D2", Cheat{Kind: GameShark, Code: "9305E0D2", Addr: 0xD2E0, Value: 0x05, Bank: 3}},
+	}
+	for _, test := range tests {
+		c, err := Parse(test.code)
+		if err != nil {
+			t.Errorf("%s : %v", test.code, err)
+			continue
+		}
+		if c != test.expected {
+			t.Errorf("%s : expected %+v, got %+v", test.code, test.expected, c)
+		}
+	}
+
+	for _, code := range []string{
+		"",
+		"3E1-407",     // Address $8140 : not in ROM
+		"3E1-40G-2A2", // Not hexadecimal
+		"01FF3400",    // ROM address
+		"02FF34C1",    // Unknown type
+		"91FF34C1",    // WRAM bank outside of D000-DFFF
+		"12345",
+	} {
+		if c, err := Parse(code); err == nil {
+			t.Errorf("%q : expected an error, got %+v", code, c)
+		}
+	}
+}
+
+// newTestEngine runs :
+//
+//	0150  LD A, [$0200]   ; $11
+//	0153  LD [$C000], A
+//	0156  JR $0150
+func newTestEngine(t *testing.T) (*Engine, emulator.Emulator) {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x0100:], []byte{0xC3, 0x50, 0x01})
+	copy(rom[0x0150:], []byte{0xFA, 0x00, 0x02, 0xEA, 0x00, 0xC0, 0x18, 0xF8})
+	rom[0x0200] = 0x11
+	emu, err := emulator.NewEmulator(rom,
+		nullio.NewNullFrameDrawer(), nullio.NewNullInputsManager(), nullio.NewNullAudioPlayer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(emu), emu
+}
+
+func run(emu emulator.Emulator, cycles uint64) {
+	for end := emu.Cycles() + cycles; emu.Cycles() < end; {
+		emu.Step()
+	}
+}
+
+func TestGameGenie(t *testing.T) {
+	engine, emu := newTestEngine(t)
+
+	// 42 at $0200 if $11 ($11 ^ $BA = $AB, rotated left : $AE)
+	id, err := engine.Add("422-00F-AAE", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	run(emu, 100)
+	if got := emu.Peek(0xC000); got != 0x42 {
+		t.Errorf("expected 0x42, got 0x%02X", got)
+	}
+	if got := emu.Peek(0x0200); got != 0x11 {
+		t.Errorf("ROM modified : 0x%02X", got)
+	}
+
+	engine.SetEnabled(id, false)
+	run(emu, 100)
+	if got := emu.Peek(0xC000); got != 0x11 {
+		t.Errorf("disabled : expected 0x11, got 0x%02X", got)
+	}
+
+	// Compare byte not matching : not applied
+	engine.Remove(id)
+	engine.Add("422-00F-AAA", "")
+	run(emu, 100)
+	if got := emu.Peek(0xC000); got != 0x11 {
+		t.Errorf("other compare byte : expected 0x11, got 0x%02X", got)
+	}
+}
+
+func TestGameShark(t *testing.T) {
+	engine, emu := newTestEngine(t)
+	engine.Add("0177F0C1", "")
+	engine.Add("9299F0D0", "WRAM bank 2")
+
+	run(emu, 20000) // 1 frame
+	if got := emu.Peek(0xC1F0); got != 0x77 {
+		t.Errorf("expected 0x77, got 0x%02X", got)
+	}
+	emu.Poke(0xFF70, 2)
+	if got := emu.Peek(0xD0F0); got != 0x99 {
+		t.Errorf("WRAM bank 2 : expected 0x99, got 0x%02X", got)
+	}
+	emu.Poke(0xFF70, 1)
+	if got := emu.Peek(0xD0F0); got != 0x00 {
+		t.Errorf("WRAM bank 1 : expected 0x00, got 0x%02X", got)
+	}
+}
+
+func TestCheatFile(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	file := "# TEST\n" +
+		"+ 01FF34C1 Infinite lives\n" +
+		"\n" +
+		"- 422-00F-AAE\n"
+	if err := engine.Load(strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+	cheats := engine.Cheats()
+	if len(cheats) != 2 || !cheats[0].Enabled || cheats[0].Name != "Infinite lives" || cheats[1].Enabled {
+		t.Fatalf("unexpected cheats %+v", cheats)
+	}
+
+	var saved bytes.Buffer
+	if err := engine.Save(&saved); err != nil {
+		t.Fatal(err)
+	}
+	expected := "+ 01FF34C1 Infinite lives\n- 422-00F-AAE\n"
+	if saved.String() != expected {
+		t.Errorf("expected %q, got %q", expected, saved.String())
+	}
+
+	if err := engine.Load(strings.NewReader("+ 01FF34C1\n* 01FF34C1\n")); err == nil || !strings.Contains(err.Error(), "LINE 2") {
+		t.Errorf("expected an error on line 2, got %v", err)
+	}
+	if got := GamePath("cheats", "POKEMON RED"); got != filepath.Join("cheats", "POKEMON_RED.cht") {
+		t.Errorf("GamePath : %q", got)
+	}
+}
@@ -0,0 +1,120 @@
+package cheats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kind is the format of a cheat code
+type Kind uint8
+
+const (
+	// GameGenie : ROM read substitution, "ABC-DEF-GHI" (or "ABC-DEF" without compare byte)
+	GameGenie Kind = iota
+	// GameShark : RAM write applied on each frame, "TTVVAAAA"
+	GameShark
+)
+
+func (k Kind) String() string {
+	switch k {
+	case GameGenie:
+		return "Game Genie"
+	case GameShark:
+		return "GameShark"
+	default:
+		return fmt.Sprintf("Kind(%d)", uint8(k))
+	}
+}
+
+// CurrentBank is the Bank of the GameShark codes writing in the banks currently mapped
+const CurrentBank = -1
+
+// Cheat is a decoded cheat code
+type Cheat struct {
+	ID      int
+	Name    string
+	Code    string // Normalized code ("ABC-DEF-GHI", "TTVVAAAA")
+	Enabled bool
+
+	Kind       Kind
+	Addr       uint16
+	Value      uint8 // Value read (Game Genie) or written (GameShark)
+	Compare    uint8 // Game Genie : original value replaced (bank check)
+	HasCompare bool
+	Bank       int // GameShark : WRAM bank written at D000-DFFF (CGB), CurrentBank otherwise
+}
+
+// Parse decodes a Game Genie or GameShark code. Dashes and spaces are ignored.
+//
+// Game Genie "ABC-DEF-GHI" : AB is the new value, FCDE the ROM address (F xored with $F),
+// GI the compare value (rotated right by 2 and xored with $BA). H is not used.
+//
+// GameShark "TTVVAAAA" : VV is the value written at AAAA (little endian).
+// TT is 01 (banks currently mapped) or 9X (WRAM bank X at D000-DFFF).
+func Parse(code string) (Cheat, error) {
+	digits := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	for _, d := range digits {
+		if !strings.ContainsRune("0123456789ABCDEF", d) {
+			return Cheat{}, fmt.Errorf("INVALID CHEAT CODE %q", code)
+		}
+	}
+	switch len(digits) {
+	case 6, 9:
+		return parseGameGenie(digits)
+	case 8:
+		return parseGameShark(digits)
+	default:
+		return Cheat{}, fmt.Errorf("INVALID CHEAT CODE %q : 6 OR 9 DIGITS (GAME GENIE), 8 DIGITS (GAMESHARK)", code)
+	}
+}
+
+// hexDigits returns the value of the hexadecimal digits s (already checked)
+func hexDigits(s string) uint16 {
+	v, _ := strconv.ParseUint(s, 16, 16)
+	return uint16(v)
+}
+
+func parseGameGenie(digits string) (Cheat, error) {
+	c := Cheat{
+		Kind:  GameGenie,
+		Value: uint8(hexDigits(digits[0:2])),
+		Addr:  (hexDigits(digits[5:6])^0xF)<<12 | hexDigits(digits[2:5]),
+		Bank:  CurrentBank,
+		Code:  digits[0:3] + "-" + digits[3:6],
+	}
+	if c.Addr >= 0x8000 {
+		return Cheat{}, fmt.Errorf("GAME GENIE ADDRESS NOT IN ROM : $%04X", c.Addr)
+	}
+	if len(digits) == 9 {
+		compare := uint8(hexDigits(digits[6:7]))<<4 | uint8(hexDigits(digits[8:9]))
+		c.Compare = (compare>>2 | compare<<6) ^ 0xBA
+		c.HasCompare = true
+		c.Code += "-" + digits[6:9]
+	}
+	return c, nil
+}
+
+func parseGameShark(digits string) (Cheat, error) {
+	c := Cheat{
+		Kind:  GameShark,
+		Value: uint8(hexDigits(digits[2:4])),
+		Addr:  hexDigits(digits[6:8])<<8 | hexDigits(digits[4:6]),
+		Bank:  CurrentBank,
+		Code:  digits,
+	}
+	switch codeType := hexDigits(digits[0:2]); {
+	case codeType == 0x00, codeType == 0x01:
+	case codeType >= 0x90 && codeType <= 0x97:
+		if c.Addr < 0xD000 || c.Addr > 0xDFFF {
+			return Cheat{}, fmt.Errorf("GAMESHARK WRAM BANK ADDRESS NOT IN D000-DFFF : $%04X", c.Addr)
+		}
+		c.Bank = int(codeType & 0x07)
+	default:
+		return Cheat{}, fmt.Errorf("GAMESHARK CODE TYPE NOT SUPPORTED : $%02X", codeType)
+	}
+	if c.Addr < 0x8000 {
+		return Cheat{}, fmt.Errorf("GAMESHARK ADDRESS NOT IN RAM : $%04X", c.Addr)
+	}
+	return c, nil
+}
@@ -0,0 +1,94 @@
+package cheats
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Cheat files have one cheat per line : "+" (enabled) or "-" (disabled), the code and the name.
+// Empty lines and lines starting with "#" are ignored.
+//
+//	# POKEMON RED
+//	+ 01FF34C1 Infinite money
+//	- 00A-17B-C49 Walk through walls
+
+// GamePath returns the path of the cheat file of a game in dir, named after its title
+func GamePath(dir string, title string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, strings.TrimSpace(title))
+	if name == "" {
+		name = "UNTITLED"
+	}
+	return filepath.Join(dir, name+".cht")
+}
+
+// Load adds the cheats of a cheat file
+func (e *Engine) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		fields := strings.SplitN(text, " ", 3)
+		if len(fields) < 2 || fields[0] != "+" && fields[0] != "-" {
+			return fmt.Errorf("CHEATS LINE %d : INVALID CHEAT %q", line, text)
+		}
+		name := ""
+		if len(fields) == 3 {
+			name = strings.TrimSpace(fields[2])
+		}
+		id, err := e.Add(fields[1], name)
+		if err != nil {
+			return fmt.Errorf("CHEATS LINE %d : %v", line, err)
+		}
+		e.SetEnabled(id, fields[0] == "+")
+	}
+	return scanner.Err()
+}
+
+// Save writes the cheats in a cheat file
+func (e *Engine) Save(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, c := range e.Cheats() {
+		state := "-"
+		if c.Enabled {
+			state = "+"
+		}
+		fmt.Fprintln(bw, strings.TrimSpace(state+" "+c.Code+" "+c.Name))
+	}
+	return bw.Flush()
+}
+
+// LoadFile adds the cheats of the cheat file at path
+func (e *Engine) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return e.Load(f)
+}
+
+// SaveFile writes the cheats in the cheat file at path
+func (e *Engine) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := e.Save(f); err != nil {
+		return err
+	}
+	return f.Close()
+}
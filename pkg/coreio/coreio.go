@@ -15,6 +15,16 @@ type KeyInputState uint8
 type FrameDrawer interface {
 	SwapFrameBuffer(frameBuffer *FrameBuffer, colors *FrameColors) (*FrameBuffer, *FrameColors)
 }
+
+// RGBFrameBuffer holds the RGB555 color of each pixel (bits 0-4 : red, 5-9 : green, 10-14 : blue)
+type RGBFrameBuffer [FrameBufferSize]uint16
+
+// RGBFrameDrawer is implemented by the FrameDrawers drawing RGB555 frames.
+// The CGB frames are sent to SwapRGBFrameBuffer, with all their colors. Otherwise they are
+// sent to SwapFrameBuffer, limited to FrameColors : past 64 colors, pixels get the nearest one.
+type RGBFrameDrawer interface {
+	SwapRGBFrameBuffer(frameBuffer *RGBFrameBuffer) *RGBFrameBuffer
+}
 type AudioPlayer interface {
 	SwapAudioBuffer(frameBuffer []uint8) []uint8
 }
@@ -22,6 +32,51 @@ type InputsManager interface {
 	CurrentInput() KeyInputState
 }
 
+// Registers is a snapshot of the CPU registers
+type Registers struct {
+	A, F, B, C, D, E, H, L uint8
+	SP, PC                 uint16
+}
+
+// ReadHook observes or overrides a CPU read : it returns the value seen by the CPU
+type ReadHook func(addr uint16, value uint8) uint8
+
+// WriteHook observes or overrides a CPU write : it returns the value to write, ok false to cancel the write
+type WriteHook func(addr uint16, value uint8) (written uint8, ok bool)
+
+// ExecHook is called when the CPU fetches an opcode at addr
+type ExecHook func(addr uint16)
+
+// MemoryDomain is a memory area accessed by offset, independently of the CPU banking and without side effects.
+// Offsets outside [0, Size()) are read as 0xFF and not written.
+type MemoryDomain interface {
+	Name() string
+	Size() int
+	Peek(offset int) uint8
+	Poke(offset int, value uint8)
+	// ReadBlock copies the bytes from offset into buf and returns the number of bytes copied
+	ReadBlock(offset int, buf []uint8) int
+}
+
+// EventHandler receives the emulation events.
+// HandleEvent is called from the emulation loop and should return quickly.
+type EventHandler interface {
+	HandleEvent(event Event)
+}
+
+type EventType uint8
+
+const (
+	// EventCPULocked : an illegal opcode froze the CPU. Only a reset can restart it.
+	EventCPULocked EventType = iota
+)
+
+type Event struct {
+	Type   EventType
+	PC     uint16 // Address of the instruction that raised the event
+	Opcode uint8
+}
+
 const (
 	GBKeyA      KeyInputState = 1 << iota
 	GBKeyB      KeyInputState = 1 << iota
@@ -0,0 +1,177 @@
+package debugger
+
+import (
+	"github.com/jmontupet/gbcore/pkg/coreio"
+)
+
+// AnyBank matches an address whatever the mapped bank
+const AnyBank = -1
+
+// Condition is evaluated with the registers before the instruction at the breakpoint.
+// The breakpoint is hit only if it returns true.
+type Condition func(regs coreio.Registers) bool
+
+// Breakpoint stops the execution before the instruction at Addr.
+type Breakpoint struct {
+	ID        int
+	Addr      uint16
+	Bank      int       // Bank mapped at Addr, or AnyBank
+	Condition Condition // nil : always hit
+	Temporary bool      // Removed when hit (run to cursor)
+}
+
+// Access is a kind of memory access, to be combined in a watchpoint
+type Access uint8
+
+const (
+	AccessRead Access = 1 << iota
+	AccessWrite
+	AccessExec
+)
+
+func (a Access) String() string {
+	s := []byte("---")
+	if a&AccessRead != 0 {
+		s[0] = 'r'
+	}
+	if a&AccessWrite != 0 {
+		s[1] = 'w'
+	}
+	if a&AccessExec != 0 {
+		s[2] = 'x'
+	}
+	return string(s)
+}
+
+// Watchpoint stops the execution after an instruction accessing Addr
+// (reads and writes), or before the instruction at Addr (exec), whatever the bank.
+type Watchpoint struct {
+	ID     int
+	Addr   uint16
+	Access Access
+}
+
+// AddBreakpoint adds a breakpoint at addr, in bank (or AnyBank). It returns its ID.
+func (d *Debugger) AddBreakpoint(addr uint16, bank int) int {
+	return d.AddConditionalBreakpoint(addr, bank, nil)
+}
+
+// AddConditionalBreakpoint adds a breakpoint hit only if cond returns true. It returns its ID.
+func (d *Debugger) AddConditionalBreakpoint(addr uint16, bank int, cond Condition) int {
+	d.nextID++
+	d.breakpoints = append(d.breakpoints, Breakpoint{
+		ID:        d.nextID,
+		Addr:      addr,
+		Bank:      bank,
+		Condition: cond,
+	})
+	return d.nextID
+}
+
+// AddWatchpoint adds a watchpoint on addr for the access kinds. It returns its ID.
+func (d *Debugger) AddWatchpoint(addr uint16, access Access) int {
+	d.nextID++
+	d.watchpoints = append(d.watchpoints, Watchpoint{
+		ID:     d.nextID,
+		Addr:   addr,
+		Access: access,
+	})
+	d.updateAccessHook()
+	return d.nextID
+}
+
+// Breakpoints returns the current breakpoints
+func (d *Debugger) Breakpoints() []Breakpoint {
+	return append([]Breakpoint(nil), d.breakpoints...)
+}
+
+// Watchpoints returns the current watchpoints
+func (d *Debugger) Watchpoints() []Watchpoint {
+	return append([]Watchpoint(nil), d.watchpoints...)
+}
+
+// Remove deletes the breakpoint or watchpoint id. It returns false if it doesn't exist.
+func (d *Debugger) Remove(id int) bool {
+	for i, bp := range d.breakpoints {
+		if bp.ID == id {
+			d.breakpoints = append(d.breakpoints[:i], d.breakpoints[i+1:]...)
+			return true
+		}
+	}
+	for i, wp := range d.watchpoints {
+		if wp.ID == id {
+			d.watchpoints = append(d.watchpoints[:i], d.watchpoints[i+1:]...)
+			d.updateAccessHook()
+			return true
+		}
+	}
+	return false
+}
+
+// Clear deletes all the breakpoints and watchpoints
+func (d *Debugger) Clear() {
+	d.breakpoints = nil
+	d.watchpoints = nil
+	d.updateAccessHook()
+}
+
+// updateAccessHook only installs the memory access hook while read/write watchpoints exist
+func (d *Debugger) updateAccessHook() {
+	for _, wp := range d.watchpoints {
+		if wp.Access&(AccessRead|AccessWrite) != 0 {
+			d.target.SetAccessHook(d.onAccess)
+			return
+		}
+	}
+	d.target.SetAccessHook(nil)
+}
+
+// onAccess records the first watched memory access of the current instruction
+func (d *Debugger) onAccess(addr uint16, value uint8, write bool) {
+	if d.watchHit != nil {
+		return
+	}
+	access := AccessRead
+	if write {
+		access = AccessWrite
+	}
+	for i := range d.watchpoints {
+		wp := &d.watchpoints[i]
+		if wp.Addr == addr && wp.Access&access != 0 {
+			d.watchHit = &Stop{
+				Reason:     StopWatchpoint,
+				ID:         wp.ID,
+				Addr:       addr,
+				Value:      value,
+				Access:     access,
+				Watchpoint: true,
+			}
+			return
+		}
+	}
+}
+
+// breakpointAt returns the breakpoint hit before the instruction at regs.PC
+func (d *Debugger) breakpointAt(regs coreio.Registers) (Stop, bool) {
+	for i, bp := range d.breakpoints {
+		if bp.Addr != regs.PC {
+			continue
+		}
+		if bp.Bank != AnyBank && uint(bp.Bank) != d.target.Bank(regs.PC) {
+			continue
+		}
+		if bp.Condition != nil && !bp.Condition(regs) {
+			continue
+		}
+		if bp.Temporary {
+			d.breakpoints = append(d.breakpoints[:i], d.breakpoints[i+1:]...)
+		}
+		return Stop{Reason: StopBreakpoint, ID: bp.ID, Addr: regs.PC}, true
+	}
+	for _, wp := range d.watchpoints {
+		if wp.Addr == regs.PC && wp.Access&AccessExec != 0 {
+			return Stop{Reason: StopWatchpoint, ID: wp.ID, Addr: regs.PC, Access: AccessExec, Watchpoint: true}, true
+		}
+	}
+	return Stop{}, false
+}
@@ -0,0 +1,334 @@
+// Package debugger provides breakpoints, watchpoints, stepping and
+// register / memory editing on top of an emulator.
+//
+// The debugger can drive the emulation itself (StepIn, StepOver, StepOut, RunTo, Continue),
+// or stop a running emulation (Emulator.Run) : the break handler is then called
+// in the emulation goroutine, between two instructions, and the emulation stays paused
+// until the handler returns. The handler can inspect and edit the state, and use the
+// stepping functions. The emulation resumes at the PC left by the handler.
+//
+//	dbg := debugger.New(emu)
+//	dbg.AddBreakpoint(0x0150, debugger.AnyBank)
+//	dbg.SetBreakHandler(func(stop debugger.Stop) {
+//		fmt.Println(stop)
+//		dbg.StepOver()
+//	})
+//	emu.Run()
+package debugger
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/jmontupet/gbcore/pkg/coreio"
+	"github.com/jmontupet/gbcore/pkg/disasm"
+	"github.com/jmontupet/gbcore/pkg/symbols"
+)
+
+// Target is the emulator controlled by the debugger
+type Target interface {
+	Step() uint8
+	Peek(addr uint16) uint8
+	Poke(addr uint16, value uint8)
+	Bank(addr uint16) uint
+	Registers() coreio.Registers
+	SetRegisters(regs coreio.Registers)
+	AddExecHook(hook func(regs coreio.Registers))
+	Break(handler func())
+	SetAccessHook(hook func(addr uint16, value uint8, write bool))
+}
+
+// StopReason is the cause of an execution stop
+type StopReason int
+
+const (
+	StopStep       StopReason = iota // Stepping done
+	StopBreakpoint                   // Breakpoint hit
+	StopWatchpoint                   // Watchpoint hit
+	StopPause                        // Pause requested
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case StopStep:
+		return "STEP"
+	case StopBreakpoint:
+		return "BREAKPOINT"
+	case StopWatchpoint:
+		return "WATCHPOINT"
+	case StopPause:
+		return "PAUSE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Stop describes why the execution stopped. PC is the next instruction to execute.
+type Stop struct {
+	Reason StopReason
+	PC     uint16
+	ID     int // Breakpoint / Watchpoint ID
+
+	// Watchpoints only
+	Watchpoint bool
+	Addr       uint16
+	Value      uint8 // Value read or written
+	Access     Access
+}
+
+func (s Stop) String() string {
+	switch {
+	case s.Watchpoint && s.Access == AccessExec:
+		return fmt.Sprintf("%s %d (x) AT 0x%04X", s.Reason, s.ID, s.PC)
+	case s.Watchpoint:
+		return fmt.Sprintf("%s %d (%s 0x%04X = 0x%02X) AT 0x%04X", s.Reason, s.ID, s.Access, s.Addr, s.Value, s.PC)
+	case s.Reason == StopBreakpoint:
+		return fmt.Sprintf("%s %d AT 0x%04X", s.Reason, s.ID, s.PC)
+	default:
+		return fmt.Sprintf("%s AT 0x%04X", s.Reason, s.PC)
+	}
+}
+
+// Debugger controls the execution of a Target.
+// Except Pause, its methods must be called from the emulation goroutine
+// (break handler), or while the emulation is not running.
+type Debugger struct {
+	target Target
+
+	breakpoints []Breakpoint
+	watchpoints []Watchpoint
+	nextID      int
+	symbols     *symbols.Table // nil : no symbols loaded
+
+	handler  func(stop Stop)
+	driving  bool  // Execution driven by the debugger (stepping)
+	executed bool  // An instruction was executed by the last Step (driving)
+	watchHit *Stop // Watched access during the current instruction
+	resumed  bool  // The handler returned : no stop before the instruction at resumePC
+	resumePC uint16
+	pause    int32 // Pause requested (atomic)
+}
+
+// New attaches a debugger to target. It must be called before running the emulation.
+func New(target Target) *Debugger {
+	d := &Debugger{target: target}
+	target.AddExecHook(d.onExec)
+	return d
+}
+
+// SetBreakHandler registers the function called when a running emulation stops.
+// The emulation resumes when the handler returns.
+func (d *Debugger) SetBreakHandler(handler func(stop Stop)) {
+	d.handler = handler
+}
+
+// Pause stops the emulation before the next instruction. It can be called from any goroutine.
+func (d *Debugger) Pause() {
+	atomic.StoreInt32(&d.pause, 1)
+}
+
+// onExec checks the stop conditions before each instruction of a running emulation.
+// The handler is called once the Step is stopped : it can step the emulation.
+func (d *Debugger) onExec(regs coreio.Registers) {
+	if d.driving {
+		d.executed = true
+		return
+	}
+	if d.handler == nil {
+		return
+	}
+	if d.resumed {
+		// The stop at resumePC is already reported (handler or stepping)
+		d.resumed = false
+		if regs.PC == d.resumePC {
+			return
+		}
+	}
+	if stop, ok := d.check(regs); ok {
+		d.target.Break(func() {
+			d.handler(stop)
+			d.resumed = true
+			d.resumePC = d.target.Registers().PC
+		})
+	}
+}
+
+// check returns the stop condition before the instruction at regs.PC
+func (d *Debugger) check(regs coreio.Registers) (Stop, bool) {
+	if atomic.CompareAndSwapInt32(&d.pause, 1, 0) {
+		return Stop{Reason: StopPause, PC: regs.PC}, true
+	}
+	if d.watchHit != nil {
+		stop := *d.watchHit
+		d.watchHit = nil
+		stop.PC = regs.PC
+		return stop, true
+	}
+	if stop, ok := d.breakpointAt(regs); ok {
+		stop.PC = regs.PC
+		return stop, true
+	}
+	return Stop{}, false
+}
+
+// runUntil runs Steps until done returns true or a stop condition is met.
+// done is called after each Step : a Step can dispatch an interrupt or be a halted cycle,
+// without executing an instruction (see executed).
+func (d *Debugger) runUntil(done func(regs coreio.Registers) bool) Stop {
+	driving := d.driving
+	d.driving = true
+	defer func() { d.driving = driving }()
+
+	d.watchHit = nil
+	for {
+		d.executed = false
+		d.target.Step()
+		regs := d.target.Registers()
+		if stop, ok := d.check(regs); ok {
+			return stop
+		}
+		if done(regs) {
+			return Stop{Reason: StopStep, PC: regs.PC}
+		}
+	}
+}
+
+// StepIn executes one instruction. The interrupt dispatches and the halted cycles
+// before it are run too : on a halted CPU, it runs until the CPU wakes up.
+func (d *Debugger) StepIn() Stop {
+	return d.runUntil(func(coreio.Registers) bool { return d.executed })
+}
+
+// StepOver executes one instruction. Calls (CALL, RST) are run until they return.
+func (d *Debugger) StepOver() Stop {
+	regs := d.target.Registers()
+	code := d.target.Peek(regs.PC)
+	if disasm.Opcodes[code].Flow != disasm.FlowCall {
+		return d.StepIn()
+	}
+	next := regs.PC + uint16(disasm.Opcodes[code].Length)
+	sp := regs.SP
+	return d.runUntil(func(regs coreio.Registers) bool {
+		return regs.PC == next && regs.SP >= sp
+	})
+}
+
+// StepOut runs until the current function returns
+func (d *Debugger) StepOut() Stop {
+	regs := d.target.Registers()
+	sp := regs.SP
+	ret := d.returns(regs.PC)
+	return d.runUntil(func(regs coreio.Registers) bool {
+		// A return of the current frame pops the return address above the initial SP
+		done := ret && regs.SP > sp
+		ret = d.returns(regs.PC)
+		return done
+	})
+}
+
+// RunTo runs until the instruction at addr (run to cursor)
+func (d *Debugger) RunTo(addr uint16) Stop {
+	return d.runUntil(func(regs coreio.Registers) bool { return regs.PC == addr })
+}
+
+// Continue runs until a breakpoint, a watchpoint or Pause
+func (d *Debugger) Continue() Stop {
+	return d.runUntil(func(coreio.Registers) bool { return false })
+}
+
+// ContinueTo makes the running emulation stop at addr (run to cursor from a break handler) :
+// a temporary breakpoint is added. It returns its ID.
+func (d *Debugger) ContinueTo(addr uint16) int {
+	d.nextID++
+	d.breakpoints = append(d.breakpoints, Breakpoint{
+		ID:        d.nextID,
+		Addr:      addr,
+		Bank:      AnyBank,
+		Temporary: true,
+	})
+	return d.nextID
+}
+
+// returns returns true if the instruction at pc is a RET, RET cc or RETI
+func (d *Debugger) returns(pc uint16) bool {
+	return disasm.Opcodes[d.target.Peek(pc)].Flow == disasm.FlowReturn
+}
+
+// Registers returns the CPU registers
+func (d *Debugger) Registers() coreio.Registers {
+	return d.target.Registers()
+}
+
+// SetRegisters replaces the CPU registers
+func (d *Debugger) SetRegisters(regs coreio.Registers) {
+	d.target.SetRegisters(regs)
+}
+
+// ReadMemory returns length bytes from addr, without side effects (wraps at 0xFFFF)
+func (d *Debugger) ReadMemory(addr uint16, length int) []uint8 {
+	data := make([]uint8, length)
+	for i := range data {
+		data[i] = d.target.Peek(addr + uint16(i))
+	}
+	return data
+}
+
+// WriteMemory writes data from addr. Writes to ROM or IO registers have the CPU write effects.
+func (d *Debugger) WriteMemory(addr uint16, data []uint8) {
+	for i, value := range data {
+		d.target.Poke(addr+uint16(i), value)
+	}
+}
+
+// Disassemble returns count instructions from addr
+func (d *Debugger) Disassemble(addr uint16, count int) []disasm.Instruction {
+	return disasm.Disassemble(d.target, addr, count)
+}
+
+// SetSymbols sets the symbols used for breakpoints by name and listings (nil to remove them)
+func (d *Debugger) SetSymbols(table *symbols.Table) {
+	d.symbols = table
+}
+
+// AddSymbolBreakpoint adds a breakpoint at the symbol name, in its bank. It returns its ID.
+func (d *Debugger) AddSymbolBreakpoint(name string) (int, error) {
+	if d.symbols == nil {
+		return 0, fmt.Errorf("NO SYMBOLS LOADED")
+	}
+	symbol, ok := d.symbols.Lookup(name)
+	if !ok {
+		return 0, fmt.Errorf("UNKNOWN SYMBOL %q", name)
+	}
+	return d.AddBreakpoint(symbol.Addr, int(symbol.Bank)), nil
+}
+
+// FormatAddr returns "Label+$offset" for addr in its mapped bank, or "BB:AAAA" without symbol
+func (d *Debugger) FormatAddr(addr uint16) string {
+	bank := d.target.Bank(addr)
+	if d.symbols != nil {
+		if name, ok := d.symbols.Symbolize(bank, addr); ok {
+			return name
+		}
+	}
+	return disasm.FormatAddr(bank, addr)
+}
+
+// Listing returns count disassembled lines from addr, with symbols :
+//
+//	00:0152  CD 60 01  CALL Update          ; Main+$2
+func (d *Debugger) Listing(addr uint16, count int) []string {
+	lines := make([]string, 0, count)
+	for _, instruction := range d.Disassemble(addr, count) {
+		if d.symbols == nil {
+			lines = append(lines, instruction.String())
+			continue
+		}
+		instruction.Text = instruction.Symbolic(d.symbols, d.target)
+		line := instruction.String()
+		if name, ok := d.symbols.Symbolize(instruction.Bank, instruction.Addr); ok {
+			line = fmt.Sprintf("%-40s; %s", line, name)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,256 @@
+package debugger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jmontupet/gbcore/pkg/coreio"
+	"github.com/jmontupet/gbcore/pkg/emulator"
+	"github.com/jmontupet/gbcore/pkg/nullio"
+	"github.com/jmontupet/gbcore/pkg/symbols"
+)
+
+// testProgram :
+//
+//	0150  LD A, $05
+//	0152  CALL $0160
+//	0155  LD [$C000], A
+//	0158  JR $0158
+//	0160  INC A
+//	0161  LD B, A
+//	0162  RET
+func newTestDebugger(t *testing.T) (*Debugger, emulator.Emulator) {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x0100:], []byte{0xC3, 0x50, 0x01})
+	copy(rom[0x0150:], []byte{0x3E, 0x05, 0xCD, 0x60, 0x01, 0xEA, 0x00, 0xC0, 0x18, 0xFE})
+	copy(rom[0x0160:], []byte{0x3C, 0x47, 0xC9})
+	emu, err := emulator.NewEmulator(rom,
+		nullio.NewNullFrameDrawer(), nullio.NewNullInputsManager(), nullio.NewNullAudioPlayer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := New(emu)
+	if stop := d.RunTo(0x0150); stop.PC != 0x0150 {
+		t.Fatalf("RUN TO 0x0150 : %s", stop)
+	}
+	return d, emu
+}
+
+func expectStop(t *testing.T, name string, got Stop, reason StopReason, pc uint16) {
+	t.Helper()
+	if got.Reason != reason || got.PC != pc {
+		t.Errorf("%s : %s, want %s AT 0x%04X", name, got, reason, pc)
+	}
+}
+
+func TestStepping(t *testing.T) {
+	d, _ := newTestDebugger(t)
+
+	expectStop(t, "STEP IN", d.StepIn(), StopStep, 0x0152)
+	expectStop(t, "STEP IN CALL", d.StepIn(), StopStep, 0x0160)
+	expectStop(t, "STEP OUT", d.StepOut(), StopStep, 0x0155)
+	if regs := d.Registers(); regs.A != 6 || regs.B != 6 {
+		t.Errorf("A = 0x%02X, B = 0x%02X after the call, want 0x06", regs.A, regs.B)
+	}
+
+	d, _ = newTestDebugger(t)
+	d.StepIn()
+	expectStop(t, "STEP OVER CALL", d.StepOver(), StopStep, 0x0155)
+	expectStop(t, "STEP OVER", d.StepOver(), StopStep, 0x0158)
+}
+
+// TestStepInterrupts checks StepIn runs until an instruction is executed :
+// halted cycles and interrupt dispatches alone don't end the step.
+func TestStepInterrupts(t *testing.T) {
+	d, _ := newTestDebugger(t)
+
+	// C100  HALT
+	// C101  INC A
+	// C102  EI
+	// C103  NOP
+	// C104  JR $C104
+	d.WriteMemory(0xC100, []uint8{0x76, 0x3C, 0xFB, 0x00, 0x18, 0xFE})
+	d.WriteMemory(0xFFFF, []uint8{0x04}) // IE : timer
+	d.WriteMemory(0xFF07, []uint8{0x05}) // TAC : timer on, 16 clocks
+	regs := d.Registers()
+	regs.A = 0
+	regs.PC = 0xC100
+	d.SetRegisters(regs)
+
+	expectStop(t, "STEP IN HALT", d.StepIn(), StopStep, 0xC101)
+	expectStop(t, "STEP IN HALTED", d.StepIn(), StopStep, 0xC102)
+	if regs := d.Registers(); regs.A != 1 {
+		t.Errorf("A = 0x%02X after the halted step, want 0x01", regs.A)
+	}
+
+	// The timer interrupt is pending (IME disabled by the boot ROM)
+	expectStop(t, "STEP IN EI", d.StepIn(), StopStep, 0xC103)
+	expectStop(t, "STEP IN NOP", d.StepIn(), StopStep, 0xC104)
+	expectStop(t, "STEP IN DISPATCH", d.StepIn(), StopStep, 0x0051) // NOP at 0x0050
+}
+
+func TestBreakpoints(t *testing.T) {
+	d, _ := newTestDebugger(t)
+
+	// Not in ROM bank 1 : never hit
+	d.AddBreakpoint(0x0160, 1)
+	id := d.AddBreakpoint(0x0160, 0)
+	stop := d.Continue()
+	expectStop(t, "BREAKPOINT", stop, StopBreakpoint, 0x0160)
+	if stop.ID != id {
+		t.Errorf("BREAKPOINT ID %d, want %d", stop.ID, id)
+	}
+
+	d.Clear()
+	d.AddConditionalBreakpoint(0x0158, AnyBank, func(regs coreio.Registers) bool { return regs.A == 7 })
+	d.AddConditionalBreakpoint(0x0158, AnyBank, func(regs coreio.Registers) bool { return regs.A == 6 })
+	expectStop(t, "CONDITIONAL BREAKPOINT", d.Continue(), StopBreakpoint, 0x0158)
+
+	if !d.Remove(id+1) || d.Remove(id+100) || len(d.Breakpoints()) != 1 {
+		t.Errorf("REMOVE : %d BREAKPOINTS LEFT", len(d.Breakpoints()))
+	}
+}
+
+func TestWatchpoints(t *testing.T) {
+	d, _ := newTestDebugger(t)
+
+	d.AddWatchpoint(0xC000, AccessRead)
+	id := d.AddWatchpoint(0xC000, AccessWrite)
+	stop := d.Continue()
+	expectStop(t, "WRITE WATCHPOINT", stop, StopWatchpoint, 0x0158)
+	if stop.ID != id || stop.Addr != 0xC000 || stop.Value != 0x06 || stop.Access != AccessWrite {
+		t.Errorf("WRITE WATCHPOINT : %s", stop)
+	}
+
+	d, _ = newTestDebugger(t)
+	d.AddWatchpoint(0x0161, AccessExec)
+	expectStop(t, "EXEC WATCHPOINT", d.Continue(), StopWatchpoint, 0x0161)
+}
+
+func TestEditing(t *testing.T) {
+	d, _ := newTestDebugger(t)
+
+	regs := d.Registers()
+	regs.A = 0x41
+	regs.F = 0xFF
+	regs.PC = 0x0152 // Skip LD A, $05
+	d.SetRegisters(regs)
+	if got := d.Registers(); got.A != 0x41 || got.F != 0xF0 {
+		t.Errorf("SET REGISTERS : A = 0x%02X F = 0x%02X, want 0x41 0xF0", got.A, got.F)
+	}
+
+	d.WriteMemory(0xC100, []uint8{1, 2, 3})
+	if got := d.ReadMemory(0xC100, 3); got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Errorf("READ MEMORY : %v, want [1 2 3]", got)
+	}
+
+	d.RunTo(0x0155)
+	if d.Registers().A != 0x42 {
+		t.Errorf("A = 0x%02X, want 0x42", d.Registers().A)
+	}
+}
+
+func TestBreakHandler(t *testing.T) {
+	d, emu := newTestDebugger(t)
+
+	var stops []Stop
+	id := d.AddBreakpoint(0x0160, AnyBank)
+	d.SetBreakHandler(func(stop Stop) {
+		stops = append(stops, stop)
+		if stop.ID == id {
+			// Stepping from the handler doesn't call it again
+			stops = append(stops, d.StepOut())
+		}
+	})
+	d.ContinueTo(0x0158)
+
+	// Running emulation
+	for i := 0; i < 20; i++ {
+		emu.Step()
+	}
+	if len(stops) != 3 {
+		t.Fatalf("%d STOPS, want 3 : %v", len(stops), stops)
+	}
+	expectStop(t, "HANDLER BREAKPOINT", stops[0], StopBreakpoint, 0x0160)
+	expectStop(t, "HANDLER STEP OUT", stops[1], StopStep, 0x0155)
+	expectStop(t, "HANDLER RUN TO CURSOR", stops[2], StopBreakpoint, 0x0158)
+
+	d.Pause()
+	emu.Step()
+	if len(stops) != 4 || stops[3].Reason != StopPause {
+		t.Errorf("PAUSE NOT HANDLED : %v", stops)
+	}
+}
+
+// TestBreakHandlerStep checks a breakpoint right after a step from the handler :
+// the Step stopped runs no instruction, and the emulation resumes after the handler step.
+func TestBreakHandlerStep(t *testing.T) {
+	d, emu := newTestDebugger(t)
+	regs := d.Registers()
+	regs.B = 0
+	d.SetRegisters(regs)
+
+	var stops []Stop
+	first := d.AddBreakpoint(0x0160, AnyBank)
+	d.AddBreakpoint(0x0161, AnyBank)
+	d.SetBreakHandler(func(stop Stop) {
+		stops = append(stops, stop)
+		if stop.ID == first {
+			stops = append(stops, d.StepIn())
+		}
+	})
+
+	for len(stops) == 0 {
+		emu.Step()
+	}
+	if len(stops) != 2 {
+		t.Fatalf("%d STOPS, want 2 : %v", len(stops), stops)
+	}
+	expectStop(t, "HANDLER BREAKPOINT", stops[0], StopBreakpoint, 0x0160)
+	expectStop(t, "HANDLER STEP", stops[1], StopBreakpoint, 0x0161)
+	if regs := d.Registers(); regs.PC != 0x0161 || regs.B != 0 {
+		t.Errorf("PC = 0x%04X, B = 0x%02X after the stopped Step, want 0x0161 0x00", regs.PC, regs.B)
+	}
+
+	// The breakpoint already reported by the step doesn't stop the resumed emulation
+	emu.Step()
+	if regs := d.Registers(); regs.PC != 0x0162 || regs.B != 0x06 || len(stops) != 2 {
+		t.Errorf("PC = 0x%04X, B = 0x%02X after the resumed Step, want 0x0162 0x06 : %v", regs.PC, regs.B, stops)
+	}
+}
+
+func TestSymbols(t *testing.T) {
+	d, _ := newTestDebugger(t)
+
+	if _, err := d.AddSymbolBreakpoint("Update"); err == nil {
+		t.Errorf("expected an error without symbols")
+	}
+	table, err := symbols.Parse(strings.NewReader("00:0150 Main\n00:0160 Update\n00:c000 wCounter\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.SetSymbols(table)
+	if _, err := d.AddSymbolBreakpoint("Missing"); err == nil {
+		t.Errorf("expected an error for an unknown symbol")
+	}
+	if _, err := d.AddSymbolBreakpoint("Update"); err != nil {
+		t.Fatal(err)
+	}
+	expectStop(t, "SYMBOL BREAKPOINT", d.Continue(), StopBreakpoint, 0x0160)
+
+	if got := d.FormatAddr(0x0161); got != "Update+$1" {
+		t.Errorf("FORMAT ADDR : %q, want \"Update+$1\"", got)
+	}
+	if got := d.FormatAddr(0x0100); got != "00:0100" {
+		t.Errorf("FORMAT ADDR : %q, want \"00:0100\"", got)
+	}
+	expected := []string{
+		"00:0152  CD 60 01  CALL Update          ; Main+$2",
+		"00:0155  EA 00 C0  LD [wCounter], A     ; Main+$5",
+	}
+	for n, line := range d.Listing(0x0152, 2) {
+		if line != expected[n] {
+			t.Errorf("LISTING : %q, want %q", line, expected[n])
+		}
+	}
+}
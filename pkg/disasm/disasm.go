@@ -0,0 +1,203 @@
+// Package disasm decodes SM83 (GameBoy CPU) machine code.
+package disasm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Memory is the memory view used to disassemble a live emulator (see emulator.Emulator).
+type Memory interface {
+	// Peek returns the value at addr without side effects
+	Peek(addr uint16) uint8
+	// Bank returns the bank currently mapped at addr
+	Bank(addr uint16) uint
+}
+
+// Instruction is a decoded instruction
+type Instruction struct {
+	Bank    uint
+	Addr    uint16
+	Bytes   []uint8 // Opcode and operand bytes
+	Opcode  Opcode
+	Operand uint16 // Raw operand value (little endian for 16 bits operands)
+	Text    string // Mnemonic with operand
+}
+
+// Target returns the destination of jumps, calls and restarts.
+// ok is false for other instructions (and JP HL, RET...).
+func (i Instruction) Target() (target uint16, ok bool) {
+	switch {
+	case i.Opcode.Flow != FlowJump && i.Opcode.Flow != FlowCall:
+		return 0, false
+	case i.Opcode.Operand == OperandRel:
+		return i.Addr + uint16(len(i.Bytes)) + uint16(int8(i.Operand)), true
+	case i.Opcode.Operand == OperandA16: // JP, CALL
+		return i.Operand, true
+	case i.Opcode.Flow == FlowCall: // RST
+		return uint16(i.Bytes[0] & 0x38), true
+	default: // JP HL
+		return 0, false
+	}
+}
+
+// Symbolizer names the addresses (see package symbols)
+type Symbolizer interface {
+	// Symbolize returns the name of addr in bank ("Label" or "Label+$offset")
+	Symbolize(bank uint, addr uint16) (string, bool)
+}
+
+// Symbolic returns Text with the address operand replaced by its symbol, if any.
+// Immediate 16 bits values are only replaced by exact symbols. RST targets are kept.
+// mem gives the banks currently mapped : it can be nil for a static disassembly.
+func (i Instruction) Symbolic(sym Symbolizer, mem Memory) string {
+	var addr uint16
+	exact := false
+	switch i.Opcode.Operand {
+	case OperandA16: // Includes JP and CALL
+		addr = i.Operand
+	case OperandN16:
+		addr = i.Operand
+		exact = true
+	case OperandA8:
+		addr = 0xFF00 | i.Operand
+	case OperandRel:
+		addr, _ = i.Target()
+	default:
+		return i.Text
+	}
+	name, ok := sym.Symbolize(i.bankOf(addr, mem), addr)
+	if !ok || exact && strings.ContainsRune(name, '+') {
+		return i.Text
+	}
+	return fmt.Sprintf(i.Opcode.Mnemonic, name)
+}
+
+// bankOf returns the bank of addr seen from the instruction : the instruction bank in its own
+// switchable ROM area, the bank mapped in mem otherwise. Without mem, bank 1 is assumed for
+// the switchable ROM and 0 for the other areas.
+func (i Instruction) bankOf(addr uint16, mem Memory) uint {
+	romx := func(a uint16) bool { return a >= 0x4000 && a <= 0x7FFF }
+	switch {
+	case addr < 0x4000:
+		return 0
+	case romx(addr) && romx(i.Addr):
+		return i.Bank
+	case mem != nil:
+		return mem.Bank(addr)
+	case romx(addr):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// String returns the instruction as "BB:AAAA  XX XX XX  MNEMONIC"
+func (i Instruction) String() string {
+	bytes := make([]string, len(i.Bytes))
+	for n, b := range i.Bytes {
+		bytes[n] = fmt.Sprintf("%02X", b)
+	}
+	return fmt.Sprintf("%s  %-8s  %s", FormatAddr(i.Bank, i.Addr), strings.Join(bytes, " "), i.Text)
+}
+
+// FormatAddr returns a bank aware address "BB:AAAA"
+func FormatAddr(bank uint, addr uint16) string {
+	return fmt.Sprintf("%02X:%04X", bank, addr)
+}
+
+// Lookup returns the opcode description of the instruction starting with code.
+// next is only used for the 0xCB prefix.
+func Lookup(code uint8, next uint8) Opcode {
+	if code == 0xCB {
+		return CBOpcodes[next]
+	}
+	return Opcodes[code]
+}
+
+// Decode decodes the instruction at the beginning of code, located at addr.
+// Missing bytes (end of code) are read as 0x00.
+func Decode(code []uint8, addr uint16) Instruction {
+	at := func(n int) uint8 {
+		if n < len(code) {
+			return code[n]
+		}
+		return 0x00
+	}
+	opcode := Lookup(at(0), at(1))
+	bytes := make([]uint8, opcode.Length)
+	for n := range bytes {
+		bytes[n] = at(n)
+	}
+	return newInstruction(0, addr, bytes, opcode)
+}
+
+// DecodeAt decodes the instruction at addr in mem
+func DecodeAt(mem Memory, addr uint16) Instruction {
+	opcode := Lookup(mem.Peek(addr), mem.Peek(addr+1))
+	bytes := make([]uint8, opcode.Length)
+	for n := range bytes {
+		bytes[n] = mem.Peek(addr + uint16(n))
+	}
+	return newInstruction(mem.Bank(addr), addr, bytes, opcode)
+}
+
+// Disassemble decodes count instructions from start in mem
+func Disassemble(mem Memory, start uint16, count int) []Instruction {
+	instructions := make([]Instruction, 0, count)
+	addr := start
+	for n := 0; n < count; n++ {
+		instruction := DecodeAt(mem, addr)
+		instructions = append(instructions, instruction)
+		addr += uint16(len(instruction.Bytes))
+	}
+	return instructions
+}
+
+// DisassembleRange decodes the instructions starting in [start, end]
+func DisassembleRange(mem Memory, start uint16, end uint16) []Instruction {
+	var instructions []Instruction
+	for addr := uint32(start); addr <= uint32(end); {
+		instruction := DecodeAt(mem, uint16(addr))
+		instructions = append(instructions, instruction)
+		addr += uint32(len(instruction.Bytes))
+	}
+	return instructions
+}
+
+func newInstruction(bank uint, addr uint16, bytes []uint8, opcode Opcode) Instruction {
+	instruction := Instruction{
+		Bank:   bank,
+		Addr:   addr,
+		Bytes:  bytes,
+		Opcode: opcode,
+		Text:   opcode.Mnemonic,
+	}
+	switch opcode.Operand {
+	case OperandNone:
+		return instruction
+	case OperandN16, OperandA16:
+		instruction.Operand = uint16(bytes[1]) | uint16(bytes[2])<<8
+	default:
+		instruction.Operand = uint16(bytes[1])
+	}
+
+	var operand string
+	switch opcode.Operand {
+	case OperandN8:
+		operand = fmt.Sprintf("$%02X", instruction.Operand)
+	case OperandN16, OperandA16:
+		operand = fmt.Sprintf("$%04X", instruction.Operand)
+	case OperandA8:
+		operand = fmt.Sprintf("$FF%02X", instruction.Operand)
+	case OperandE8:
+		operand = fmt.Sprintf("%d", int8(instruction.Operand))
+	case OperandSPE8:
+		operand = fmt.Sprintf("%+d", int8(instruction.Operand))
+	case OperandRel:
+		target, _ := instruction.Target()
+		operand = fmt.Sprintf("$%04X", target)
+	}
+	instruction.Text = fmt.Sprintf(opcode.Mnemonic, operand)
+	return instruction
+}
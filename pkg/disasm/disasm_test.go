@@ -0,0 +1,149 @@
+package disasm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		code     []uint8
+		addr     uint16
+		expected string
+		length   int
+	}{
+		{[]uint8{0x00}, 0x0100, "NOP", 1},
+		{[]uint8{0x3E, 0x12}, 0x0100, "LD A, $12", 2},
+		{[]uint8{0x21, 0x34, 0x12}, 0x0100, "LD HL, $1234", 3},
+		{[]uint8{0x2A}, 0x0100, "LD A, [HL+]", 1},
+		{[]uint8{0x46}, 0x0100, "LD B, [HL]", 1},
+		{[]uint8{0x70}, 0x0100, "LD [HL], B", 1},
+		{[]uint8{0xE0, 0x40}, 0x0100, "LDH [$FF40], A", 2},
+		{[]uint8{0xF2}, 0x0100, "LDH A, [C]", 1},
+		{[]uint8{0x18, 0xFE}, 0x0150, "JR $0150", 2},
+		{[]uint8{0x20, 0x05}, 0x0150, "JR NZ, $0157", 2},
+		{[]uint8{0xC3, 0x50, 0x01}, 0x0100, "JP $0150", 3},
+		{[]uint8{0xDC, 0x00, 0x40}, 0x0100, "CALL C, $4000", 3},
+		{[]uint8{0xFF}, 0x0100, "RST $38", 1},
+		{[]uint8{0xE8, 0xFD}, 0x0100, "ADD SP, -3", 2},
+		{[]uint8{0xF8, 0x05}, 0x0100, "LD HL, SP+5", 2},
+		{[]uint8{0xFE, 0x90}, 0x0100, "CP A, $90", 2},
+		{[]uint8{0x10, 0x00}, 0x0100, "STOP", 2},
+		{[]uint8{0xCB, 0x7C}, 0x0100, "BIT 7, H", 2},
+		{[]uint8{0xCB, 0x36}, 0x0100, "SWAP [HL]", 2},
+		{[]uint8{0xD3}, 0x0100, "DB $D3", 1},
+	}
+	for _, test := range tests {
+		instruction := Decode(test.code, test.addr)
+		if instruction.Text != test.expected || len(instruction.Bytes) != test.length {
+			t.Errorf("% X : expected %q (%d bytes), got %q (%d bytes)",
+				test.code, test.expected, test.length, instruction.Text, len(instruction.Bytes))
+		}
+	}
+}
+
+func TestOpcodesTable(t *testing.T) {
+	illegal := 0
+	for code, opcode := range Opcodes {
+		if opcode.Illegal {
+			illegal++
+			continue
+		}
+		if opcode.Cycles == 0 || opcode.CyclesTaken < opcode.Cycles || opcode.Length == 0 {
+			t.Errorf("0x%02X %q : invalid description %+v", code, opcode.Mnemonic, opcode)
+		}
+	}
+	if illegal != 11 {
+		t.Errorf("expected 11 illegal opcodes, got %d", illegal)
+	}
+}
+
+// TestOpcodesFlow checks the control flow of each opcode against its mnemonic :
+// the loads sharing bit patterns with the jumps and returns (LDH, LD [a16], ADD SP) change nothing
+func TestOpcodesFlow(t *testing.T) {
+	for code, opcode := range Opcodes {
+		expected := FlowNone
+		switch strings.SplitN(opcode.Mnemonic, " ", 2)[0] {
+		case "JP", "JR":
+			expected = FlowJump
+		case "CALL", "RST":
+			expected = FlowCall
+		case "RET", "RETI":
+			expected = FlowReturn
+		}
+		if opcode.Flow != expected {
+			t.Errorf("0x%02X %q : expected flow %d, got %d", code, opcode.Mnemonic, expected, opcode.Flow)
+		}
+		instruction := Decode([]uint8{uint8(code), 0x34, 0x12}, 0x0150)
+		if _, ok := instruction.Target(); ok != (expected != FlowNone && expected != FlowReturn && code != 0xE9) {
+			t.Errorf("0x%02X %q : target %v", code, opcode.Mnemonic, ok)
+		}
+	}
+}
+
+type testMemory []uint8
+
+func (m testMemory) Peek(addr uint16) uint8 { return m[addr] }
+func (m testMemory) Bank(addr uint16) uint {
+	if addr >= 0x4000 {
+		return 3
+	}
+	return 0
+}
+
+func TestDisassemble(t *testing.T) {
+	mem := make(testMemory, 0x10000)
+	copy(mem[0x3FFE:], []uint8{0x00, 0xCD, 0x00, 0x50, 0xC9})
+	instructions := Disassemble(mem, 0x3FFE, 3)
+	expected := []string{
+		"00:3FFE  00        NOP",
+		"00:3FFF  CD 00 50  CALL $5000",
+		"03:4002  C9        RET",
+	}
+	for n, instruction := range instructions {
+		if got := instruction.String(); got != expected[n] {
+			t.Errorf("expected %q, got %q", expected[n], got)
+		}
+	}
+	if target, ok := instructions[1].Target(); !ok || target != 0x5000 {
+		t.Errorf("CALL target : expected 0x5000, got 0x%04X (%v)", target, ok)
+	}
+	if n := len(DisassembleRange(mem, 0x3FFE, 0x4002)); n != 3 {
+		t.Errorf("expected 3 instructions in range, got %d", n)
+	}
+}
+
+type testSymbols map[uint16]string
+
+func (s testSymbols) Symbolize(bank uint, addr uint16) (string, bool) {
+	name, ok := s[addr]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s%d", name, bank), true
+}
+
+func TestSymbolic(t *testing.T) {
+	symbols := testSymbols{0x5000: "Func", 0xC000: "wVar+$1", 0xFF80: "hVar", 0x0150: "Main"}
+	tests := []struct {
+		code     []uint8
+		addr     uint16
+		expected string
+	}{
+		{[]uint8{0xCD, 0x00, 0x50}, 0x0100, "CALL Func1"}, // ROMX from ROM0 without memory
+		{[]uint8{0xC3, 0x00, 0x50}, 0x4100, "JP Func0"},   // Same bank as the instruction
+		{[]uint8{0x18, 0x4E}, 0x0100, "JR Main0"},
+		{[]uint8{0xEA, 0x00, 0xC0}, 0x0100, "LD [wVar+$10], A"},
+		{[]uint8{0xE0, 0x80}, 0x0100, "LDH [hVar0], A"},
+		{[]uint8{0x21, 0x00, 0xC0}, 0x0100, "LD HL, $C000"}, // Immediate : exact symbols only
+		{[]uint8{0x21, 0x80, 0xFF}, 0x0100, "LD HL, hVar0"},
+		{[]uint8{0xFF}, 0x0100, "RST $38"},
+	}
+	for _, test := range tests {
+		instruction := Decode(test.code, test.addr)
+		if got := instruction.Symbolic(symbols, nil); got != test.expected {
+			t.Errorf("%s : expected %q, got %q", instruction.Text, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package disasm
+
+import (
+	"fmt"
+)
+
+// OperandType describes the immediate data following an opcode
+type OperandType uint8
+
+const (
+	OperandNone OperandType = iota
+	OperandN8               // Unsigned 8 bits value
+	OperandN16              // Unsigned 16 bits value
+	OperandA8               // High memory address ($FF00 + n)
+	OperandA16              // 16 bits address
+	OperandE8               // Signed 8 bits value
+	OperandSPE8             // Signed 8 bits SP offset (LD HL, SP+e8)
+	OperandRel              // Signed 8 bits jump offset, relative to the next instruction
+)
+
+// Size returns the number of bytes used by the operand
+func (t OperandType) Size() uint8 {
+	switch t {
+	case OperandNone:
+		return 0
+	case OperandN16, OperandA16:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Flow is the change of the control flow made by an instruction
+type Flow uint8
+
+const (
+	FlowNone   Flow = iota
+	FlowJump        // JP, JR (conditional or not) and JP HL
+	FlowCall        // CALL (conditional or not) and RST
+	FlowReturn      // RET (conditional or not) and RETI
+)
+
+// Opcode describes an SM83 instruction.
+// Cycles are machine cycles (1 machine cycle = 4 clocks in normal speed).
+type Opcode struct {
+	Mnemonic    string // RGBDS syntax, %s replaced by the operand
+	Operand     OperandType
+	Length      uint8 // Bytes, opcode included
+	Cycles      uint8 // Machine cycles (condition false for conditional instructions)
+	CyclesTaken uint8 // Machine cycles when the condition is true (= Cycles if unconditional)
+	Illegal     bool  // Locks the CPU
+	Flow        Flow
+}
+
+// Opcodes contains all the SM83 opcodes. 0xCB is the prefix of CBOpcodes.
+var Opcodes [256]Opcode
+
+// CBOpcodes contains the opcodes prefixed by 0xCB. Length includes the prefix.
+var CBOpcodes [256]Opcode
+
+var (
+	r8     = [8]string{"B", "C", "D", "E", "H", "L", "[HL]", "A"}
+	r16    = [4]string{"BC", "DE", "HL", "SP"}
+	r16stk = [4]string{"BC", "DE", "HL", "AF"}
+	r16mem = [4]string{"[BC]", "[DE]", "[HL+]", "[HL-]"}
+	cond   = [4]string{"NZ", "Z", "NC", "C"}
+	alu    = [8]string{"ADD A,", "ADC A,", "SUB A,", "SBC A,", "AND A,", "XOR A,", "OR A,", "CP A,"}
+	rot    = [8]string{"RLC", "RRC", "RL", "RR", "SLA", "SRA", "SWAP", "SRL"}
+)
+
+func op(mnemonic string, operand OperandType, cycles uint8) Opcode {
+	return Opcode{
+		Mnemonic:    mnemonic,
+		Operand:     operand,
+		Length:      1 + operand.Size(),
+		Cycles:      cycles,
+		CyclesTaken: cycles,
+	}
+}
+
+func condOp(mnemonic string, operand OperandType, cycles uint8, cyclesTaken uint8) Opcode {
+	o := op(mnemonic, operand, cycles)
+	o.CyclesTaken = cyclesTaken
+	return o
+}
+
+// r8Cycles adds the memory access cycles if the register is [HL]
+func r8Cycles(reg uint8, cycles uint8, hlCycles uint8) uint8 {
+	if reg == 6 {
+		return hlCycles
+	}
+	return cycles
+}
+
+func init() {
+	for i := 0; i < 256; i++ {
+		code := uint8(i)
+		Opcodes[code] = decodeOpcode(code)
+		Opcodes[code].Flow = flowOf(code)
+		CBOpcodes[code] = decodeCBOpcode(code)
+	}
+}
+
+// flowOf returns the change of the control flow made by an opcode
+func flowOf(code uint8) Flow {
+	switch code {
+	case 0xC3, 0xC2, 0xCA, 0xD2, 0xDA, 0xE9, 0x18, 0x20, 0x28, 0x30, 0x38:
+		return FlowJump
+	case 0xCD, 0xC4, 0xCC, 0xD4, 0xDC, 0xC7, 0xCF, 0xD7, 0xDF, 0xE7, 0xEF, 0xF7, 0xFF:
+		return FlowCall
+	case 0xC9, 0xD9, 0xC0, 0xC8, 0xD0, 0xD8:
+		return FlowReturn
+	}
+	return FlowNone
+}
+
+// decodeOpcode builds the opcode description from its bit fields (xx yyy zzz)
+func decodeOpcode(code uint8) Opcode {
+	y, z := code>>3&7, code&7
+	p, q := y>>1, y&1
+
+	switch {
+	// Block 0
+	case code == 0x00:
+		return op("NOP", OperandNone, 1)
+	case code == 0x08:
+		return op("LD [%s], SP", OperandA16, 5)
+	case code == 0x10:
+		o := op("STOP", OperandNone, 1)
+		o.Length = 2 // Next byte skipped
+		return o
+	case code == 0x18:
+		return op("JR %s", OperandRel, 3)
+	case code < 0x40 && z == 0: // 20 28 30 38
+		return condOp("JR "+cond[y-4]+", %s", OperandRel, 2, 3)
+	case code < 0x40 && z == 1 && q == 0:
+		return op("LD "+r16[p]+", %s", OperandN16, 3)
+	case code < 0x40 && z == 1:
+		return op("ADD HL, "+r16[p], OperandNone, 2)
+	case code < 0x40 && z == 2 && q == 0:
+		return op("LD "+r16mem[p]+", A", OperandNone, 2)
+	case code < 0x40 && z == 2:
+		return op("LD A, "+r16mem[p], OperandNone, 2)
+	case code < 0x40 && z == 3 && q == 0:
+		return op("INC "+r16[p], OperandNone, 2)
+	case code < 0x40 && z == 3:
+		return op("DEC "+r16[p], OperandNone, 2)
+	case code < 0x40 && z == 4:
+		return op("INC "+r8[y], OperandNone, r8Cycles(y, 1, 3))
+	case code < 0x40 && z == 5:
+		return op("DEC "+r8[y], OperandNone, r8Cycles(y, 1, 3))
+	case code < 0x40 && z == 6:
+		return op("LD "+r8[y]+", %s", OperandN8, r8Cycles(y, 2, 3))
+	case code < 0x40:
+		return op([8]string{"RLCA", "RRCA", "RLA", "RRA", "DAA", "CPL", "SCF", "CCF"}[y], OperandNone, 1)
+
+	// Block 1
+	case code == 0x76:
+		return op("HALT", OperandNone, 1)
+	case code < 0x80 && z == 6:
+		return op("LD "+r8[y]+", [HL]", OperandNone, 2)
+	case code < 0x80:
+		return op("LD "+r8[y]+", "+r8[z], OperandNone, r8Cycles(y, 1, 2))
+
+	// Block 2
+	case code < 0xC0:
+		return op(alu[y]+" "+r8[z], OperandNone, r8Cycles(z, 1, 2))
+
+	// Block 3
+	case z == 0 && y < 4:
+		return condOp("RET "+cond[y], OperandNone, 2, 5)
+	case code == 0xE0:
+		return op("LDH [%s], A", OperandA8, 3)
+	case code == 0xE8:
+		return op("ADD SP, %s", OperandE8, 4)
+	case code == 0xF0:
+		return op("LDH A, [%s]", OperandA8, 3)
+	case code == 0xF8:
+		return op("LD HL, SP%s", OperandSPE8, 3)
+	case z == 1 && q == 0:
+		return op("POP "+r16stk[p], OperandNone, 3)
+	case code == 0xC9:
+		return op("RET", OperandNone, 4)
+	case code == 0xD9:
+		return op("RETI", OperandNone, 4)
+	case code == 0xE9:
+		return op("JP HL", OperandNone, 1)
+	case code == 0xF9:
+		return op("LD SP, HL", OperandNone, 2)
+	case z == 2 && y < 4:
+		return condOp("JP "+cond[y]+", %s", OperandA16, 3, 4)
+	case code == 0xE2:
+		return op("LDH [C], A", OperandNone, 2)
+	case code == 0xEA:
+		return op("LD [%s], A", OperandA16, 4)
+	case code == 0xF2:
+		return op("LDH A, [C]", OperandNone, 2)
+	case code == 0xFA:
+		return op("LD A, [%s]", OperandA16, 4)
+	case code == 0xC3:
+		return op("JP %s", OperandA16, 4)
+	case code == 0xCB:
+		return op("PREFIX CB", OperandNone, 1)
+	case code == 0xF3:
+		return op("DI", OperandNone, 1)
+	case code == 0xFB:
+		return op("EI", OperandNone, 1)
+	case z == 4 && y < 4:
+		return condOp("CALL "+cond[y]+", %s", OperandA16, 3, 6)
+	case z == 5 && q == 0:
+		return op("PUSH "+r16stk[p], OperandNone, 4)
+	case code == 0xCD:
+		return op("CALL %s", OperandA16, 6)
+	case z == 6:
+		return op(alu[y]+" %s", OperandN8, 2)
+	case z == 7:
+		return op(fmt.Sprintf("RST $%02X", y*8), OperandNone, 4)
+	}
+
+	// 0xD3, 0xDB, 0xDD, 0xE3, 0xE4, 0xEB, 0xEC, 0xED, 0xF4, 0xFC, 0xFD
+	o := op(fmt.Sprintf("DB $%02X", code), OperandNone, 1)
+	o.Illegal = true
+	return o
+}
+
+// decodeCBOpcode builds the 0xCB prefixed opcode description from its bit fields (xx yyy zzz)
+func decodeCBOpcode(code uint8) Opcode {
+	x, y, z := code>>6, code>>3&7, code&7
+	var o Opcode
+	switch x {
+	case 0:
+		o = op(rot[y]+" "+r8[z], OperandNone, r8Cycles(z, 2, 4))
+	case 1:
+		o = op(fmt.Sprintf("BIT %d, %s", y, r8[z]), OperandNone, r8Cycles(z, 2, 3))
+	case 2:
+		o = op(fmt.Sprintf("RES %d, %s", y, r8[z]), OperandNone, r8Cycles(z, 2, 4))
+	default:
+		o = op(fmt.Sprintf("SET %d, %s", y, r8[z]), OperandNone, r8Cycles(z, 2, 4))
+	}
+	o.Length = 2
+	return o
+}
@@ -12,7 +12,76 @@ import (
 
 type Emulator interface {
 	Run()
+	// Step executes one CPU instruction (or interrupt dispatch / halted cycle) without pacing
+	// and returns the machine cycles used. It must not be called concurrently with Run.
+	// With fast-forward, the idle time skipped (HALT, idle loop) is part of the Step.
+	Step() uint8
+	// Cycles returns the CPU machine cycles executed since power on
+	// (1 machine cycle = 4 clocks, twice as fast in CGB double speed).
+	Cycles() uint64
 	GetGameTitle() string
+	// SetEventHandler registers the receiver of the emulation events (CPU lock...).
+	// Must be called before Run.
+	SetEventHandler(handler coreio.EventHandler)
+	// Peek returns the value at addr as seen by the CPU, without side effects.
+	Peek(addr uint16) uint8
+	// Poke writes value at addr as the CPU would (bank switching...), without restrictions.
+	Poke(addr uint16, value uint8)
+	// Bank returns the bank currently mapped at addr (0 for unbanked areas).
+	Bank(addr uint16) uint
+	// Registers returns the current CPU registers.
+	Registers() coreio.Registers
+	// SetRegisters replaces the CPU registers.
+	SetRegisters(regs coreio.Registers)
+	// AddExecHook registers a function called before each executed instruction.
+	// Must be called before Run. Hooks run in the emulation loop : keep them fast.
+	AddExecHook(hook func(regs coreio.Registers))
+	// Break stops the execution before the current instruction : called from an exec hook, the next hooks
+	// are skipped and handler is called at the end of the Step, between two instructions. The handler
+	// can step the emulation. The instruction runs on the next Step, after the exec hooks are called again.
+	Break(handler func())
+	// AddFrameHook registers a function called at the start of each VBlank (LY = 144),
+	// between two instructions. Must be called before Run.
+	AddFrameHook(hook func())
+	// SetAccessHook registers a function called on each CPU memory access (nil to disable) :
+	// after the reads and before the writes. It observes the raw accesses, ahead of the bus hooks :
+	// values not overridden by read hooks (cheats), writes cancelled by write hooks included.
+	SetAccessHook(hook func(addr uint16, value uint8, write bool))
+	// AddBusReadHook registers a hook observing or overriding the CPU reads in [start, end]
+	// (opcode fetches included) and returns its id. Hooks are chained in registration order.
+	// Bus hooks must be added and removed from the emulation goroutine (hooks, event handler) or before Run.
+	AddBusReadHook(start, end uint16, hook coreio.ReadHook) int
+	// AddBusWriteHook registers a hook observing, overriding or cancelling the CPU writes in [start, end]
+	// and returns its id.
+	AddBusWriteHook(start, end uint16, hook coreio.WriteHook) int
+	// AddBusExecHook registers a hook called on each opcode fetch in [start, end] and returns its id.
+	AddBusExecHook(start, end uint16, hook coreio.ExecHook) int
+	// RemoveBusHook unregisters a bus hook. It returns false if id is unknown.
+	RemoveBusHook(id int) bool
+	// ROMSize returns the size of the ROM image in bytes : the file, padded with 0xFF up to
+	// the banks declared in the header if it is shorter. Overdumped files keep their size.
+	ROMSize() int
+	// SetCodeDataLog registers the flags updated on each ROM access (cdl package, nil to disable).
+	SetCodeDataLog(log []uint8)
+	// WRAMBank returns a WRAM bank without copy : 0 (C000-CFFF) or 1-7 (D000-DFFF, 2-7 in CGB mode only).
+	// It returns nil if the bank is not available.
+	WRAMBank(bank uint) []uint8
+	// HRAM returns the HRAM (FF80-FFFE) without copy.
+	HRAM() []uint8
+	// CartridgeRAM returns all the banks of the cartridge RAM without copy (nil without RAM) :
+	// bank N is at N * 0x2000.
+	CartridgeRAM() []uint8
+	// MemoryDomains returns the memory areas of the console, accessed without copy and independently
+	// of the banks mapped (VBK, SVBK, MBC) : ROM, VRAM0-1, WRAM0-7, CARTRAM0-N (0x2000 bytes banks),
+	// OAM, IO (raw registers), HRAM, BGPAL & OBJPAL (CGB palettes data).
+	// VRAM1, WRAM2-7 and the palettes are only available in CGB mode.
+	MemoryDomains() []coreio.MemoryDomain
+	// MemoryDomain returns the memory domain called name, nil if not available.
+	MemoryDomain(name string) coreio.MemoryDomain
+	// SetFastForward enables the skipping of the idle CPU time (enabled by default) : HALT and busy-wait
+	// loops polling a register or RAM run until the next change at once. The emulation result is the same,
+	// only the Steps are longer. Idle loops are not skipped while bus hooks or a code/data log are set.
+	SetFastForward(enabled bool)
 }
 
 type gbcEmulator struct {
@@ -20,7 +89,57 @@ type gbcEmulator struct {
 	cartidge cartridge.Cartridge
 }
 
-func (e *gbcEmulator) Run() { e.gbc.Run() }
+func (e *gbcEmulator) Run()                               { e.gbc.Run() }
+func (e *gbcEmulator) Cycles() uint64                     { return e.gbc.Cycles() }
+func (e *gbcEmulator) Step() uint8                        { return e.gbc.Step() }
+func (e *gbcEmulator) Peek(addr uint16) uint8             { return e.gbc.Peek(addr) }
+func (e *gbcEmulator) Poke(addr uint16, value uint8)      { e.gbc.Poke(addr, value) }
+func (e *gbcEmulator) Bank(addr uint16) uint              { return e.gbc.Bank(addr) }
+func (e *gbcEmulator) Registers() coreio.Registers        { return e.gbc.Registers() }
+func (e *gbcEmulator) SetRegisters(regs coreio.Registers) { e.gbc.SetRegisters(regs) }
+func (e *gbcEmulator) SetAccessHook(hook func(addr uint16, value uint8, write bool)) {
+	e.gbc.SetAccessHook(hook)
+}
+func (e *gbcEmulator) AddBusReadHook(start, end uint16, hook coreio.ReadHook) int {
+	return e.gbc.AddBusReadHook(start, end, hook)
+}
+func (e *gbcEmulator) AddBusWriteHook(start, end uint16, hook coreio.WriteHook) int {
+	return e.gbc.AddBusWriteHook(start, end, hook)
+}
+func (e *gbcEmulator) AddBusExecHook(start, end uint16, hook coreio.ExecHook) int {
+	return e.gbc.AddBusExecHook(start, end, hook)
+}
+func (e *gbcEmulator) RemoveBusHook(id int) bool  { return e.gbc.RemoveBusHook(id) }
+func (e *gbcEmulator) WRAMBank(bank uint) []uint8 { return e.gbc.WRAMBank(bank) }
+func (e *gbcEmulator) HRAM() []uint8              { return e.gbc.HRAM() }
+func (e *gbcEmulator) CartridgeRAM() []uint8      { return e.gbc.CartridgeRAM() }
+func (e *gbcEmulator) MemoryDomains() []coreio.MemoryDomain {
+	return e.gbc.MemoryDomains()
+}
+func (e *gbcEmulator) MemoryDomain(name string) coreio.MemoryDomain {
+	for _, d := range e.gbc.MemoryDomains() {
+		if d.Name() == name {
+			return d
+		}
+	}
+	return nil
+}
+func (e *gbcEmulator) SetCodeDataLog(log []uint8)  { e.gbc.SetCodeDataLog(log) }
+func (e *gbcEmulator) SetFastForward(enabled bool) { e.gbc.SetFastForward(enabled) }
+func (e *gbcEmulator) ROMSize() int {
+	return len(e.cartidge.ROM())
+}
+func (e *gbcEmulator) AddExecHook(hook func(regs coreio.Registers)) {
+	e.gbc.AddExecHook(hook)
+}
+func (e *gbcEmulator) Break(handler func())     { e.gbc.Break(handler) }
+func (e *gbcEmulator) AddFrameHook(hook func()) { e.gbc.AddFrameHook(hook) }
+func (e *gbcEmulator) SetEventHandler(handler coreio.EventHandler) {
+	if handler == nil {
+		handler = nullio.NewNullEventHandler()
+	}
+	e.gbc.SetEventHandler(handler)
+}
 func (e *gbcEmulator) GetGameTitle() string {
 	return cartridge.ReadTitle(e.cartidge)
 }
@@ -0,0 +1,552 @@
+// Package gdbstub exposes a debugger through the GDB remote serial protocol,
+// over a TCP or Unix socket.
+//
+// The emulation must be running (Emulator.Run) : the target is halted when GDB connects,
+// and resumed when it detaches. One GDB session is served at a time.
+// The target halts before an instruction : while the CPU runs no instruction (locked by an
+// illegal opcode, STOP, HALT without enabled interrupt), connecting and detaching a running
+// target wait until it restarts.
+//
+//	dbg := debugger.New(emu)
+//	go gdbstub.ListenAndServe("tcp", "localhost:2345", dbg)
+//	emu.Run()
+//
+// Registers are AF, BC, DE, HL, SP and PC (16 bits, numbers 0 to 5).
+// Supported packets : ? g G p P m M c s Z0-Z4 z0-z4 D k, qSupported, qXfer:features:read, QStartNoAckMode.
+package gdbstub
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jmontupet/gbcore/pkg/coreio"
+	"github.com/jmontupet/gbcore/pkg/debugger"
+)
+
+// Z packet types
+const (
+	zSoftware = 0
+	zHardware = 1
+	zWrite    = 2
+	zRead     = 3
+	zAccess   = 4
+)
+
+const targetXML = `<?xml version="1.0"?>
+<!DOCTYPE target SYSTEM "gdb-target.dtd">
+<target version="1.0">
+  <feature name="org.gnu.gdb.sm83.cpu">
+    <reg name="af" bitsize="16" type="int16" regnum="0"/>
+    <reg name="bc" bitsize="16" type="int16"/>
+    <reg name="de" bitsize="16" type="int16"/>
+    <reg name="hl" bitsize="16" type="int16"/>
+    <reg name="sp" bitsize="16" type="data_ptr"/>
+    <reg name="pc" bitsize="16" type="code_ptr"/>
+  </feature>
+</target>
+`
+
+// point is a breakpoint or watchpoint set by GDB
+type point struct {
+	kind uint8 // Z packet type
+	addr uint16
+}
+
+// Server is a GDB stub controlling the emulation through a debugger
+type Server struct {
+	dbg *debugger.Debugger
+
+	stops    chan debugger.Stop // Emulation halted (from the break handler)
+	cmds     chan func() bool   // Run in the emulation goroutine while halted. true resumes.
+	attached int32              // A GDB session is active (atomic)
+
+	points map[point]int // GDB points -> debugger IDs
+	kinds  map[int]uint8 // debugger IDs -> Z packet type
+}
+
+// NewServer returns a GDB stub for dbg. It replaces the debugger break handler.
+func NewServer(dbg *debugger.Debugger) *Server {
+	s := &Server{
+		dbg:    dbg,
+		stops:  make(chan debugger.Stop),
+		cmds:   make(chan func() bool),
+		points: make(map[point]int),
+		kinds:  make(map[int]uint8),
+	}
+	dbg.SetBreakHandler(s.onBreak)
+	return s
+}
+
+// ListenAndServe listens on the network address ("tcp" or "unix") and serves GDB sessions
+func ListenAndServe(network string, address string, dbg *debugger.Debugger) error {
+	l, err := net.Listen(network, address)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	return NewServer(dbg).Serve(l)
+}
+
+// Serve accepts the GDB connections on l, one session at a time
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		s.ServeConn(conn)
+	}
+}
+
+// onBreak halts the emulation and runs the commands of the GDB session until a resume
+func (s *Server) onBreak(stop debugger.Stop) {
+	if atomic.LoadInt32(&s.attached) == 0 {
+		return // Stale stop after a detach
+	}
+	s.stops <- stop
+	for cmd := range s.cmds {
+		if cmd() {
+			return
+		}
+	}
+}
+
+// exec runs fn in the halted emulation goroutine and waits for its completion
+func (s *Server) exec(fn func()) {
+	done := make(chan struct{})
+	s.cmds <- func() bool {
+		fn()
+		close(done)
+		return false
+	}
+	<-done
+}
+
+// resume restarts the halted emulation
+func (s *Server) resume() {
+	s.cmds <- func() bool { return true }
+}
+
+type packet struct {
+	data string
+	err  error
+}
+
+// ServeConn runs a GDB session on conn. The emulation is halted during the session,
+// except when GDB continues. It is resumed when the session ends.
+// It blocks until the target halts : not while the CPU runs no instruction.
+func (s *Server) ServeConn(conn io.ReadWriteCloser) error {
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	packets := make(chan packet)
+	go func() {
+		r := bufio.NewReader(conn)
+		for {
+			data, err := readPacket(r)
+			select {
+			case packets <- packet{data, err}:
+			case <-done:
+				return
+			}
+			if err != nil && err != errChecksum {
+				return
+			}
+		}
+	}()
+
+	atomic.StoreInt32(&s.attached, 1)
+	s.dbg.Pause()
+	sess := &session{Server: s, halted: true, lastStop: <-s.stops}
+	defer sess.detach()
+
+	for {
+		select {
+		case p := <-packets:
+			if p.err == errChecksum {
+				if !sess.noAck {
+					io.WriteString(conn, "-")
+				}
+				continue
+			}
+			if p.err == io.EOF {
+				return nil
+			}
+			if p.err != nil {
+				return p.err
+			}
+			if p.data == interruptPacket {
+				if !sess.halted {
+					s.dbg.Pause()
+				}
+				continue
+			}
+			if !sess.noAck {
+				if _, err := io.WriteString(conn, "+"); err != nil {
+					return err
+				}
+			}
+			if !sess.halted {
+				continue // Only Ctrl-C while running
+			}
+			reply, send, quit := sess.handle(p.data)
+			if send {
+				if err := writePacket(conn, reply); err != nil {
+					return err
+				}
+			}
+			if quit {
+				return nil
+			}
+		case stop := <-s.stops:
+			sess.halted = true
+			sess.lastStop = stop
+			if err := writePacket(conn, sess.stopReply(stop)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type session struct {
+	*Server
+	noAck    bool
+	halted   bool
+	lastStop debugger.Stop
+}
+
+// detach removes the GDB points and resumes the emulation.
+// A running target is halted first : it waits for the next instruction.
+func (s *session) detach() {
+	if !s.halted {
+		s.dbg.Pause()
+		<-s.stops
+	}
+	s.exec(func() {
+		for _, id := range s.points {
+			s.dbg.Remove(id)
+		}
+	})
+	s.points = make(map[point]int)
+	s.kinds = make(map[int]uint8)
+	atomic.StoreInt32(&s.attached, 0)
+	s.resume()
+}
+
+// stopReply returns the T packet for stop
+func (s *session) stopReply(stop debugger.Stop) string {
+	if stop.Reason == debugger.StopPause {
+		return "T02" // SIGINT
+	}
+	if stop.Watchpoint && stop.Access != debugger.AccessExec {
+		switch s.kinds[stop.ID] {
+		case zWrite:
+			return fmt.Sprintf("T05watch:%04x;", stop.Addr)
+		case zRead:
+			return fmt.Sprintf("T05rwatch:%04x;", stop.Addr)
+		case zAccess:
+			return fmt.Sprintf("T05awatch:%04x;", stop.Addr)
+		}
+	}
+	return "T05" // SIGTRAP
+}
+
+// handle executes a packet while halted. It returns the reply, if it must be sent,
+// and if the session ends.
+func (s *session) handle(data string) (reply string, send bool, quit bool) {
+	if data == "" {
+		return "", true, false
+	}
+	args := data[1:]
+	switch data[0] {
+	case '?':
+		return s.stopReply(s.lastStop), true, false
+	case 'g':
+		return s.readRegisters(), true, false
+	case 'G':
+		return s.writeRegisters(args), true, false
+	case 'p':
+		return s.readRegister(args), true, false
+	case 'P':
+		return s.writeRegister(args), true, false
+	case 'm':
+		return s.readMemory(args), true, false
+	case 'M':
+		return s.writeMemory(args), true, false
+	case 'Z', 'z':
+		return s.setPoint(args, data[0] == 'Z'), true, false
+	case 'c':
+		if err := s.jump(args); err != nil {
+			return "E01", true, false
+		}
+		s.halted = false
+		s.resume()
+		return "", false, false
+	case 's':
+		if err := s.jump(args); err != nil {
+			return "E01", true, false
+		}
+		var stop debugger.Stop
+		s.exec(func() { stop = s.dbg.StepIn() })
+		s.lastStop = stop
+		return s.stopReply(stop), true, false
+	case 'H':
+		return "OK", true, false
+	case 'D':
+		return "OK", true, true
+	case 'k':
+		return "", false, true
+	case 'q':
+		return s.query(data), true, false
+	case 'Q':
+		if data == "QStartNoAckMode" {
+			s.noAck = true
+			return "OK", true, false
+		}
+	}
+	return "", true, false // Unsupported
+}
+
+func (s *session) query(data string) string {
+	switch {
+	case strings.HasPrefix(data, "qSupported"):
+		return "PacketSize=1000;qXfer:features:read+;QStartNoAckMode+"
+	case data == "qAttached":
+		return "1"
+	case data == "qfThreadInfo":
+		return "m1"
+	case data == "qsThreadInfo":
+		return "l"
+	case data == "qC":
+		return "QC1"
+	case strings.HasPrefix(data, "qXfer:features:read:target.xml:"):
+		var off, length int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(data, "qXfer:features:read:target.xml:"), "%x,%x", &off, &length); err != nil {
+			return "E01"
+		}
+		if off >= len(targetXML) {
+			return "l"
+		}
+		if end := off + length; end < len(targetXML) {
+			return "m" + targetXML[off:end]
+		}
+		return "l" + targetXML[off:]
+	}
+	return ""
+}
+
+// jump sets PC to the optional address of c / s packets
+func (s *session) jump(args string) error {
+	if args == "" {
+		return nil
+	}
+	addr, err := strconv.ParseUint(args, 16, 16)
+	if err != nil {
+		return err
+	}
+	s.exec(func() {
+		regs := s.dbg.Registers()
+		regs.PC = uint16(addr)
+		s.dbg.SetRegisters(regs)
+	})
+	return nil
+}
+
+// registers returns the GDB registers (AF, BC, DE, HL, SP, PC)
+func registers(r coreio.Registers) [6]uint16 {
+	return [6]uint16{
+		uint16(r.A)<<8 | uint16(r.F),
+		uint16(r.B)<<8 | uint16(r.C),
+		uint16(r.D)<<8 | uint16(r.E),
+		uint16(r.H)<<8 | uint16(r.L),
+		r.SP,
+		r.PC,
+	}
+}
+
+func setRegister(r *coreio.Registers, n int, v uint16) {
+	switch n {
+	case 0:
+		r.A, r.F = uint8(v>>8), uint8(v)
+	case 1:
+		r.B, r.C = uint8(v>>8), uint8(v)
+	case 2:
+		r.D, r.E = uint8(v>>8), uint8(v)
+	case 3:
+		r.H, r.L = uint8(v>>8), uint8(v)
+	case 4:
+		r.SP = v
+	case 5:
+		r.PC = v
+	}
+}
+
+// Registers are sent in target byte order (little endian)
+func encodeRegister(v uint16) string { return fmt.Sprintf("%02x%02x", uint8(v), uint8(v>>8)) }
+
+func decodeRegister(s string) (uint16, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 2 {
+		return 0, fmt.Errorf("GDB : INVALID REGISTER VALUE %q", s)
+	}
+	return uint16(b[1])<<8 | uint16(b[0]), nil
+}
+
+func (s *session) readRegisters() string {
+	var regs coreio.Registers
+	s.exec(func() { regs = s.dbg.Registers() })
+	var sb strings.Builder
+	for _, v := range registers(regs) {
+		sb.WriteString(encodeRegister(v))
+	}
+	return sb.String()
+}
+
+func (s *session) writeRegisters(args string) string {
+	if len(args) < 6*4 {
+		return "E01"
+	}
+	var values [6]uint16
+	for n := range values {
+		v, err := decodeRegister(args[n*4 : n*4+4])
+		if err != nil {
+			return "E01"
+		}
+		values[n] = v
+	}
+	s.exec(func() {
+		regs := s.dbg.Registers()
+		for n, v := range values {
+			setRegister(&regs, n, v)
+		}
+		s.dbg.SetRegisters(regs)
+	})
+	return "OK"
+}
+
+func (s *session) readRegister(args string) string {
+	n, err := strconv.ParseUint(args, 16, 8)
+	if err != nil || n > 5 {
+		return "E01"
+	}
+	var regs coreio.Registers
+	s.exec(func() { regs = s.dbg.Registers() })
+	return encodeRegister(registers(regs)[n])
+}
+
+func (s *session) writeRegister(args string) string {
+	parts := strings.SplitN(args, "=", 2)
+	if len(parts) != 2 {
+		return "E01"
+	}
+	n, err := strconv.ParseUint(parts[0], 16, 8)
+	if err != nil || n > 5 {
+		return "E01"
+	}
+	v, err := decodeRegister(parts[1])
+	if err != nil {
+		return "E01"
+	}
+	s.exec(func() {
+		regs := s.dbg.Registers()
+		setRegister(&regs, int(n), v)
+		s.dbg.SetRegisters(regs)
+	})
+	return "OK"
+}
+
+// parseAddrLength parses "addr,length"
+func parseAddrLength(args string) (addr uint16, length int, err error) {
+	parts := strings.SplitN(args, ",", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("GDB : INVALID ADDRESS %q", args)
+	}
+	a, err := strconv.ParseUint(parts[0], 16, 16)
+	if err != nil {
+		return 0, 0, err
+	}
+	l, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint16(a), int(l), nil
+}
+
+func (s *session) readMemory(args string) string {
+	addr, length, err := parseAddrLength(args)
+	if err != nil {
+		return "E01"
+	}
+	var data []uint8
+	s.exec(func() { data = s.dbg.ReadMemory(addr, length) })
+	return hex.EncodeToString(data)
+}
+
+func (s *session) writeMemory(args string) string {
+	parts := strings.SplitN(args, ":", 2)
+	if len(parts) != 2 {
+		return "E01"
+	}
+	addr, length, err := parseAddrLength(parts[0])
+	if err != nil {
+		return "E01"
+	}
+	data, err := hex.DecodeString(parts[1])
+	if err != nil || len(data) != length {
+		return "E01"
+	}
+	s.exec(func() { s.dbg.WriteMemory(addr, data) })
+	return "OK"
+}
+
+// setPoint adds (Z) or removes (z) a breakpoint / watchpoint : "type,addr,kind"
+func (s *session) setPoint(args string, add bool) string {
+	parts := strings.Split(args, ",")
+	if len(parts) < 2 {
+		return "E01"
+	}
+	kind, err := strconv.ParseUint(parts[0], 16, 8)
+	if err != nil || kind > zAccess {
+		return ""
+	}
+	addr, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return "E01"
+	}
+	p := point{uint8(kind), uint16(addr)}
+
+	if !add {
+		if id, ok := s.points[p]; ok {
+			s.exec(func() { s.dbg.Remove(id) })
+			delete(s.points, p)
+			delete(s.kinds, id)
+		}
+		return "OK"
+	}
+	if _, ok := s.points[p]; ok {
+		return "OK"
+	}
+	var id int
+	s.exec(func() {
+		switch p.kind {
+		case zSoftware, zHardware:
+			id = s.dbg.AddBreakpoint(p.addr, debugger.AnyBank)
+		case zWrite:
+			id = s.dbg.AddWatchpoint(p.addr, debugger.AccessWrite)
+		case zRead:
+			id = s.dbg.AddWatchpoint(p.addr, debugger.AccessRead)
+		case zAccess:
+			id = s.dbg.AddWatchpoint(p.addr, debugger.AccessRead|debugger.AccessWrite)
+		}
+	})
+	s.points[p] = id
+	s.kinds[id] = p.kind
+	return "OK"
+}
@@ -0,0 +1,171 @@
+package gdbstub
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmontupet/gbcore/pkg/debugger"
+	"github.com/jmontupet/gbcore/pkg/emulator"
+	"github.com/jmontupet/gbcore/pkg/nullio"
+)
+
+// gdbClient is a scripted GDB client
+type gdbClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// post sends a packet without waiting for a reply (acknowledgment checked)
+func (c *gdbClient) post(data string) {
+	c.t.Helper()
+	if err := writePacket(c.conn, data); err != nil {
+		c.t.Fatal(err)
+	}
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if ack, err := c.r.ReadByte(); err != nil || ack != '+' {
+		c.t.Fatalf("%s : NO ACK (%q, %v)", data, ack, err)
+	}
+}
+
+// send sends a packet and returns the reply
+func (c *gdbClient) send(data string) string {
+	c.t.Helper()
+	c.post(data)
+	return c.reply()
+}
+
+// reply waits for the next packet from the stub
+func (c *gdbClient) reply() string {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reply, err := readPacket(c.r)
+	if err != nil {
+		c.t.Fatalf("READ REPLY : %v", err)
+	}
+	c.conn.Write([]byte("+"))
+	return reply
+}
+
+func (c *gdbClient) expect(data string, want string) {
+	c.t.Helper()
+	if got := c.send(data); got != want {
+		c.t.Errorf("%s : %q, want %q", data, got, want)
+	}
+}
+
+// testProgram :
+//
+//	0150  LD A, $05
+//	0152  CALL $0160
+//	0155  LD [$C000], A
+//	0158  JR $0150
+//	0160  INC A
+//	0161  LD B, A
+//	0162  RET
+func startTestServer(t *testing.T) (c *gdbClient, stop func()) {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x0100:], []byte{0xC3, 0x50, 0x01})
+	copy(rom[0x0150:], []byte{0x3E, 0x05, 0xCD, 0x60, 0x01, 0xEA, 0x00, 0xC0, 0x18, 0xF6})
+	copy(rom[0x0160:], []byte{0x3C, 0x47, 0xC9})
+	emu, err := emulator.NewEmulator(rom,
+		nullio.NewNullFrameDrawer(), nullio.NewNullInputsManager(), nullio.NewNullAudioPlayer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbg := debugger.New(emu)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go NewServer(dbg).Serve(l)
+	go emu.Run()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		l.Close()
+		t.Fatal(err)
+	}
+	stop = func() {
+		conn.Close()
+		l.Close()
+	}
+	return &gdbClient{t: t, conn: conn, r: bufio.NewReader(conn)}, stop
+}
+
+func TestSession(t *testing.T) {
+	c, stop := startTestServer(t)
+	defer stop()
+
+	if got := c.send("qSupported:swbreak+"); !strings.Contains(got, "qXfer:features:read+") {
+		t.Errorf("qSupported : %q", got)
+	}
+	if got := c.send("qXfer:features:read:target.xml:0,ffff"); !strings.HasPrefix(got, "l<?xml") {
+		t.Errorf("target.xml : %q", got)
+	}
+	c.expect("?", "T02")
+
+	// Breakpoint and continue
+	c.expect("Z0,160,1", "OK")
+	c.expect("c", "T05")
+	c.expect("p5", "6001") // PC = 0x0160 (little endian)
+	c.expect("z0,160,1", "OK")
+
+	// Registers
+	regs := c.send("g")
+	if len(regs) != 24 || regs[20:] != "6001" {
+		t.Fatalf("g : %q", regs)
+	}
+	c.expect("P0="+"b005", "OK") // AF = 0x05B0
+	c.expect("p0", "b005")
+
+	// Single step : INC A
+	c.expect("s", "T05")
+	c.expect("p0", "1006") // A = 0x06, carry unchanged
+	c.expect("p5", "6101")
+
+	// Single step to a breakpoint, then continue : the breakpoint stops the next loop
+	c.expect("Z0,162,1", "OK")
+	c.expect("s", "T05")
+	c.expect("p5", "6201")
+	c.expect("Mc000,1:00", "OK")
+	c.expect("c", "T05")
+	c.expect("p5", "6201")
+	c.expect("mc000,1", "06") // LD [$C000], A executed
+	c.expect("z0,162,1", "OK")
+
+	// Memory
+	c.expect("Mc100,3:0a0b0c", "OK")
+	c.expect("mc100,3", "0a0b0c")
+	c.expect("m150,2", "3e05")
+
+	// Write watchpoint
+	c.expect("Z2,c000,1", "OK")
+	c.expect("c", "T05watch:c000;")
+	c.expect("mc000,1", "06")
+	c.expect("z2,c000,1", "OK")
+
+	// Interrupt a running target
+	c.post("c")
+	c.conn.Write([]byte{0x03})
+	if got := c.reply(); got != "T02" {
+		t.Errorf("CTRL-C : %q, want T02", got)
+	}
+
+	c.expect("D", "OK")
+}
+
+func TestNoAckMode(t *testing.T) {
+	c, stop := startTestServer(t)
+	defer stop()
+	c.expect("QStartNoAckMode", "OK")
+	writePacket(c.conn, "p5")
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if got, err := readPacket(c.r); err != nil || len(got) != 4 {
+		t.Errorf("p5 WITHOUT ACK : %q, %v", got, err)
+	}
+}
@@ -0,0 +1,56 @@
+package gdbstub
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// interruptPacket is the content of the packet sent for the raw 0x03 byte (Ctrl-C)
+const interruptPacket = "\x03"
+
+var errChecksum = errors.New("GDB PACKET : INVALID CHECKSUM")
+
+// readPacket returns the content of the next packet ($data#cs) or a Ctrl-C.
+// Acknowledgments ('+' / '-') are skipped.
+func readPacket(r *bufio.Reader) (string, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		switch b {
+		case 0x03:
+			return interruptPacket, nil
+		case '$':
+			data, err := r.ReadString('#')
+			if err != nil {
+				return "", err
+			}
+			data = data[:len(data)-1]
+			var cs [2]byte
+			if _, err := io.ReadFull(r, cs[:]); err != nil {
+				return "", err
+			}
+			var sum uint8
+			if _, err := fmt.Sscanf(string(cs[:]), "%02x", &sum); err != nil || sum != checksum(data) {
+				return data, errChecksum
+			}
+			return data, nil
+		}
+	}
+}
+
+// writePacket sends data in a packet ($data#cs)
+func writePacket(w io.Writer, data string) error {
+	_, err := fmt.Fprintf(w, "$%s#%02x", data, checksum(data))
+	return err
+}
+
+func checksum(data string) (sum uint8) {
+	for i := 0; i < len(data); i++ {
+		sum += data[i]
+	}
+	return sum
+}
@@ -0,0 +1,13 @@
+package nullio
+
+import (
+	"github.com/jmontupet/gbcore/pkg/coreio"
+)
+
+type nullEventHandler struct{}
+
+func (p *nullEventHandler) HandleEvent(event coreio.Event) {}
+
+func NewNullEventHandler() coreio.EventHandler {
+	return &nullEventHandler{}
+}
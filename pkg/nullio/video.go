@@ -12,6 +12,10 @@ func (p *nullFrameDrawer) SwapFrameBuffer(
 	return frameBuffer, colors
 }
 
+func (p *nullFrameDrawer) SwapRGBFrameBuffer(frameBuffer *coreio.RGBFrameBuffer) *coreio.RGBFrameBuffer {
+	return frameBuffer
+}
+
 func NewNullFrameDrawer() coreio.FrameDrawer {
 	return &nullFrameDrawer{}
 }
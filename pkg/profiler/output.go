@@ -0,0 +1,147 @@
+package profiler
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteFolded writes the folded call stacks, one line per stack with its cycles,
+// as used by flamegraph.pl or speedscope :
+//
+//	[root];Main;UpdatePlayer 1520
+func (p *Profiler) WriteFolded(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	p.stacks(func(stack []*node, self uint64) {
+		names := make([]string, len(stack))
+		for i, n := range stack {
+			names[i] = strings.Replace(p.name(n.fn), ";", ":", -1)
+		}
+		fmt.Fprintf(bw, "%s %d\n", strings.Join(names, ";"), self)
+	})
+	return bw.Flush()
+}
+
+// WritePprof writes a gzipped pprof profile (profile.proto), readable by "go tool pprof".
+// Each function is a location whose address is bank<<16 | entry address.
+func (p *Profiler) WritePprof(w io.Writer) error {
+	strs := []string{""}
+	strIndex := map[string]uint64{"": 0}
+	str := func(s string) uint64 {
+		i, ok := strIndex[s]
+		if !ok {
+			i = uint64(len(strs))
+			strIndex[s] = i
+			strs = append(strs, s)
+		}
+		return i
+	}
+
+	var prof protoBuffer
+	// sample_type : cycles / count
+	var sampleType protoBuffer
+	sampleType.uint64Field(1, str("cycles"))
+	sampleType.uint64Field(2, str("count"))
+	prof.bytesField(1, sampleType.b)
+
+	ids := make(map[location]uint64)
+	var locations []location
+	p.stacks(func(stack []*node, self uint64) {
+		// Leaf first
+		locationIDs := make([]uint64, 0, len(stack))
+		for i := len(stack) - 1; i >= 0; i-- {
+			fn := stack[i].fn
+			id, ok := ids[fn]
+			if !ok {
+				id = uint64(len(locations) + 1)
+				ids[fn] = id
+				locations = append(locations, fn)
+			}
+			locationIDs = append(locationIDs, id)
+		}
+		var sample protoBuffer
+		sample.packedField(1, locationIDs)
+		sample.packedField(2, []uint64{self})
+		prof.bytesField(2, sample.b)
+	})
+
+	for i, loc := range locations {
+		id := uint64(i + 1)
+		var line protoBuffer
+		line.uint64Field(1, id) // function_id
+		var location protoBuffer
+		location.uint64Field(1, id)
+		if loc != rootLocation {
+			location.uint64Field(3, uint64(loc.bank)<<16|uint64(loc.addr))
+		}
+		location.bytesField(4, line.b)
+		prof.bytesField(4, location.b)
+	}
+	for i, loc := range locations {
+		var function protoBuffer
+		function.uint64Field(1, uint64(i+1))
+		function.uint64Field(2, str(p.name(loc)))
+		function.uint64Field(3, str(p.name(loc)))
+		prof.bytesField(5, function.b)
+	}
+
+	// period_type / period : 1 cycle
+	var periodType protoBuffer
+	periodType.uint64Field(1, str("cycles"))
+	periodType.uint64Field(2, str("count"))
+	for _, s := range strs {
+		prof.bytesField(6, []byte(s))
+	}
+	prof.bytesField(11, periodType.b)
+	prof.uint64Field(12, 1)
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(prof.b); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// protoBuffer encodes protobuf messages
+type protoBuffer struct {
+	b []byte
+}
+
+func (p *protoBuffer) varint(v uint64) {
+	for v >= 0x80 {
+		p.b = append(p.b, uint8(v)|0x80)
+		v >>= 7
+	}
+	p.b = append(p.b, uint8(v))
+}
+
+func (p *protoBuffer) key(field int, wireType int) {
+	p.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+// uint64Field writes a varint field (omitted if 0)
+func (p *protoBuffer) uint64Field(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	p.key(field, 0)
+	p.varint(v)
+}
+
+// bytesField writes a length delimited field (strings, messages)
+func (p *protoBuffer) bytesField(field int, data []byte) {
+	p.key(field, 2)
+	p.varint(uint64(len(data)))
+	p.b = append(p.b, data...)
+}
+
+// packedField writes a packed repeated varint field
+func (p *protoBuffer) packedField(field int, values []uint64) {
+	var packed protoBuffer
+	for _, v := range values {
+		packed.varint(v)
+	}
+	p.bytesField(field, packed.b)
+}
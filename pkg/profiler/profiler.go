@@ -0,0 +1,341 @@
+// Package profiler measures where the CPU time is spent : machine cycles per
+// bank:PC (hot spots) and per function, with the call stacks rebuilt from the
+// executed CALL / RST / RET instructions and the interrupts.
+//
+//	prof := profiler.New(emu)
+//	prof.SetSymbols(table) // Optional
+//	prof.Start()
+//	...
+//	prof.WritePprof(file)  // go tool pprof -http=: file
+//	prof.WriteFolded(file) // flamegraph.pl file > flame.svg
+//
+// The cycles between two instructions are counted for the first one :
+// interrupt dispatches are counted for the interrupted instruction and
+// the halted cycles for HALT.
+package profiler
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jmontupet/gbcore/pkg/coreio"
+	"github.com/jmontupet/gbcore/pkg/disasm"
+)
+
+// Target is the profiled emulator
+type Target interface {
+	Peek(addr uint16) uint8
+	Bank(addr uint16) uint
+	Cycles() uint64
+	AddExecHook(hook func(regs coreio.Registers))
+}
+
+// location is a bank aware address
+type location struct {
+	bank uint
+	addr uint16
+}
+
+// rootLocation identifies the bottom of the call stacks
+var rootLocation = location{bank: ^uint(0)}
+
+// node is a function in the calls tree
+type node struct {
+	fn       location // Entry point
+	parent   *node
+	children map[location]*node
+	self     uint64 // Cycles spent in the function itself
+}
+
+func (n *node) child(fn location) *node {
+	c, ok := n.children[fn]
+	if !ok {
+		c = &node{fn: fn, parent: n, children: make(map[location]*node)}
+		n.children[fn] = c
+	}
+	return c
+}
+
+// frame is an active call
+type frame struct {
+	node *node
+	sp   uint16 // Address of the return address
+}
+
+type spot struct {
+	cycles     uint64
+	executions uint64
+}
+
+// Profiler counts the cycles executed by a Target
+type Profiler struct {
+	target  Target
+	symbols disasm.Symbolizer
+	running bool
+
+	root  *node
+	stack []frame
+	spots map[location]*spot
+	total uint64
+
+	// Previous instruction
+	started    bool
+	prev       location
+	prevCode   uint8
+	prevSP     uint16
+	prevCycles uint64
+}
+
+// New attaches a profiler to target. It must be called before running the emulation.
+// The profiler does nothing until Start.
+func New(target Target) *Profiler {
+	p := &Profiler{target: target}
+	p.Reset()
+	target.AddExecHook(p.onExec)
+	return p
+}
+
+// SetSymbols names the functions and hot spots with symbols (nil : "BB:AAAA")
+func (p *Profiler) SetSymbols(symbols disasm.Symbolizer) {
+	p.symbols = symbols
+}
+
+// Start starts counting. The call stacks are rebuilt from this point.
+func (p *Profiler) Start() {
+	p.running = true
+	p.started = false
+	p.stack = p.stack[:0]
+}
+
+// Stop stops counting. The results are kept.
+func (p *Profiler) Stop() {
+	p.running = false
+}
+
+// Reset clears the results
+func (p *Profiler) Reset() {
+	p.root = &node{fn: rootLocation, children: make(map[location]*node)}
+	p.stack = nil
+	p.spots = make(map[location]*spot)
+	p.total = 0
+	p.started = false
+}
+
+// TotalCycles returns the profiled machine cycles
+func (p *Profiler) TotalCycles() uint64 {
+	return p.total
+}
+
+func (p *Profiler) current() *node {
+	if len(p.stack) == 0 {
+		return p.root
+	}
+	return p.stack[len(p.stack)-1].node
+}
+
+// onExec counts the cycles of the previous instruction and follows the calls
+func (p *Profiler) onExec(regs coreio.Registers) {
+	if !p.running {
+		return
+	}
+	now := p.target.Cycles()
+	if p.started {
+		cycles := now - p.prevCycles
+		s, ok := p.spots[p.prev]
+		if !ok {
+			s = &spot{}
+			p.spots[p.prev] = s
+		}
+		s.cycles += cycles
+		s.executions++
+		p.current().self += cycles
+		p.total += cycles
+
+		// Returned frames : SP above their return address (RET, RETI, stack reset...)
+		for len(p.stack) > 0 && regs.SP > p.stack[len(p.stack)-1].sp {
+			p.stack = p.stack[:len(p.stack)-1]
+		}
+		switch {
+		case p.interrupted(regs):
+			if disasm.Opcodes[p.prevCode].Flow == disasm.FlowCall && regs.SP == p.prevSP-4 {
+				// Interrupt before the first instruction of the callee
+				p.push(p.callTarget(), regs.SP+2)
+			}
+			p.push(regs.PC, regs.SP)
+		case p.called(regs.PC):
+			p.push(regs.PC, regs.SP)
+		}
+	}
+	p.started = true
+	p.prev = location{p.target.Bank(regs.PC), regs.PC}
+	p.prevCode = p.target.Peek(regs.PC)
+	p.prevSP = regs.SP
+	p.prevCycles = now
+}
+
+// push enters the function at addr, sp being the address of its return address
+func (p *Profiler) push(addr uint16, sp uint16) {
+	fn := location{p.target.Bank(addr), addr}
+	p.stack = append(p.stack, frame{node: p.current().child(fn), sp: sp})
+}
+
+// called returns true if pc was reached by a taken call
+func (p *Profiler) called(pc uint16) bool {
+	next := p.prev.addr + uint16(disasm.Opcodes[p.prevCode].Length)
+	return pc != next && disasm.Opcodes[p.prevCode].Flow == disasm.FlowCall
+}
+
+// interrupted returns true if regs.PC was reached by an interrupt dispatch : PC at a vector,
+// with the interrupted PC pushed below the SP left by the previous instruction
+func (p *Profiler) interrupted(regs coreio.Registers) bool {
+	switch regs.PC {
+	case 0x0040, 0x0048, 0x0050, 0x0058, 0x0060:
+	default:
+		return false
+	}
+	code := p.prevCode
+	switch disasm.Opcodes[code].Flow {
+	case disasm.FlowCall:
+		// Taken : the dispatch pushes the called address too. Not taken : PC isn't the called address.
+		return regs.SP == p.prevSP-4 || regs.PC != p.callTarget()
+	case disasm.FlowReturn:
+		// Taken : the interrupted PC is pushed over the return address
+		return regs.SP == p.prevSP || regs.SP == p.prevSP-2
+	case disasm.FlowJump:
+		return regs.SP == p.prevSP-2
+	}
+	return regs.PC != p.prev.addr+uint16(disasm.Opcodes[code].Length)
+}
+
+// callTarget returns the address called by the previous instruction
+func (p *Profiler) callTarget() uint16 {
+	if disasm.Opcodes[p.prevCode].Operand == disasm.OperandNone { // RST
+		return uint16(p.prevCode & 0x38)
+	}
+	return uint16(p.target.Peek(p.prev.addr+1)) | uint16(p.target.Peek(p.prev.addr+2))<<8
+}
+
+// name returns the symbol of loc, or "BB:AAAA"
+func (p *Profiler) name(loc location) string {
+	if loc == rootLocation {
+		return "[root]"
+	}
+	if p.symbols != nil {
+		if name, ok := p.symbols.Symbolize(loc.bank, loc.addr); ok {
+			return name
+		}
+	}
+	return disasm.FormatAddr(loc.bank, loc.addr)
+}
+
+// HotSpot is the cost of an instruction
+type HotSpot struct {
+	Bank       uint
+	Addr       uint16
+	Name       string // Symbol or "BB:AAAA"
+	Cycles     uint64
+	Executions uint64
+}
+
+// HotSpots returns the n most expensive instructions (all if n <= 0)
+func (p *Profiler) HotSpots(n int) []HotSpot {
+	spots := make([]HotSpot, 0, len(p.spots))
+	for loc, s := range p.spots {
+		spots = append(spots, HotSpot{
+			Bank:       loc.bank,
+			Addr:       loc.addr,
+			Name:       p.name(loc),
+			Cycles:     s.cycles,
+			Executions: s.executions,
+		})
+	}
+	sort.Slice(spots, func(i, j int) bool {
+		if spots[i].Cycles != spots[j].Cycles {
+			return spots[i].Cycles > spots[j].Cycles
+		}
+		return spots[i].Bank < spots[j].Bank || spots[i].Bank == spots[j].Bank && spots[i].Addr < spots[j].Addr
+	})
+	if n > 0 && n < len(spots) {
+		spots = spots[:n]
+	}
+	return spots
+}
+
+// Function is the cost of a function
+type Function struct {
+	Name  string
+	Bank  uint
+	Addr  uint16 // Entry point
+	Self  uint64 // Cycles in the function itself
+	Total uint64 // Cycles in the function and its callees
+}
+
+// Functions returns the functions sorted by total cycles
+func (p *Profiler) Functions() []Function {
+	stats := make(map[location]*Function)
+	onPath := make(map[location]int)
+	var walk func(n *node) uint64
+	walk = func(n *node) uint64 {
+		total := n.self
+		onPath[n.fn]++
+		for _, c := range n.children {
+			total += walk(c)
+		}
+		onPath[n.fn]--
+
+		f, ok := stats[n.fn]
+		if !ok {
+			f = &Function{Name: p.name(n.fn), Bank: n.fn.bank, Addr: n.fn.addr}
+			stats[n.fn] = f
+		}
+		f.Self += n.self
+		if onPath[n.fn] == 0 { // Recursive calls counted once
+			f.Total += total
+		}
+		return total
+	}
+	walk(p.root)
+
+	functions := make([]Function, 0, len(stats))
+	for _, f := range stats {
+		functions = append(functions, *f)
+	}
+	sort.Slice(functions, func(i, j int) bool {
+		if functions[i].Total != functions[j].Total {
+			return functions[i].Total > functions[j].Total
+		}
+		return functions[i].Name < functions[j].Name
+	})
+	return functions
+}
+
+// stacks calls fn for each calls tree node with its own cycles, with the stack from the root
+func (p *Profiler) stacks(fn func(stack []*node, self uint64)) {
+	var walk func(n *node, stack []*node)
+	walk = func(n *node, stack []*node) {
+		stack = append(stack, n)
+		if n.self > 0 {
+			fn(stack, n.self)
+		}
+		children := make([]*node, 0, len(n.children))
+		for _, c := range n.children {
+			children = append(children, c)
+		}
+		sort.Slice(children, func(i, j int) bool {
+			a, b := children[i].fn, children[j].fn
+			return a.bank < b.bank || a.bank == b.bank && a.addr < b.addr
+		})
+		for _, c := range children {
+			walk(c, stack)
+		}
+	}
+	walk(p.root, nil)
+}
+
+func (f Function) String() string {
+	return fmt.Sprintf("%-24s self %10d  total %10d", f.Name, f.Self, f.Total)
+}
+
+func (h HotSpot) String() string {
+	return fmt.Sprintf("%s  %-24s %10d cycles %8d executions", disasm.FormatAddr(h.Bank, h.Addr), h.Name, h.Cycles, h.Executions)
+}
@@ -0,0 +1,187 @@
+package profiler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/jmontupet/gbcore/pkg/emulator"
+	"github.com/jmontupet/gbcore/pkg/nullio"
+	"github.com/jmontupet/gbcore/pkg/symbols"
+)
+
+// Main loop (11 instructions, 37 cycles) :
+//
+//	0150  CALL Update  ; 6
+//	0153  CALL Helper  ; 6
+//	0156  JR Main      ; 3
+//	0160  CALL Helper  ; 6  Update
+//	0163  RET          ; 4
+//	0170  NOP          ; 1  Helper
+//	0171  NOP          ; 1
+//	0172  RET          ; 4
+func newTestProfiler(t *testing.T, iterations int) *Profiler {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x0100:], []byte{0xC3, 0x50, 0x01})
+	copy(rom[0x0150:], []byte{0xCD, 0x60, 0x01, 0xCD, 0x70, 0x01, 0x18, 0xF8})
+	copy(rom[0x0160:], []byte{0xCD, 0x70, 0x01, 0xC9})
+	copy(rom[0x0170:], []byte{0x00, 0x00, 0xC9})
+	emu, err := emulator.NewEmulator(rom,
+		nullio.NewNullFrameDrawer(), nullio.NewNullInputsManager(), nullio.NewNullAudioPlayer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := symbols.Parse(strings.NewReader("00:0150 Main\n00:0160 Update\n00:0170 Helper\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := New(emu)
+	p.SetSymbols(table)
+
+	emu.Step() // JP Main
+	p.Start()
+	// The last instruction is counted when the next one starts
+	for i := 0; i < iterations*11+1; i++ {
+		emu.Step()
+	}
+	p.Stop()
+	return p
+}
+
+func TestFunctions(t *testing.T) {
+	p := newTestProfiler(t, 10)
+
+	if p.TotalCycles() != 370 {
+		t.Errorf("expected 370 cycles, got %d", p.TotalCycles())
+	}
+	expected := []Function{
+		{Name: "[root]", Self: 150, Total: 370},
+		{Name: "Update", Self: 100, Total: 160},
+		{Name: "Helper", Self: 120, Total: 120},
+	}
+	functions := p.Functions()
+	if len(functions) != len(expected) {
+		t.Fatalf("expected %d functions, got %v", len(expected), functions)
+	}
+	for i, f := range functions {
+		if f.Name != expected[i].Name || f.Self != expected[i].Self || f.Total != expected[i].Total {
+			t.Errorf("expected %v, got %v", expected[i], f)
+		}
+	}
+
+	spot := p.HotSpots(1)[0]
+	if spot.Addr != 0x0172 || spot.Name != "Helper+$2" || spot.Cycles != 80 || spot.Executions != 20 {
+		t.Errorf("HOT SPOT : %v", spot)
+	}
+}
+
+// TestInterrupt checks the interrupts dispatched after a taken jump and after stack changes
+// made by ordinary instructions (ADD SP, e8 isn't a return) :
+//
+//	0040  NOP        ; 1  VBlank
+//	0041  RETI       ; 4
+//	0150  LD A, $01  ; IE : V-Blank
+//	0152  LDH [$FF], A
+//	0154  EI
+//	0155  JR @       ; 3  or  ADD SP, -2 / ADD SP, 2 (x4) then JR 0155
+func TestInterrupt(t *testing.T) {
+	stack := []byte{}
+	for i := 0; i < 4; i++ {
+		stack = append(stack, 0xE8, 0xFE, 0xE8, 0x02)
+	}
+	stack = append(stack, 0x18, 0xEE)
+	for name, loop := range map[string][]byte{"jump": {0x18, 0xFE}, "stack": stack} {
+		t.Run(name, func(t *testing.T) {
+			testInterrupt(t, loop)
+		})
+	}
+}
+
+func testInterrupt(t *testing.T, loop []byte) {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x0040:], []byte{0x00, 0xD9})
+	copy(rom[0x0100:], []byte{0xC3, 0x50, 0x01})
+	copy(rom[0x0150:], []byte{0x3E, 0x01, 0xE0, 0xFF, 0xFB})
+	copy(rom[0x0155:], loop)
+	emu, err := emulator.NewEmulator(rom,
+		nullio.NewNullFrameDrawer(), nullio.NewNullInputsManager(), nullio.NewNullAudioPlayer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := symbols.Parse(strings.NewReader("00:0040 VBlank\n00:0150 Main\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := New(emu)
+	p.SetSymbols(table)
+	p.Start()
+	for emu.Cycles() < 3*17556 {
+		emu.Step()
+	}
+	// Back in Main, up to the count of the last RETI
+	for emu.Registers().PC != 0x0155 {
+		emu.Step()
+	}
+	emu.Step()
+	p.Stop()
+
+	var vblank *Function
+	for _, f := range p.Functions() {
+		if f.Name == "VBlank" {
+			vblank = &f
+			break
+		}
+	}
+	if vblank == nil {
+		t.Fatalf("VBlank handler not called : %v", p.Functions())
+	}
+	var dispatches uint64
+	for _, spot := range p.HotSpots(0) {
+		if spot.Addr == 0x0040 {
+			dispatches = spot.Executions
+		}
+	}
+	if dispatches < 2 || vblank.Self != dispatches*5 || vblank.Total != vblank.Self {
+		t.Errorf("%d dispatches : %v", dispatches, vblank)
+	}
+}
+
+func TestWriteFolded(t *testing.T) {
+	p := newTestProfiler(t, 10)
+
+	var out bytes.Buffer
+	if err := p.WriteFolded(&out); err != nil {
+		t.Fatal(err)
+	}
+	expected := "[root] 150\n" +
+		"[root];Update 100\n" +
+		"[root];Update;Helper 60\n" +
+		"[root];Helper 60\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestWritePprof(t *testing.T) {
+	p := newTestProfiler(t, 1)
+
+	var out bytes.Buffer
+	if err := p.WritePprof(&out); err != nil {
+		t.Fatal(err)
+	}
+	r, err := gzip.NewReader(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"cycles", "[root]", "Update", "Helper"} {
+		if !bytes.Contains(data, []byte(s)) {
+			t.Errorf("%q not in the profile string table", s)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+// Package ramsearch finds the RAM addresses of game values (cheat finder).
+// WRAM (all banks), HRAM and cartridge RAM (all banks) are searched, independently of the banks mapped :
+//
+//	search := ramsearch.New(emu, ramsearch.U8)
+//	... lose a life ...
+//	search.Filter(ramsearch.Decreased, 0)
+//	... lose a life ...
+//	search.Filter(ramsearch.ChangedBy, -1)
+//	codes, err := search.Candidates()[0].GameShark(99)
+//
+// Each Filter compares the memory with the previous snapshot, then takes a new one.
+package ramsearch
+
+import (
+	"fmt"
+)
+
+// Target is the searched emulator
+type Target interface {
+	WRAMBank(bank uint) []uint8
+	HRAM() []uint8
+	CartridgeRAM() []uint8
+}
+
+// Format is the encoding of the searched values
+type Format uint8
+
+const (
+	U8    Format = iota // Unsigned byte
+	U16                 // Unsigned 16 bits, little endian
+	BCD8                // 2 BCD digits (0-99)
+	BCD16               // 4 BCD digits, little endian (0-9999, low digits first)
+)
+
+// Size returns the number of bytes of a value
+func (f Format) Size() int {
+	if f == U16 || f == BCD16 {
+		return 2
+	}
+	return 1
+}
+
+// Comparison filters the candidates
+type Comparison uint8
+
+const (
+	Equal     Comparison = iota // Value == operand
+	NotEqual                    // Value != operand
+	Greater                     // Value > operand
+	Less                        // Value < operand
+	Changed                     // Value != previous value
+	Unchanged                   // Value == previous value
+	Increased                   // Value > previous value
+	Decreased                   // Value < previous value
+	ChangedBy                   // Value - previous value == operand
+)
+
+// Area is a searched RAM
+type Area uint8
+
+const (
+	WRAM Area = iota
+	HRAM
+	CartRAM
+)
+
+func (a Area) String() string {
+	switch a {
+	case WRAM:
+		return "WRAM"
+	case HRAM:
+		return "HRAM"
+	case CartRAM:
+		return "CARTRAM"
+	default:
+		return fmt.Sprintf("Area(%d)", uint8(a))
+	}
+}
+
+// Address is a bank aware RAM address
+type Address struct {
+	Area Area
+	Bank uint
+	Addr uint16
+}
+
+func (a Address) String() string {
+	return fmt.Sprintf("%s %02X:%04X", a.Area, a.Bank, a.Addr)
+}
+
+// region is a RAM bank
+type region struct {
+	area  Area
+	bank  uint
+	start uint16  // CPU address of the first byte
+	data  []uint8 // Emulator memory, not a copy
+}
+
+// candidate is the offset of a value in a region
+type candidate struct {
+	region int
+	offset int
+}
+
+// Search is a RAM search in progress
+type Search struct {
+	format     Format
+	regions    []region
+	cgb        bool      // WRAM banks 2-7 available
+	previous   [][]uint8 // Snapshot of the regions
+	candidates []candidate
+}
+
+// New starts a search of format values : all the RAM addresses are candidates
+func New(target Target, format Format) *Search {
+	s := &Search{format: format}
+	for bank := uint(0); bank < 8; bank++ {
+		data := target.WRAMBank(bank)
+		if data == nil {
+			break
+		}
+		start := uint16(0xD000)
+		if bank == 0 {
+			start = 0xC000
+		}
+		s.regions = append(s.regions, region{area: WRAM, bank: bank, start: start, data: data})
+		s.cgb = bank > 1
+	}
+	s.regions = append(s.regions, region{area: HRAM, start: 0xFF80, data: target.HRAM()})
+	ram := target.CartridgeRAM()
+	for bank := 0; bank*0x2000 < len(ram); bank++ {
+		end := (bank + 1) * 0x2000
+		if end > len(ram) {
+			end = len(ram)
+		}
+		s.regions = append(s.regions, region{area: CartRAM, bank: uint(bank), start: 0xA000, data: ram[bank*0x2000 : end]})
+	}
+	s.Reset()
+	return s
+}
+
+// Reset restarts the search : all the addresses are candidates and a new snapshot is taken
+func (s *Search) Reset() {
+	s.candidates = s.candidates[:0]
+	for i, r := range s.regions {
+		for offset := 0; offset+s.format.Size() <= len(r.data); offset++ {
+			s.candidates = append(s.candidates, candidate{region: i, offset: offset})
+		}
+	}
+	s.Snapshot()
+}
+
+// Snapshot takes a new snapshot, compared by the next Filter
+func (s *Search) Snapshot() {
+	if s.previous == nil {
+		s.previous = make([][]uint8, len(s.regions))
+		for i, r := range s.regions {
+			s.previous[i] = make([]uint8, len(r.data))
+		}
+	}
+	for i, r := range s.regions {
+		copy(s.previous[i], r.data)
+	}
+}
+
+// Count returns the number of candidates
+func (s *Search) Count() int {
+	return len(s.candidates)
+}
+
+// Filter keeps the candidates matching cmp and takes a new snapshot.
+// operand is the value compared (Equal to Less) or the difference (ChangedBy).
+// It returns the number of candidates left.
+func (s *Search) Filter(cmp Comparison, operand int) int {
+	kept := s.candidates[:0]
+	for _, c := range s.candidates {
+		value, ok := s.format.decode(s.regions[c.region].data, c.offset)
+		if !ok {
+			continue
+		}
+		previous, previousOK := s.format.decode(s.previous[c.region], c.offset)
+		var match bool
+		switch cmp {
+		case Equal:
+			match = value == operand
+		case NotEqual:
+			match = value != operand
+		case Greater:
+			match = value > operand
+		case Less:
+			match = value < operand
+		case Changed:
+			match = previousOK && value != previous
+		case Unchanged:
+			match = previousOK && value == previous
+		case Increased:
+			match = previousOK && value > previous
+		case Decreased:
+			match = previousOK && value < previous
+		case ChangedBy:
+			match = previousOK && value-previous == operand
+		}
+		if match {
+			kept = append(kept, c)
+		}
+	}
+	s.candidates = kept
+	s.Snapshot()
+	return len(s.candidates)
+}
+
+// Candidate is an address matching all the filters
+type Candidate struct {
+	Address
+	Value    int
+	Previous int // Value in the last snapshot
+
+	format Format
+	cgb    bool
+}
+
+// Candidates returns the addresses matching all the filters
+func (s *Search) Candidates() []Candidate {
+	candidates := make([]Candidate, 0, len(s.candidates))
+	for _, c := range s.candidates {
+		r := s.regions[c.region]
+		value, _ := s.format.decode(r.data, c.offset)
+		previous, _ := s.format.decode(s.previous[c.region], c.offset)
+		candidates = append(candidates, Candidate{
+			Address:  Address{Area: r.area, Bank: r.bank, Addr: r.start + uint16(c.offset)},
+			Value:    value,
+			Previous: previous,
+			format:   s.format,
+			cgb:      s.cgb,
+		})
+	}
+	return candidates
+}
+
+// GameShark returns the GameShark codes (one per byte) setting the candidate to value.
+// WRAM banks are selected by the code in CGB mode. The cartridge RAM can only be set for
+// the bank 0 (codes written in the bank currently mapped).
+func (c Candidate) GameShark(value int) ([]string, error) {
+	data, err := c.format.encode(value)
+	if err != nil {
+		return nil, err
+	}
+	codeType := 0x01
+	switch {
+	case c.Area == WRAM && c.Addr >= 0xD000 && c.cgb:
+		codeType = 0x90 | int(c.Bank)
+	case c.Area == CartRAM && c.Bank > 0:
+		return nil, fmt.Errorf("CARTRIDGE RAM BANK %d NOT ADDRESSABLE BY GAMESHARK CODES", c.Bank)
+	}
+	codes := make([]string, len(data))
+	for i, b := range data {
+		addr := c.Addr + uint16(i)
+		codes[i] = fmt.Sprintf("%02X%02X%02X%02X", codeType, b, addr&0xFF, addr>>8)
+	}
+	return codes, nil
+}
+
+// decode returns the value at offset. ok is false for invalid BCD values.
+func (f Format) decode(data []uint8, offset int) (value int, ok bool) {
+	switch f {
+	case U16:
+		return int(data[offset]) | int(data[offset+1])<<8, true
+	case BCD8:
+		return decodeBCD(data[offset])
+	case BCD16:
+		lo, okLo := decodeBCD(data[offset])
+		hi, okHi := decodeBCD(data[offset+1])
+		return hi*100 + lo, okLo && okHi
+	default:
+		return int(data[offset]), true
+	}
+}
+
+func decodeBCD(b uint8) (int, bool) {
+	if b>>4 > 9 || b&0x0F > 9 {
+		return 0, false
+	}
+	return int(b>>4)*10 + int(b&0x0F), true
+}
+
+func encodeBCD(v int) uint8 {
+	return uint8(v/10)<<4 | uint8(v%10)
+}
+
+// encode returns the bytes of value
+func (f Format) encode(value int) ([]uint8, error) {
+	max := map[Format]int{U8: 0xFF, U16: 0xFFFF, BCD8: 99, BCD16: 9999}[f]
+	if value < 0 || value > max {
+		return nil, fmt.Errorf("VALUE %d OUT OF RANGE (0-%d)", value, max)
+	}
+	switch f {
+	case U16:
+		return []uint8{uint8(value), uint8(value >> 8)}, nil
+	case BCD8:
+		return []uint8{encodeBCD(value)}, nil
+	case BCD16:
+		return []uint8{encodeBCD(value % 100), encodeBCD(value / 100)}, nil
+	default:
+		return []uint8{uint8(value)}, nil
+	}
+}
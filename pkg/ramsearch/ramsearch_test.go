@@ -0,0 +1,116 @@
+package ramsearch
+
+import (
+	"testing"
+
+	"github.com/jmontupet/gbcore/pkg/cheats"
+	"github.com/jmontupet/gbcore/pkg/emulator"
+	"github.com/jmontupet/gbcore/pkg/nullio"
+)
+
+func newTestEmulator(t *testing.T, cgb bool) emulator.Emulator {
+	rom := make([]byte, 0x8000)
+	if cgb {
+		rom[0x0143] = 0x80
+	}
+	copy(rom[0x0100:], []byte{0xC3, 0x50, 0x01})
+	copy(rom[0x0150:], []byte{0x18, 0xFE}) // JR $0150
+	emu, err := emulator.NewEmulator(rom,
+		nullio.NewNullFrameDrawer(), nullio.NewNullInputsManager(), nullio.NewNullAudioPlayer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return emu
+}
+
+func TestSearchLives(t *testing.T) {
+	emu := newTestEmulator(t, false)
+	emu.Poke(0xC123, 3) // Lives
+	emu.Poke(0xC200, 3) // Other value
+	search := New(emu, U8)
+
+	emu.Poke(0xC123, 2)
+	emu.Poke(0xC200, 2)
+	search.Filter(ChangedBy, -1)
+	emu.Poke(0xC123, 1)
+	if n := search.Filter(ChangedBy, -1); n != 1 {
+		t.Fatalf("expected 1 candidate, got %d : %v", n, search.Candidates())
+	}
+	c := search.Candidates()[0]
+	expected := Address{Area: WRAM, Bank: 0, Addr: 0xC123}
+	if c.Address != expected || c.Value != 1 || c.Previous != 1 {
+		t.Fatalf("unexpected candidate %+v", c)
+	}
+
+	codes, err := c.GameShark(9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 1 || codes[0] != "010923C1" {
+		t.Fatalf("unexpected codes %v", codes)
+	}
+	cheat, err := cheats.Parse(codes[0])
+	if err != nil || cheat.Addr != 0xC123 || cheat.Value != 9 {
+		t.Errorf("code %s : %+v, %v", codes[0], cheat, err)
+	}
+	if _, err := c.GameShark(256); err == nil {
+		t.Errorf("expected an out of range error")
+	}
+
+	search.Reset()
+	if n := search.Filter(Unchanged, 0); n != 0x2000+0x7F { // DMG WRAM, HRAM
+		t.Errorf("reset : expected all the addresses, got %d", n)
+	}
+}
+
+func TestSearchBCD16Bank(t *testing.T) {
+	emu := newTestEmulator(t, true)
+	emu.Poke(0xFF70, 3)
+	emu.Poke(0xD456, 0x34) // Score 1234
+	emu.Poke(0xD457, 0x12)
+	emu.Poke(0xFF70, 1)
+	emu.Poke(0xD456, 0x12) // Same address, other bank
+
+	search := New(emu, BCD16)
+	search.Filter(Equal, 1234)
+	emu.Poke(0xFF70, 3)
+	emu.Poke(0xD456, 0x50) // 1250
+	if n := search.Filter(Increased, 0); n != 1 {
+		t.Fatalf("expected 1 candidate, got %d : %v", n, search.Candidates())
+	}
+	c := search.Candidates()[0]
+	expected := Address{Area: WRAM, Bank: 3, Addr: 0xD456}
+	if c.Address != expected || c.Value != 1250 {
+		t.Fatalf("unexpected candidate %+v", c)
+	}
+	codes, err := c.GameShark(9999)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 2 || codes[0] != "939956D4" || codes[1] != "939957D4" {
+		t.Fatalf("unexpected codes %v", codes)
+	}
+}
+
+func TestFormats(t *testing.T) {
+	data := []uint8{0x34, 0x12, 0x9A}
+	tests := []struct {
+		format Format
+		offset int
+		value  int
+		ok     bool
+	}{
+		{U8, 2, 0x9A, true},
+		{U16, 0, 0x1234, true},
+		{BCD8, 0, 34, true},
+		{BCD8, 2, 0, false},
+		{BCD16, 0, 1234, true},
+		{BCD16, 1, 0, false},
+	}
+	for _, test := range tests {
+		value, ok := test.format.decode(data, test.offset)
+		if ok != test.ok || ok && value != test.value {
+			t.Errorf("format %d offset %d : expected %d %v, got %d %v", test.format, test.offset, test.value, test.ok, value, ok)
+		}
+	}
+}
@@ -0,0 +1,350 @@
+// Package rgbds exports a ROM as RGBDS source files, one per bank, to be edited and reassembled :
+//
+//	rgbds.Export(rom, "src", rgbds.Options{CDL: log.Bytes(), Symbols: table})
+//
+//	cd src && rgbasm -o game.o game.asm && rgblink -o game.gb game.o
+//
+// The code is the instructions executed in the CDL (see package cdl) or, without CDL,
+// the instructions reached from the entry point and the interrupt vectors (jumps and
+// calls in the same bank or to bank 0). The other bytes are exported as "db" data.
+// The jump and call targets get generated labels, unless named by the symbols.
+//
+// The reassembled ROM is identical to the original : the instructions which could be
+// encoded differently by the assembler are exported as "db" with a comment.
+package rgbds
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jmontupet/gbcore/pkg/cdl"
+	"github.com/jmontupet/gbcore/pkg/disasm"
+	"github.com/jmontupet/gbcore/pkg/symbols"
+)
+
+const bankSize = 0x4000
+
+// MainFile is the file including all the banks
+const MainFile = "game.asm"
+
+// Options of the export
+type Options struct {
+	CDL     []uint8        // Code/data log of the ROM (cdl package), nil to find the code from the entry points
+	Symbols *symbols.Table // Names of the ROM addresses, nil for generated names only
+}
+
+// entryPoints are the addresses executed by the hardware : boot and interrupt vectors
+var entryPoints = []uint16{0x0100, 0x0040, 0x0048, 0x0050, 0x0058, 0x0060}
+
+type exporter struct {
+	rom     []uint8
+	options Options
+	code    map[int]disasm.Instruction // Instructions by ROM offset
+	inCode  []bool                     // Bytes of the instructions
+	labels  map[int]string             // Labels by ROM offset
+}
+
+// Export writes the source files of rom in dir : MainFile and one "bank_BBB.asm" per bank
+func Export(rom []uint8, dir string, options Options) error {
+	if len(rom) < 2*bankSize || len(rom)%bankSize != 0 {
+		return fmt.Errorf("INVALID ROM SIZE : 0x%X BYTES", len(rom))
+	}
+	if options.CDL != nil && len(options.CDL) != len(rom) {
+		return fmt.Errorf("CDL SIZE MISMATCH : 0x%X BYTES, ROM 0x%X BYTES", len(options.CDL), len(rom))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	e := &exporter{
+		rom:     rom,
+		options: options,
+		code:    make(map[int]disasm.Instruction),
+		inCode:  make([]bool, len(rom)),
+		labels:  make(map[int]string),
+	}
+	if options.CDL != nil {
+		e.findLoggedCode()
+	} else {
+		e.findReachableCode()
+	}
+	e.addLabels()
+
+	f, err := os.Create(filepath.Join(dir, MainFile))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "; rgbasm -o game.o %s && rgblink -o game.gb game.o\n\n", MainFile)
+	for bank := 0; bank < len(rom)/bankSize; bank++ {
+		name := fmt.Sprintf("bank_%03X.asm", bank)
+		if err := e.writeBankFile(filepath.Join(dir, name), bank); err != nil {
+			return err
+		}
+		fmt.Fprintf(f, "INCLUDE \"%s\"\n", name)
+	}
+	return f.Close()
+}
+
+// location returns the bank and CPU address of a ROM offset
+func location(offset int) (bank uint, addr uint16) {
+	bank = uint(offset / bankSize)
+	addr = uint16(offset % bankSize)
+	if bank > 0 {
+		addr += bankSize
+	}
+	return bank, addr
+}
+
+// offsetOf returns the ROM offset of addr seen from bank, if known
+func (e *exporter) offsetOf(bank uint, addr uint16) (int, bool) {
+	switch {
+	case addr < bankSize:
+		return int(addr), true
+	case addr < 2*bankSize && bank > 0 && int(bank) < len(e.rom)/bankSize:
+		return int(bank)*bankSize + int(addr-bankSize), true
+	case addr < 2*bankSize && len(e.rom) == 2*bankSize: // Only one switchable bank
+		return int(addr), true
+	default:
+		return 0, false
+	}
+}
+
+// decode decodes the instruction at offset. ok is false if it is illegal or crosses the end of the bank.
+func (e *exporter) decode(offset int) (instruction disasm.Instruction, ok bool) {
+	end := (offset/bankSize + 1) * bankSize
+	bank, addr := location(offset)
+	instruction = disasm.Decode(e.rom[offset:end], addr)
+	instruction.Bank = bank
+	if instruction.Opcode.Illegal || offset+len(instruction.Bytes) > end {
+		return instruction, false
+	}
+	return instruction, true
+}
+
+// addCode marks the instruction at offset as code, if it doesn't overlap other instructions
+func (e *exporter) addCode(offset int) (disasm.Instruction, bool) {
+	instruction, ok := e.decode(offset)
+	if !ok {
+		return instruction, false
+	}
+	for i := range instruction.Bytes {
+		if e.inCode[offset+i] {
+			return instruction, false
+		}
+	}
+	for i := range instruction.Bytes {
+		e.inCode[offset+i] = true
+	}
+	e.code[offset] = instruction
+	return instruction, true
+}
+
+// findLoggedCode marks the instructions executed in the CDL
+func (e *exporter) findLoggedCode() {
+	for offset, flags := range e.options.CDL {
+		if flags&cdl.Code != 0 {
+			e.addCode(offset)
+		}
+	}
+}
+
+// findReachableCode marks the instructions reached from the entry points
+func (e *exporter) findReachableCode() {
+	var pending []int
+	for _, addr := range entryPoints {
+		pending = append(pending, int(addr))
+	}
+	for len(pending) > 0 {
+		offset := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for {
+			if _, done := e.code[offset]; done {
+				break
+			}
+			instruction, ok := e.addCode(offset)
+			if !ok {
+				break
+			}
+			if target, ok := instruction.Target(); ok {
+				if targetOffset, ok := e.offsetOf(instruction.Bank, target); ok {
+					pending = append(pending, targetOffset)
+				}
+			}
+			if endsFlow(instruction.Bytes[0]) {
+				break
+			}
+			offset += len(instruction.Bytes)
+		}
+	}
+}
+
+// endsFlow returns true if the next instruction is never executed after code
+func endsFlow(code uint8) bool {
+	switch code {
+	case 0xC3, 0x18, 0xE9, 0xC9, 0xD9: // JP, JR, JP HL, RET, RETI
+		return true
+	}
+	return false
+}
+
+// addLabels names the jump and call targets and the symbols of the ROM
+func (e *exporter) addLabels() {
+	names := make(map[string]bool)
+	if e.options.Symbols != nil {
+		for _, symbol := range e.options.Symbols.Symbols() {
+			offset, ok := e.offsetOf(symbol.Bank, symbol.Addr)
+			if !ok || symbol.Bank != uint(offset/bankSize) || names[symbol.Name] {
+				continue
+			}
+			if _, labeled := e.labels[offset]; labeled || !e.labelAllowed(offset) {
+				continue
+			}
+			e.labels[offset] = symbol.Name
+			names[symbol.Name] = true
+		}
+	}
+	offsets := make([]int, 0, len(e.code))
+	for offset := range e.code {
+		offsets = append(offsets, offset)
+	}
+	sort.Ints(offsets)
+	for _, offset := range offsets {
+		instruction := e.code[offset]
+		target, ok := instruction.Target()
+		if !ok {
+			continue
+		}
+		targetOffset, ok := e.offsetOf(instruction.Bank, target)
+		if _, labeled := e.labels[targetOffset]; !ok || labeled || !e.labelAllowed(targetOffset) {
+			continue
+		}
+		prefix := "Jump"
+		if instruction.Opcode.Flow == disasm.FlowCall {
+			prefix = "Call"
+		}
+		bank, addr := location(targetOffset)
+		e.labels[targetOffset] = fmt.Sprintf("%s_%03X_%04X", prefix, bank, addr)
+	}
+}
+
+// labelAllowed returns true if offset is not inside an instruction
+func (e *exporter) labelAllowed(offset int) bool {
+	if !e.inCode[offset] {
+		return true
+	}
+	_, start := e.code[offset]
+	return start
+}
+
+// Symbolize names the labeled addresses (disasm.Symbolizer)
+func (e *exporter) Symbolize(bank uint, addr uint16) (string, bool) {
+	offset, ok := e.offsetOf(bank, addr)
+	if !ok {
+		return "", false
+	}
+	name, ok := e.labels[offset]
+	return name, ok
+}
+
+// source returns the assembly of an instruction
+func (e *exporter) source(instruction disasm.Instruction) string {
+	code := instruction.Bytes[0]
+	switch {
+	// Assemblers may optimize LD [$FF00+n] into LDH, or add a NOP after HALT
+	case (code == 0xEA || code == 0xFA) && instruction.Operand >= 0xFF00,
+		code == 0x76,
+		code == 0x10 && instruction.Bytes[1] != 0x00:
+		return fmt.Sprintf("%s ; %s", dataLine(instruction.Bytes), instruction.Text)
+	}
+	// The bank mapped in the switchable area is unknown from bank 0
+	if target, ok := instruction.Target(); ok && instruction.Bank == 0 && target >= bankSize && len(e.rom) > 2*bankSize {
+		return instruction.Text
+	}
+	return instruction.Symbolic(e, nil)
+}
+
+// minFill is the length of the repeated bytes exported as "ds" instead of "db"
+const minFill = 32
+
+// fillLength returns the number of data bytes equal to the one at offset, without labels
+func (e *exporter) fillLength(offset int, end int) int {
+	n := 1
+	for i := offset + 1; i < end && e.rom[i] == e.rom[offset] && !e.inCode[i]; i++ {
+		if _, labeled := e.labels[i]; labeled {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// dataLine returns a "db" directive
+func dataLine(data []uint8) string {
+	values := make([]string, len(data))
+	for i, b := range data {
+		values[i] = fmt.Sprintf("$%02X", b)
+	}
+	return "db " + strings.Join(values, ", ")
+}
+
+func (e *exporter) writeBankFile(path string, bank int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	e.writeBank(w, bank)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeBank writes the section of a bank
+func (e *exporter) writeBank(w io.Writer, bank int) {
+	if bank == 0 {
+		fmt.Fprintf(w, "SECTION \"ROM Bank $000\", ROM0[$0000]\n")
+	} else {
+		fmt.Fprintf(w, "SECTION \"ROM Bank $%03X\", ROMX[$4000], BANK[$%X]\n", bank, bank)
+	}
+
+	var data []uint8
+	flush := func() {
+		if len(data) > 0 {
+			fmt.Fprintf(w, "\t%s\n", dataLine(data))
+			data = data[:0]
+		}
+	}
+	end := (bank + 1) * bankSize
+	for offset := bank * bankSize; offset < end; {
+		if label, ok := e.labels[offset]; ok {
+			flush()
+			fmt.Fprintf(w, "\n%s:\n", label)
+		}
+		if instruction, ok := e.code[offset]; ok {
+			flush()
+			fmt.Fprintf(w, "\t%s\n", e.source(instruction))
+			offset += len(instruction.Bytes)
+			continue
+		}
+		if n := e.fillLength(offset, end); n >= minFill {
+			flush()
+			fmt.Fprintf(w, "\tds %d, $%02X\n", n, e.rom[offset])
+			offset += n
+			continue
+		}
+		data = append(data, e.rom[offset])
+		offset++
+		if offset%16 == 0 {
+			flush()
+		}
+	}
+	flush()
+}
@@ -0,0 +1,185 @@
+package rgbds
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jmontupet/gbcore/pkg/cdl"
+	"github.com/jmontupet/gbcore/pkg/symbols"
+)
+
+// testROM (4 banks) :
+//
+//	0040  RETI (interrupt vectors)
+//	0100  NOP
+//	0101  JP $0150
+//	0150  CALL $0160
+//	0153  LD A, [$FF44]
+//	0156  HALT
+//	0157  JR $0150
+//	0160  LD HL, $4000
+//	0163  RET
+//	0170  "HELLO"
+//	01:4000  LD A, $01  (only executed in the CDL)
+//	01:4002  JR $4000
+func testROM() []uint8 {
+	rom := make([]uint8, 4*bankSize)
+	for addr := 0x40; addr <= 0x60; addr += 8 {
+		rom[addr] = 0xD9
+	}
+	copy(rom[0x0100:], []uint8{0x00, 0xC3, 0x50, 0x01})
+	copy(rom[0x0150:], []uint8{0xCD, 0x60, 0x01, 0xFA, 0x44, 0xFF, 0x76, 0x18, 0xF7})
+	copy(rom[0x0160:], []uint8{0x21, 0x00, 0x40, 0xC9})
+	copy(rom[0x0170:], "HELLO")
+	copy(rom[0x4000:], []uint8{0x3E, 0x01, 0x18, 0xFC})
+	return rom
+}
+
+func export(t *testing.T, rom []uint8, options Options) (dir string, files map[string]string) {
+	dir, err := ioutil.TempDir("", "rgbds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Export(rom, dir, options); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	files = make(map[string]string)
+	names, _ := filepath.Glob(filepath.Join(dir, "*.asm"))
+	for _, name := range names {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[filepath.Base(name)] = string(data)
+	}
+	return dir, files
+}
+
+func expectLines(t *testing.T, file string, source string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(source, line+"\n") {
+			t.Errorf("%s : %q not found in\n%s", file, line, source)
+		}
+	}
+}
+
+func TestExportReachableCode(t *testing.T) {
+	dir, files := export(t, testROM(), Options{})
+	defer os.RemoveAll(dir)
+
+	if len(files) != 5 {
+		t.Fatalf("expected 5 files, got %d", len(files))
+	}
+	expectLines(t, "bank_000.asm", files["bank_000.asm"],
+		`SECTION "ROM Bank $000", ROM0[$0000]`,
+		"\tJP Jump_000_0150",
+		"Jump_000_0150:",
+		"\tCALL Call_000_0160",
+		"\tdb $FA, $44, $FF ; LD A, [$FF44]",
+		"\tdb $76 ; HALT",
+		"\tJR Jump_000_0150",
+		"Call_000_0160:",
+		"\tLD HL, $4000",
+		"\tRET",
+		"\tdb $48, $45, $4C, $4C, $4F",
+		"\tds 16011, $00",
+	)
+	expectLines(t, "bank_001.asm", files["bank_001.asm"],
+		`SECTION "ROM Bank $001", ROMX[$4000], BANK[$1]`,
+		"\tdb $3E, $01, $18, $FC",
+	)
+	expectLines(t, MainFile, files[MainFile],
+		`INCLUDE "bank_000.asm"`,
+		`INCLUDE "bank_003.asm"`,
+	)
+}
+
+// TestExportGolden compares the files exported for testROM to testdata, checked without RGBDS
+func TestExportGolden(t *testing.T) {
+	dir, files := export(t, testROM(), Options{})
+	defer os.RemoveAll(dir)
+
+	golden, _ := filepath.Glob(filepath.Join("testdata", "*.asm"))
+	if len(golden) != len(files) {
+		t.Fatalf("expected %d files, got %d", len(golden), len(files))
+	}
+	for _, name := range golden {
+		want, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := files[filepath.Base(name)]; got != string(want) {
+			t.Errorf("%s :\n%s\nwant\n%s", filepath.Base(name), got, want)
+		}
+	}
+}
+
+func TestExportLoggedCode(t *testing.T) {
+	rom := testROM()
+	log := make([]uint8, len(rom))
+	for _, offset := range []int{0x0100, 0x0101, 0x4000, 0x4002} {
+		log[offset] = cdl.Code
+	}
+	table, err := symbols.Parse(strings.NewReader("00:0150 Main\n01:4002 Loop\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, files := export(t, rom, Options{CDL: log, Symbols: table})
+	defer os.RemoveAll(dir)
+
+	expectLines(t, "bank_000.asm", files["bank_000.asm"],
+		"\tJP Main",
+		"Main:",
+		"\tdb $CD, $60, $01, $FA, $44, $FF, $76, $18, $F7, $00, $00, $00, $00, $00, $00, $00",
+	)
+	expectLines(t, "bank_001.asm", files["bank_001.asm"],
+		"Jump_001_4000:",
+		"\tLD A, $01",
+		"Loop:",
+		"\tJR Jump_001_4000",
+	)
+}
+
+func TestExportErrors(t *testing.T) {
+	if err := Export(make([]uint8, 0x6000), "", Options{}); err == nil {
+		t.Error("expected an error for a ROM of 0x6000 bytes")
+	}
+	if err := Export(testROM(), "", Options{CDL: make([]uint8, 0x8000)}); err == nil {
+		t.Error("expected an error for a CDL of another size")
+	}
+}
+
+// TestReassemble checks that RGBDS builds the original ROM (skipped without RGBDS)
+func TestReassemble(t *testing.T) {
+	if _, err := exec.LookPath("rgbasm"); err != nil {
+		t.Skip("RGBDS NOT FOUND")
+	}
+	rom := testROM()
+	dir, _ := export(t, rom, Options{})
+	defer os.RemoveAll(dir)
+
+	for _, args := range [][]string{
+		{"rgbasm", "-o", "game.o", MainFile},
+		{"rgblink", "-o", "game.gb", "game.o"},
+	} {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%s : %v\n%s", args[0], err, out)
+		}
+	}
+	built, err := ioutil.ReadFile(filepath.Join(dir, "game.gb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(built, rom) {
+		t.Error("REASSEMBLED ROM DIFFERS FROM THE ORIGINAL")
+	}
+}
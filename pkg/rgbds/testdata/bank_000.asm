@@ -0,0 +1,29 @@
+SECTION "ROM Bank $000", ROM0[$0000]
+	ds 64, $00
+	RETI
+	db $00, $00, $00, $00, $00, $00, $00
+	RETI
+	db $00, $00, $00, $00, $00, $00, $00
+	RETI
+	db $00, $00, $00, $00, $00, $00, $00
+	RETI
+	db $00, $00, $00, $00, $00, $00, $00
+	RETI
+	ds 159, $00
+	NOP
+	JP Jump_000_0150
+	ds 76, $00
+
+Jump_000_0150:
+	CALL Call_000_0160
+	db $FA, $44, $FF ; LD A, [$FF44]
+	db $76 ; HALT
+	JR Jump_000_0150
+	db $00, $00, $00, $00, $00, $00, $00
+
+Call_000_0160:
+	LD HL, $4000
+	RET
+	db $00, $00, $00, $00, $00, $00, $00, $00, $00, $00, $00, $00
+	db $48, $45, $4C, $4C, $4F
+	ds 16011, $00
@@ -0,0 +1,3 @@
+SECTION "ROM Bank $001", ROMX[$4000], BANK[$1]
+	db $3E, $01, $18, $FC
+	ds 16380, $00
@@ -0,0 +1,2 @@
+SECTION "ROM Bank $002", ROMX[$4000], BANK[$2]
+	ds 16384, $00
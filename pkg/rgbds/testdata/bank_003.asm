@@ -0,0 +1,2 @@
+SECTION "ROM Bank $003", ROMX[$4000], BANK[$3]
+	ds 16384, $00
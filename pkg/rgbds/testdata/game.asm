@@ -0,0 +1,6 @@
+; rgbasm -o game.o game.asm && rgblink -o game.gb game.o
+
+INCLUDE "bank_000.asm"
+INCLUDE "bank_001.asm"
+INCLUDE "bank_002.asm"
+INCLUDE "bank_003.asm"
@@ -0,0 +1,110 @@
+package romloader
+
+import (
+	"fmt"
+)
+
+var bpsMagic = []byte("BPS1")
+
+// BPS actions
+const (
+	bpsSourceRead = iota
+	bpsTargetRead
+	bpsSourceCopy
+	bpsTargetCopy
+)
+
+// applyBPS applies a BPS patch.
+//
+// Header  : source size (VLQ) + target size (VLQ) + metadata size (VLQ) + metadata
+// Actions : (length-1)<<2 | action (VLQ), followed by action data
+func applyBPS(rom []byte, patch []byte) ([]byte, error) {
+	r, footer, err := splitFooter(patch, len(bpsMagic))
+	if err != nil {
+		return nil, err
+	}
+	sourceSize, err := r.readVLQ()
+	if err != nil {
+		return nil, err
+	}
+	targetSize, err := r.readVLQ()
+	if err != nil {
+		return nil, err
+	}
+	metadataSize, err := r.readVLQ()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.read(metadataSize); err != nil {
+		return nil, err
+	}
+	if err := checkSource(rom, sourceSize, footer); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, targetSize)
+	outPos, sourceRel, targetRel := 0, 0, 0
+
+	for !r.eof() {
+		data, err := r.readVLQ()
+		if err != nil {
+			return nil, err
+		}
+		action, length := data&3, data>>2+1
+		if outPos+length > len(out) {
+			return nil, fmt.Errorf("BPS WRITE OUT OF TARGET AT 0x%06X", outPos)
+		}
+
+		switch action {
+		case bpsSourceRead:
+			if outPos+length > len(rom) {
+				return nil, fmt.Errorf("BPS READ OUT OF SOURCE AT 0x%06X", outPos)
+			}
+			copy(out[outPos:], rom[outPos:outPos+length])
+			outPos += length
+
+		case bpsTargetRead:
+			b, err := r.read(length)
+			if err != nil {
+				return nil, err
+			}
+			copy(out[outPos:], b)
+			outPos += length
+
+		case bpsSourceCopy, bpsTargetCopy:
+			offset, err := r.readVLQ()
+			if err != nil {
+				return nil, err
+			}
+			delta := offset >> 1
+			if offset&1 != 0 {
+				delta = -delta
+			}
+			if action == bpsSourceCopy {
+				sourceRel += delta
+				if sourceRel < 0 || sourceRel+length > len(rom) {
+					return nil, fmt.Errorf("BPS READ OUT OF SOURCE AT 0x%06X", sourceRel)
+				}
+				copy(out[outPos:], rom[sourceRel:sourceRel+length])
+				sourceRel += length
+				outPos += length
+			} else {
+				targetRel += delta
+				if targetRel < 0 || targetRel >= outPos {
+					return nil, fmt.Errorf("BPS READ OUT OF TARGET AT 0x%06X", targetRel)
+				}
+				// Byte per byte : source & destination can overlap (RLE like copies)
+				for i := 0; i < length; i++ {
+					out[outPos] = out[targetRel]
+					outPos++
+					targetRel++
+				}
+			}
+		}
+	}
+
+	if err := checkTarget(out, footer); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
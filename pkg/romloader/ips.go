@@ -0,0 +1,72 @@
+package romloader
+
+import (
+	"fmt"
+)
+
+var ipsMagic = []byte("PATCH")
+
+// ipsEOF is the "EOF" marker, read as a record offset
+const ipsEOF = 0x454F46
+
+// applyIPS applies an IPS patch.
+//
+// Record : 3 bytes offset (BE) + 2 bytes size (BE) + data
+// RLE record (size = 0) : 2 bytes run length (BE) + 1 byte value
+// Optional 3 bytes after "EOF" : truncate size (lunar IPS extension)
+func applyIPS(rom []byte, patch []byte) ([]byte, error) {
+	out := append([]byte(nil), rom...)
+	pos := len(ipsMagic)
+
+	read := func(n int) ([]byte, error) {
+		if pos+n > len(patch) {
+			return nil, fmt.Errorf("IPS PATCH TRUNCATED AT 0x%06X", pos)
+		}
+		b := patch[pos : pos+n]
+		pos += n
+		return b, nil
+	}
+
+	for {
+		b, err := read(3)
+		if err != nil {
+			return nil, err
+		}
+		offset := int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+		if offset == ipsEOF {
+			break
+		}
+		if b, err = read(2); err != nil {
+			return nil, err
+		}
+		size := int(b[0])<<8 | int(b[1])
+
+		var data []byte
+		if size == 0 { // RLE
+			if b, err = read(3); err != nil {
+				return nil, err
+			}
+			size = int(b[0])<<8 | int(b[1])
+			data = make([]byte, size)
+			for i := range data {
+				data[i] = b[2]
+			}
+		} else if data, err = read(size); err != nil {
+			return nil, err
+		}
+
+		if end := offset + size; end > len(out) {
+			out = append(out, make([]byte, end-len(out))...)
+		}
+		copy(out[offset:], data)
+	}
+
+	// Truncate extension
+	if len(patch)-pos >= 3 {
+		b := patch[pos : pos+3]
+		if size := int(b[0])<<16 | int(b[1])<<8 | int(b[2]); size < len(out) {
+			out = out[:size]
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,102 @@
+package romloader
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+)
+
+// PatchFormat identifies a soft-patch file format
+type PatchFormat uint8
+
+const (
+	// FormatUnknown is returned when the patch header is not recognized
+	FormatUnknown PatchFormat = iota
+	// FormatIPS : International Patching System
+	FormatIPS
+	// FormatUPS : Universal Patching System (CRC checked)
+	FormatUPS
+	// FormatBPS : Beat Patching System (CRC checked)
+	FormatBPS
+)
+
+func (f PatchFormat) String() string {
+	switch f {
+	case FormatIPS:
+		return "IPS"
+	case FormatUPS:
+		return "UPS"
+	case FormatBPS:
+		return "BPS"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// DetectFormat returns the format of the patch from its magic header
+func DetectFormat(patch []byte) PatchFormat {
+	switch {
+	case bytes.HasPrefix(patch, ipsMagic):
+		return FormatIPS
+	case bytes.HasPrefix(patch, upsMagic):
+		return FormatUPS
+	case bytes.HasPrefix(patch, bpsMagic):
+		return FormatBPS
+	default:
+		return FormatUnknown
+	}
+}
+
+// ApplyPatch applies an IPS, UPS or BPS patch to rom and returns the patched image.
+//
+// rom is never modified : the result is always a new slice.
+// UPS and BPS checksums are verified (source, target and patch CRC32).
+func ApplyPatch(rom []byte, patch []byte) ([]byte, error) {
+	switch format := DetectFormat(patch); format {
+	case FormatIPS:
+		return applyIPS(rom, patch)
+	case FormatUPS:
+		return applyUPS(rom, patch)
+	case FormatBPS:
+		return applyBPS(rom, patch)
+	default:
+		return nil, fmt.Errorf("PATCH FORMAT NOT RECOGNIZED")
+	}
+}
+
+// ApplyPatches applies each patch in order, the output of a patch being the input of the next one.
+func ApplyPatches(rom []byte, patches ...[]byte) ([]byte, error) {
+	result := rom
+	for i, patch := range patches {
+		patched, err := ApplyPatch(result, patch)
+		if err != nil {
+			return nil, fmt.Errorf("PATCH #%d : %v", i, err)
+		}
+		result = patched
+	}
+	if len(patches) == 0 {
+		// Keep the "never modified" promise even without patches
+		result = append([]byte(nil), rom...)
+	}
+	return result, nil
+}
+
+// Load reads the ROM file at romPath and applies the patch files in order.
+//
+// Files on disk are only read : the patched image is returned in memory
+// and can be passed to the emulator.
+func Load(romPath string, patchPaths ...string) ([]byte, error) {
+	rom, err := ioutil.ReadFile(romPath)
+	if err != nil {
+		return nil, err
+	}
+	patches := make([][]byte, 0, len(patchPaths))
+	for _, path := range patchPaths {
+		patch, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, patch)
+	}
+	return ApplyPatches(rom, patches...)
+}
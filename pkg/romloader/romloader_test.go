@@ -0,0 +1,140 @@
+package romloader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+)
+
+func encodeVLQ(value int) []byte {
+	var out []byte
+	for {
+		x := byte(value & 0x7F)
+		value >>= 7
+		if value == 0 {
+			return append(out, 0x80|x)
+		}
+		out = append(out, x)
+		value--
+	}
+}
+
+func withFooter(body []byte, source, target []byte) []byte {
+	var crcs [4]byte
+	binary.LittleEndian.PutUint32(crcs[:], crc32.ChecksumIEEE(source))
+	body = append(body, crcs[:]...)
+	binary.LittleEndian.PutUint32(crcs[:], crc32.ChecksumIEEE(target))
+	body = append(body, crcs[:]...)
+	binary.LittleEndian.PutUint32(crcs[:], crc32.ChecksumIEEE(body))
+	return append(body, crcs[:]...)
+}
+
+func TestVLQ(t *testing.T) {
+	for _, v := range []int{0, 1, 127, 128, 255, 16511, 16512, 1 << 20} {
+		r := &patchReader{data: encodeVLQ(v)}
+		if got, err := r.readVLQ(); err != nil || got != v {
+			t.Fatalf("VLQ %d : got %d (%v)", v, got, err)
+		}
+	}
+}
+
+func TestApplyIPS(t *testing.T) {
+	rom := []byte{0, 1, 2, 3, 4, 5}
+	patch := []byte("PATCH")
+	patch = append(patch, 0x00, 0x00, 0x01, 0x00, 0x02, 0xAA, 0xBB)       // 2 bytes at 1
+	patch = append(patch, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x03, 0xCC) // RLE 3 bytes at 5
+	patch = append(patch, []byte("EOF")...)
+
+	out, err := ApplyPatch(rom, patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []byte{0, 0xAA, 0xBB, 3, 4, 0xCC, 0xCC, 0xCC}; !bytes.Equal(out, expected) {
+		t.Fatalf("expected: %X, got: %X", expected, out)
+	}
+	if !bytes.Equal(rom, []byte{0, 1, 2, 3, 4, 5}) {
+		t.Fatal("source rom modified")
+	}
+
+	// Truncate extension
+	out, err = ApplyPatch(rom, append(append([]byte("PATCH"), []byte("EOF")...), 0x00, 0x00, 0x04))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 4 {
+		t.Fatalf("expected truncated size 4, got %d", len(out))
+	}
+}
+
+func TestApplyUPS(t *testing.T) {
+	rom := []byte{0x10, 0x20, 0x30, 0x40}
+	target := []byte{0x10, 0x21, 0x30, 0x40, 0x50}
+
+	body := append([]byte("UPS1"), encodeVLQ(len(rom))...)
+	body = append(body, encodeVLQ(len(target))...)
+	body = append(body, encodeVLQ(1)...)
+	body = append(body, 0x20^0x21, 0x00)
+	body = append(body, encodeVLQ(1)...)
+	body = append(body, 0x50, 0x00)
+	patch := withFooter(body, rom, target)
+
+	out, err := ApplyPatch(rom, patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, target) {
+		t.Fatalf("expected: %X, got: %X", target, out)
+	}
+
+	if _, err := ApplyPatch([]byte{0x11, 0x20, 0x30, 0x40}, patch); err == nil {
+		t.Fatal("source CRC mismatch not detected")
+	}
+	patch[5] ^= 0xFF
+	if _, err := ApplyPatch(rom, patch); err == nil {
+		t.Fatal("patch CRC mismatch not detected")
+	}
+}
+
+func TestApplyBPS(t *testing.T) {
+	rom := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	target := []byte{1, 2, 3, 0xAA, 0xAA, 0xAA, 0xAA, 6, 7, 8}
+
+	action := func(kind, length int) []byte { return encodeVLQ((length-1)<<2 | kind) }
+	body := append([]byte("BPS1"), encodeVLQ(len(rom))...)
+	body = append(body, encodeVLQ(len(target))...)
+	body = append(body, encodeVLQ(0)...)             // No metadata
+	body = append(body, action(bpsSourceRead, 3)...) // 1 2 3
+	body = append(body, action(bpsTargetRead, 1)...) // AA
+	body = append(body, 0xAA)                        // TargetRead data
+	body = append(body, action(bpsTargetCopy, 3)...) // AA AA AA (overlapping copy)
+	body = append(body, encodeVLQ(3<<1)...)          // target offset +3
+	body = append(body, action(bpsSourceCopy, 3)...) // 6 7 8
+	body = append(body, encodeVLQ(5<<1)...)          // source offset +5
+	patch := withFooter(body, rom, target)
+
+	out, err := ApplyPatch(rom, patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, target) {
+		t.Fatalf("expected: %X, got: %X", target, out)
+	}
+}
+
+func TestApplyPatches(t *testing.T) {
+	rom := []byte{0, 0, 0, 0}
+	first := append([]byte("PATCH"), 0x00, 0x00, 0x00, 0x00, 0x01, 0x11, 'E', 'O', 'F')
+	second := append([]byte("PATCH"), 0x00, 0x00, 0x00, 0x00, 0x02, 0x22, 0x33, 'E', 'O', 'F')
+
+	out, err := ApplyPatches(rom, first, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []byte{0x22, 0x33, 0, 0}; !bytes.Equal(out, expected) {
+		t.Fatalf("expected: %X, got: %X", expected, out)
+	}
+	if _, err := ApplyPatches(rom, []byte("NOT A PATCH")); err == nil {
+		t.Fatal("unknown format not detected")
+	}
+}
@@ -0,0 +1,59 @@
+package romloader
+
+var upsMagic = []byte("UPS1")
+
+// applyUPS applies an UPS patch.
+//
+// Header : source size (VLQ) + target size (VLQ)
+// Hunks  : relative offset (VLQ) + bytes XORed with the source, ended by 0x00
+func applyUPS(rom []byte, patch []byte) ([]byte, error) {
+	r, footer, err := splitFooter(patch, len(upsMagic))
+	if err != nil {
+		return nil, err
+	}
+	sourceSize, err := r.readVLQ()
+	if err != nil {
+		return nil, err
+	}
+	targetSize, err := r.readVLQ()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSource(rom, sourceSize, footer); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, targetSize)
+	copy(out, rom)
+
+	pos := 0
+	for !r.eof() {
+		offset, err := r.readVLQ()
+		if err != nil {
+			return nil, err
+		}
+		pos += offset
+		for {
+			x, err := r.readByte()
+			if err != nil {
+				return nil, err
+			}
+			if pos < len(out) {
+				var src byte
+				if pos < len(rom) {
+					src = rom[pos]
+				}
+				out[pos] = src ^ x
+			}
+			pos++
+			if x == 0 {
+				break
+			}
+		}
+	}
+
+	if err := checkTarget(out, footer); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
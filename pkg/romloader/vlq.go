@@ -0,0 +1,96 @@
+package romloader
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+// footerSize : source CRC32 + target CRC32 + patch CRC32 (UPS & BPS)
+const footerSize = 12
+
+// patchReader reads the body of UPS/BPS patches (footer excluded)
+type patchReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *patchReader) eof() bool { return r.pos >= len(r.data) }
+
+func (r *patchReader) readByte() (byte, error) {
+	if r.eof() {
+		return 0, fmt.Errorf("PATCH TRUNCATED AT 0x%06X", r.pos)
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *patchReader) read(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.data) {
+		return nil, fmt.Errorf("PATCH TRUNCATED AT 0x%06X", r.pos)
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+// readVLQ decodes a variable length number as used by UPS & BPS
+// (7 bits per byte, bit 7 set on the last byte, with implicit offset).
+func (r *patchReader) readVLQ() (int, error) {
+	value, shift := 0, 1
+	for {
+		b, err := r.readByte()
+		if err != nil {
+			return 0, err
+		}
+		value += int(b&0x7F) * shift
+		if b&0x80 != 0 {
+			return value, nil
+		}
+		shift <<= 7
+		value += shift
+		if shift > 1<<42 {
+			return 0, fmt.Errorf("PATCH NUMBER OVERFLOW AT 0x%06X", r.pos)
+		}
+	}
+}
+
+// patchFooter holds the checksums stored at the end of UPS & BPS patches
+type patchFooter struct {
+	source uint32
+	target uint32
+}
+
+// splitFooter checks the patch CRC and returns the patch body (magic excluded) and footer
+func splitFooter(patch []byte, magicLength int) (*patchReader, patchFooter, error) {
+	if len(patch) < magicLength+footerSize {
+		return nil, patchFooter{}, fmt.Errorf("PATCH TOO SHORT")
+	}
+	footer := patch[len(patch)-footerSize:]
+	if crc := crc32.ChecksumIEEE(patch[:len(patch)-4]); crc != binary.LittleEndian.Uint32(footer[8:]) {
+		return nil, patchFooter{}, fmt.Errorf("PATCH CRC MISMATCH : 0x%08X", crc)
+	}
+	reader := &patchReader{data: patch[magicLength : len(patch)-footerSize]}
+	return reader, patchFooter{
+		source: binary.LittleEndian.Uint32(footer[0:]),
+		target: binary.LittleEndian.Uint32(footer[4:]),
+	}, nil
+}
+
+func checkSource(rom []byte, size int, footer patchFooter) error {
+	if len(rom) != size {
+		return fmt.Errorf("SOURCE SIZE MISMATCH : EXPECTED %d, GOT %d", size, len(rom))
+	}
+	if crc := crc32.ChecksumIEEE(rom); crc != footer.source {
+		return fmt.Errorf("SOURCE CRC MISMATCH : EXPECTED 0x%08X, GOT 0x%08X", footer.source, crc)
+	}
+	return nil
+}
+
+func checkTarget(out []byte, footer patchFooter) error {
+	if crc := crc32.ChecksumIEEE(out); crc != footer.target {
+		return fmt.Errorf("TARGET CRC MISMATCH : EXPECTED 0x%08X, GOT 0x%08X", footer.target, crc)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+// Package symbols loads the symbol files produced by RGBDS (rgblink -n) or used by no$gmb / BGB :
+//
+//	; File generated by rgblink
+//	00:0150 Main
+//	00:0158 Main.loop
+//	01:4000 LoadLevel
+//	00:c000 wPlayerX
+package symbols
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Symbol is a label located in a bank
+type Symbol struct {
+	Name string
+	Bank uint
+	Addr uint16
+}
+
+// Table contains the symbols of a ROM
+type Table struct {
+	symbols []Symbol          // Sorted by bank and address
+	byName  map[string]Symbol // First definition of each name
+}
+
+// Load reads the symbol file at path
+func Load(path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads a symbol file. Comments (';') and sections ('[labels]') are ignored.
+func Parse(r io.Reader) (*Table, error) {
+	t := &Table{byName: make(map[string]Symbol)}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, ';'); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" || text[0] == '[' {
+			continue
+		}
+		symbol, err := parseSymbol(text)
+		if err != nil {
+			return nil, fmt.Errorf("SYMBOLS LINE %d : %v", line, err)
+		}
+		t.Add(symbol)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// parseSymbol parses "BB:AAAA Name"
+func parseSymbol(text string) (Symbol, error) {
+	fields := strings.Fields(text)
+	if len(fields) < 2 {
+		return Symbol{}, fmt.Errorf("INVALID SYMBOL %q", text)
+	}
+	location := strings.SplitN(fields[0], ":", 2)
+	if len(location) != 2 {
+		return Symbol{}, fmt.Errorf("INVALID LOCATION %q", fields[0])
+	}
+	bank, err := strconv.ParseUint(location[0], 16, 16)
+	if err != nil {
+		return Symbol{}, fmt.Errorf("INVALID BANK %q", location[0])
+	}
+	addr, err := strconv.ParseUint(location[1], 16, 16)
+	if err != nil {
+		return Symbol{}, fmt.Errorf("INVALID ADDRESS %q", location[1])
+	}
+	return Symbol{Name: fields[1], Bank: uint(bank), Addr: uint16(addr)}, nil
+}
+
+// less orders the symbols by bank and address
+func less(bank1 uint, addr1 uint16, bank2 uint, addr2 uint16) bool {
+	if bank1 != bank2 {
+		return bank1 < bank2
+	}
+	return addr1 < addr2
+}
+
+// Add inserts a symbol in the table
+func (t *Table) Add(symbol Symbol) {
+	i := sort.Search(len(t.symbols), func(i int) bool {
+		s := t.symbols[i]
+		return less(symbol.Bank, symbol.Addr, s.Bank, s.Addr)
+	})
+	t.symbols = append(t.symbols, Symbol{})
+	copy(t.symbols[i+1:], t.symbols[i:])
+	t.symbols[i] = symbol
+	if _, ok := t.byName[symbol.Name]; !ok {
+		t.byName[symbol.Name] = symbol
+	}
+}
+
+// Len returns the number of symbols
+func (t *Table) Len() int {
+	return len(t.symbols)
+}
+
+// Symbols returns all the symbols, sorted by bank and address
+func (t *Table) Symbols() []Symbol {
+	return append([]Symbol(nil), t.symbols...)
+}
+
+// Lookup returns the symbol called name
+func (t *Table) Lookup(name string) (Symbol, bool) {
+	symbol, ok := t.byName[name]
+	return symbol, ok
+}
+
+// region returns the start of the memory area containing addr.
+// A symbol only names the addresses of its own area.
+func region(addr uint16) uint16 {
+	switch {
+	case addr < 0x4000: // ROM0
+		return 0x0000
+	case addr < 0x8000: // ROMX
+		return 0x4000
+	case addr < 0xA000: // VRAM
+		return 0x8000
+	case addr < 0xC000: // SRAM
+		return 0xA000
+	case addr < 0xD000: // WRAM0
+		return 0xC000
+	case addr < 0xE000: // WRAMX
+		return 0xD000
+	case addr < 0xFE00: // Echo RAM
+		return 0xE000
+	case addr < 0xFF00: // OAM
+		return 0xFE00
+	case addr < 0xFF80: // IO
+		return 0xFF00
+	default: // HRAM
+		return 0xFF80
+	}
+}
+
+// Find returns the closest symbol at or before addr, in the same bank and memory area
+func (t *Table) Find(bank uint, addr uint16) (symbol Symbol, offset uint16, ok bool) {
+	i := sort.Search(len(t.symbols), func(i int) bool {
+		s := t.symbols[i]
+		return less(bank, addr, s.Bank, s.Addr)
+	}) - 1
+	if i < 0 {
+		return Symbol{}, 0, false
+	}
+	symbol = t.symbols[i]
+	if symbol.Bank != bank || region(symbol.Addr) != region(addr) {
+		return Symbol{}, 0, false
+	}
+	// First definition at this address
+	for i > 0 && t.symbols[i-1].Bank == bank && t.symbols[i-1].Addr == symbol.Addr {
+		i--
+	}
+	symbol = t.symbols[i]
+	return symbol, addr - symbol.Addr, true
+}
+
+// Symbolize returns "Label" or "Label+$offset" for addr in bank (disasm.Symbolizer)
+func (t *Table) Symbolize(bank uint, addr uint16) (string, bool) {
+	symbol, offset, ok := t.Find(bank, addr)
+	if !ok {
+		return "", false
+	}
+	if offset == 0 {
+		return symbol.Name, true
+	}
+	return fmt.Sprintf("%s+$%X", symbol.Name, offset), true
+}
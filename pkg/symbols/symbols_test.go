@@ -0,0 +1,66 @@
+package symbols
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSymbols = `; File generated by rgblink
+[labels]
+00:0150 Main
+00:0150 Start
+00:0158 Main.loop
+01:4000 LoadLevel
+02:4000 DrawMap
+00:c000 wPlayerX
+00:ff80 hVBlankFlag
+`
+
+func TestParse(t *testing.T) {
+	table, err := Parse(strings.NewReader(testSymbols))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Len() != 7 {
+		t.Fatalf("expected 7 symbols, got %d", table.Len())
+	}
+	if s, ok := table.Lookup("DrawMap"); !ok || s.Bank != 2 || s.Addr != 0x4000 {
+		t.Errorf("DrawMap : got %+v (%v)", s, ok)
+	}
+	if _, ok := table.Lookup("Missing"); ok {
+		t.Errorf("Missing symbol found")
+	}
+
+	if _, err := Parse(strings.NewReader("0150 Main\n")); err == nil {
+		t.Errorf("expected an error for a missing bank")
+	}
+}
+
+func TestSymbolize(t *testing.T) {
+	table, err := Parse(strings.NewReader(testSymbols))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		bank     uint
+		addr     uint16
+		expected string // "" : no symbol
+	}{
+		{0, 0x0150, "Main"},
+		{0, 0x0152, "Main+$2"},
+		{0, 0x015A, "Main.loop+$2"},
+		{0, 0x0100, ""},
+		{1, 0x4010, "LoadLevel+$10"},
+		{2, 0x4000, "DrawMap"},
+		{3, 0x4000, ""},
+		{0, 0xC001, "wPlayerX+$1"},
+		{0, 0xFF81, "hVBlankFlag+$1"},
+		{0, 0xFF40, ""}, // IO area : not after wPlayerX
+	}
+	for _, test := range tests {
+		name, ok := table.Symbolize(test.bank, test.addr)
+		if name != test.expected || ok != (test.expected != "") {
+			t.Errorf("%02X:%04X : expected %q, got %q (%v)", test.bank, test.addr, test.expected, name, ok)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Package trace logs the executed instructions, one line per instruction,
+// in the gameboy-doctor format :
+//
+//	A:01 F:B0 B:00 C:13 D:00 E:D8 H:01 L:4D SP:FFFE PC:0100 PCMEM:00,C3,13,02
+//
+// Usage :
+//
+//	w := bufio.NewWriter(file)
+//	defer w.Flush()
+//	tracer := trace.NewTracer(w, emu)
+//	emu.AddExecHook(tracer.Trace)
+package trace
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jmontupet/gbcore/pkg/coreio"
+	"github.com/jmontupet/gbcore/pkg/disasm"
+)
+
+// Tracer writes the CPU state before each instruction
+type Tracer struct {
+	w   io.Writer
+	mem disasm.Memory
+	err error
+
+	// Filters : instructions are logged only if PC and its bank are in range
+	pcFilter            bool
+	pcStart, pcEnd      uint16
+	bankFilter          bool
+	bankFirst, bankLast uint
+
+	symbols disasm.Symbolizer // nil : no symbol comment
+}
+
+// NewTracer returns a Tracer writing to w. mem is used to read the bytes at PC (and its bank).
+func NewTracer(w io.Writer, mem disasm.Memory) *Tracer {
+	return &Tracer{w: w, mem: mem}
+}
+
+// SetPCRange only logs the instructions with PC in [start, end]
+func (t *Tracer) SetPCRange(start, end uint16) {
+	t.pcFilter, t.pcStart, t.pcEnd = true, start, end
+}
+
+// SetBankRange only logs the instructions located in banks [first, last]
+func (t *Tracer) SetBankRange(first, last uint) {
+	t.bankFilter, t.bankFirst, t.bankLast = true, first, last
+}
+
+// SetSymbols appends the symbol of PC to each line (" ; Label+$offset").
+// The lines are no longer in the strict gameboy-doctor format.
+func (t *Tracer) SetSymbols(symbols disasm.Symbolizer) {
+	t.symbols = symbols
+}
+
+// ClearFilters logs all the instructions
+func (t *Tracer) ClearFilters() {
+	t.pcFilter, t.bankFilter = false, false
+}
+
+// Err returns the first write error. The tracer stops writing after an error.
+func (t *Tracer) Err() error {
+	return t.err
+}
+
+// Trace logs the instruction at regs.PC. To be registered with AddExecHook.
+func (t *Tracer) Trace(regs coreio.Registers) {
+	if t.err != nil || !t.match(regs.PC) {
+		return
+	}
+	if t.symbols != nil {
+		if name, ok := t.symbols.Symbolize(t.mem.Bank(regs.PC), regs.PC); ok {
+			_, t.err = fmt.Fprintf(t.w, "%s ; %s\n", Format(regs, t.mem), name)
+			return
+		}
+	}
+	_, t.err = fmt.Fprintf(t.w, "%s\n", Format(regs, t.mem))
+}
+
+func (t *Tracer) match(pc uint16) bool {
+	if t.pcFilter && (pc < t.pcStart || pc > t.pcEnd) {
+		return false
+	}
+	if t.bankFilter {
+		if bank := t.mem.Bank(pc); bank < t.bankFirst || bank > t.bankLast {
+			return false
+		}
+	}
+	return true
+}
+
+// Format returns the gameboy-doctor line of the CPU state
+func Format(regs coreio.Registers, mem disasm.Memory) string {
+	pc := regs.PC
+	return fmt.Sprintf(
+		"A:%02X F:%02X B:%02X C:%02X D:%02X E:%02X H:%02X L:%02X SP:%04X PC:%04X PCMEM:%02X,%02X,%02X,%02X",
+		regs.A, regs.F, regs.B, regs.C, regs.D, regs.E, regs.H, regs.L, regs.SP, pc,
+		mem.Peek(pc), mem.Peek(pc+1), mem.Peek(pc+2), mem.Peek(pc+3),
+	)
+}
@@ -0,0 +1,70 @@
+package trace
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/jmontupet/gbcore/pkg/coreio"
+)
+
+type testMemory []uint8
+
+func (m testMemory) Peek(addr uint16) uint8 { return m[addr] }
+func (m testMemory) Bank(addr uint16) uint {
+	if addr >= 0x4000 && addr < 0x8000 {
+		return 2
+	}
+	return 0
+}
+
+func TestTracer(t *testing.T) {
+	mem := make(testMemory, 0x10000)
+	copy(mem[0x0100:], []uint8{0x00, 0xC3, 0x13, 0x02})
+	regs := coreio.Registers{A: 0x01, F: 0xB0, C: 0x13, E: 0xD8, H: 0x01, L: 0x4D, SP: 0xFFFE, PC: 0x0100}
+
+	var out bytes.Buffer
+	tracer := NewTracer(&out, mem)
+	tracer.Trace(regs)
+	expected := "A:01 F:B0 B:00 C:13 D:00 E:D8 H:01 L:4D SP:FFFE PC:0100 PCMEM:00,C3,13,02\n"
+	if out.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, out.String())
+	}
+
+	out.Reset()
+	tracer.SetPCRange(0x0200, 0x7FFF)
+	tracer.Trace(regs) // Filtered
+	regs.PC = 0x4000
+	tracer.Trace(regs)
+	tracer.SetBankRange(3, 4)
+	tracer.Trace(regs) // Filtered : bank 2
+	if lines := bytes.Count(out.Bytes(), []byte("\n")); lines != 1 {
+		t.Errorf("expected 1 line after filtering, got %d", lines)
+	}
+}
+
+type testSymbols struct{}
+
+func (testSymbols) Symbolize(bank uint, addr uint16) (string, bool) {
+	if bank == 0 && addr >= 0x0100 && addr < 0x0200 {
+		return fmt.Sprintf("Start+$%X", addr-0x0100), true
+	}
+	return "", false
+}
+
+func TestTracerSymbols(t *testing.T) {
+	mem := make(testMemory, 0x10000)
+	regs := coreio.Registers{PC: 0x0102}
+
+	var out bytes.Buffer
+	tracer := NewTracer(&out, mem)
+	tracer.SetSymbols(testSymbols{})
+	tracer.Trace(regs)
+	regs.PC = 0x4000
+	tracer.Trace(regs)
+	expected := "A:00 F:00 B:00 C:00 D:00 E:00 H:00 L:00 SP:0000 PC:0102 PCMEM:00,00,00,00 ; Start+$2\n" +
+		"A:00 F:00 B:00 C:00 D:00 E:00 H:00 L:00 SP:0000 PC:4000 PCMEM:00,00,00,00\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}